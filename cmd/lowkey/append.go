@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -16,23 +18,38 @@ import (
 )
 
 // newAppendCmd creates the `append` command, which accepts JSON log entries
-// from stdin and appends them to a specified log file with automatic rotation.
-// This enables external tools to leverage lowkey's robust logging infrastructure.
+// from stdin (or an --input file) and appends them to a specified log file
+// with automatic rotation. This enables external tools to leverage lowkey's
+// robust logging infrastructure.
 func newAppendCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "append",
+		Use:   "append --file PATH [--input PATH|-] [--filter KEY=VALUE]... [--compact|--pretty] [--timestamp] [--verbose]",
 		Short: "Append JSON log entries with rotation support",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			logFile, maxSize, maxBackups, remaining := parseAppendFlags(args)
+			opts, remaining, err := parseAppendFlags(args)
+			if err != nil {
+				return err
+			}
 			if len(remaining) > 0 {
 				return fmt.Errorf("append: unexpected arguments: %v", remaining)
 			}
-			if logFile == "" {
+			if opts.logFile == "" {
 				return fmt.Errorf("append: --file is required")
 			}
+			if opts.compact && opts.pretty {
+				return fmt.Errorf("append: --compact and --pretty are mutually exclusive")
+			}
+
+			// Open the input before the rotator so a mistyped --input path
+			// does not leave an empty log file behind.
+			input, inputName, err := openAppendInput(opts.input)
+			if err != nil {
+				return err
+			}
+			defer input.Close()
 
 			// Ensure absolute path
-			absPath, err := filepath.Abs(logFile)
+			absPath, err := filepath.Abs(opts.logFile)
 			if err != nil {
 				return fmt.Errorf("append: invalid file path: %w", err)
 			}
@@ -45,14 +62,15 @@ func newAppendCmd() *cobra.Command {
 
 			// Set up rotator
 			baseName := filepath.Base(absPath)
-			rotator, err := logging.NewRotator(logDir, baseName, maxSize, maxBackups)
+			rotator, err := logging.NewRotator(logDir, baseName, opts.maxSize, opts.maxBackups)
 			if err != nil {
 				return fmt.Errorf("append: failed to create rotator: %w", err)
 			}
 			defer rotator.Close()
 
-			// Read from stdin and append to log
-			scanner := bufio.NewScanner(os.Stdin)
+			// Read from the input and append to log
+			skipped := 0
+			scanner := bufio.NewScanner(input)
 			for scanner.Scan() {
 				line := scanner.Bytes()
 
@@ -63,6 +81,16 @@ func newAppendCmd() *cobra.Command {
 					continue
 				}
 
+				if !matchesFieldFilters(jsonCheck, opts.filters) {
+					skipped++
+					continue
+				}
+
+				line, err = formatAppendEntry(line, jsonCheck, opts, time.Now())
+				if err != nil {
+					return fmt.Errorf("append: format entry: %w", err)
+				}
+
 				// Write the line with newline
 				if _, err := rotator.Write(append(line, '\n')); err != nil {
 					return fmt.Errorf("append: write failed: %w", err)
@@ -70,7 +98,10 @@ func newAppendCmd() *cobra.Command {
 			}
 
 			if err := scanner.Err(); err != nil && err != io.EOF {
-				return fmt.Errorf("append: stdin read error: %w", err)
+				return fmt.Errorf("append: %s read error: %w", inputName, err)
+			}
+			if opts.verbose && len(opts.filters) > 0 {
+				fmt.Fprintf(os.Stderr, "append: skipped %d entries not matching filters\n", skipped)
 			}
 
 			return nil
@@ -78,12 +109,33 @@ func newAppendCmd() *cobra.Command {
 	}
 }
 
+// appendOptions holds the flags accepted by the `append` command.
+type appendOptions struct {
+	logFile    string
+	input      string
+	maxSize    int64
+	maxBackups int
+	compact    bool
+	pretty     bool
+	timestamp  bool
+	verbose    bool
+	filters    []fieldFilter
+}
+
+// fieldFilter is a parsed KEY=VALUE condition on a top-level JSON field, as
+// accepted by `append --filter` and `read --where`.
+type fieldFilter struct {
+	key   string
+	value string
+}
+
 // parseAppendFlags processes the command-line arguments for the `append` command,
-// extracting the log file path and rotation parameters.
-func parseAppendFlags(args []string) (logFile string, maxSize int64, maxBackups int, remaining []string) {
+// extracting the log file path, rotation parameters, filters, and formatting
+// options.
+func parseAppendFlags(args []string) (opts appendOptions, remaining []string, err error) {
 	// Set defaults
-	maxSize = 10 * 1024 * 1024 // 10MB
-	maxBackups = 5
+	opts.maxSize = 10 * 1024 * 1024 // 10MB
+	opts.maxBackups = 5
 
 	remaining = make([]string, 0, len(args))
 	for i := 0; i < len(args); i++ {
@@ -91,38 +143,169 @@ func parseAppendFlags(args []string) (logFile string, maxSize int64, maxBackups
 		switch {
 		case arg == "--file" || arg == "-f":
 			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				logFile = args[i+1]
+				opts.logFile = args[i+1]
 				i++
 			}
 		case strings.HasPrefix(arg, "--file="):
-			logFile = arg[len("--file="):]
+			opts.logFile = arg[len("--file="):]
 		case strings.HasPrefix(arg, "-f="):
-			logFile = arg[len("-f="):]
+			opts.logFile = arg[len("-f="):]
+		case arg == "--input" || arg == "-i":
+			// "-" is accepted here because it explicitly selects stdin.
+			if i+1 < len(args) && (args[i+1] == "-" || !strings.HasPrefix(args[i+1], "-")) {
+				opts.input = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--input="):
+			opts.input = arg[len("--input="):]
 		case arg == "--max-size":
 			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
 				if size, err := strconv.ParseInt(args[i+1], 10, 64); err == nil {
-					maxSize = size
+					opts.maxSize = size
 				}
 				i++
 			}
 		case strings.HasPrefix(arg, "--max-size="):
 			if size, err := strconv.ParseInt(arg[len("--max-size="):], 10, 64); err == nil {
-				maxSize = size
+				opts.maxSize = size
 			}
 		case arg == "--max-backups":
 			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
 				if backups, err := strconv.Atoi(args[i+1]); err == nil {
-					maxBackups = backups
+					opts.maxBackups = backups
 				}
 				i++
 			}
 		case strings.HasPrefix(arg, "--max-backups="):
 			if backups, err := strconv.Atoi(arg[len("--max-backups="):]); err == nil {
-				maxBackups = backups
+				opts.maxBackups = backups
+			}
+		case arg == "--compact":
+			opts.compact = true
+		case arg == "--pretty":
+			opts.pretty = true
+		case arg == "--timestamp":
+			opts.timestamp = true
+		case arg == "--verbose" || arg == "-v":
+			opts.verbose = true
+		case arg == "--filter":
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("append: --filter requires KEY=VALUE")
 			}
+			filter, err := parseFieldFilter(args[i+1])
+			if err != nil {
+				return opts, nil, fmt.Errorf("append: %w", err)
+			}
+			opts.filters = append(opts.filters, filter)
+			i++
+		case strings.HasPrefix(arg, "--filter="):
+			filter, err := parseFieldFilter(arg[len("--filter="):])
+			if err != nil {
+				return opts, nil, fmt.Errorf("append: %w", err)
+			}
+			opts.filters = append(opts.filters, filter)
 		default:
 			remaining = append(remaining, arg)
 		}
 	}
-	return logFile, maxSize, maxBackups, remaining
+	return opts, remaining, nil
+}
+
+// parseFieldFilter splits a KEY=VALUE filter expression at the first '='.
+func parseFieldFilter(expr string) (fieldFilter, error) {
+	key, value, ok := strings.Cut(expr, "=")
+	if !ok || key == "" {
+		return fieldFilter{}, fmt.Errorf("invalid filter %q, expected KEY=VALUE", expr)
+	}
+	return fieldFilter{key: key, value: value}, nil
+}
+
+// matchesFieldFilters reports whether decoded is a JSON object whose
+// top-level keys equal every filter value. String fields compare by their
+// text; other fields compare by their JSON encoding, so `count=3` and
+// `ok=true` match numbers and booleans. With no filters every entry matches.
+func matchesFieldFilters(decoded interface{}, filters []fieldFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	object, ok := decoded.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, filter := range filters {
+		field, exists := object[filter.key]
+		if !exists {
+			return false
+		}
+		if text, isString := field.(string); isString {
+			if text != filter.value {
+				return false
+			}
+			continue
+		}
+		encoded, err := json.Marshal(field)
+		if err != nil || string(encoded) != filter.value {
+			return false
+		}
+	}
+	return true
+}
+
+// openAppendInput returns the reader the `append` command consumes along with
+// a name for error messages. An empty path or "-" selects stdin; any other
+// path must name a readable regular file.
+func openAppendInput(path string) (io.ReadCloser, string, error) {
+	if path == "" || path == "-" {
+		return io.NopCloser(os.Stdin), "stdin", nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("append: open input: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, "", fmt.Errorf("append: stat input: %w", err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, "", fmt.Errorf("append: input %s is a directory", path)
+	}
+	return file, path, nil
+}
+
+// formatAppendEntry applies the optional --timestamp, --compact, and --pretty
+// transformations to a validated JSON line. decoded is the already-parsed
+// value of line. With --timestamp, objects lacking a `ts` field gain one set to
+// now in RFC3339; non-object values are left untouched. Without any options
+// the line is returned verbatim.
+func formatAppendEntry(line []byte, decoded interface{}, opts appendOptions, now time.Time) ([]byte, error) {
+	if opts.timestamp {
+		if object, ok := decoded.(map[string]interface{}); ok {
+			if _, exists := object["ts"]; !exists {
+				object["ts"] = now.UTC().Format(time.RFC3339)
+				encoded, err := json.Marshal(object)
+				if err != nil {
+					return nil, err
+				}
+				line = encoded
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case opts.compact:
+		if err := json.Compact(&buf, line); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case opts.pretty:
+		if err := json.Indent(&buf, line, "", "  "); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return line, nil
+	}
 }
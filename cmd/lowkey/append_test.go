@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchesFieldFiltersAcceptsAndRejects(t *testing.T) {
+	entry := map[string]interface{}{"level": "error", "count": 3.0, "ok": true, "msg": "x"}
+	cases := []struct {
+		filters []string
+		accept  bool
+	}{
+		{nil, true},
+		{[]string{"level=error"}, true},
+		{[]string{"level=info"}, false},
+		{[]string{"count=3"}, true},
+		{[]string{"count=\"3\""}, false},
+		{[]string{"ok=true"}, true},
+		{[]string{"missing=x"}, false},
+		{[]string{"level=error", "ok=true"}, true},
+		{[]string{"level=error", "ok=false"}, false},
+	}
+	for _, tc := range cases {
+		var filters []fieldFilter
+		for _, expr := range tc.filters {
+			filter, err := parseFieldFilter(expr)
+			if err != nil {
+				t.Fatalf("parse %q: %v", expr, err)
+			}
+			filters = append(filters, filter)
+		}
+		if got := matchesFieldFilters(entry, filters); got != tc.accept {
+			t.Fatalf("filters %q: accepted %v, want %v", tc.filters, got, tc.accept)
+		}
+	}
+	if matchesFieldFilters([]interface{}{"level", "error"}, []fieldFilter{{key: "level", value: "error"}}) {
+		t.Fatalf("expected a non-object entry to be rejected by a filter")
+	}
+	if _, err := parseFieldFilter("=error"); err == nil {
+		t.Fatalf("expected a filter without a key to be rejected")
+	}
+}
+
+func TestAppendWritesOnlyEntriesMatchingFilters(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.jsonl")
+	lines := []string{
+		`{"level":"error","msg":"kept"}`,
+		`{"level":"info","msg":"dropped"}`,
+		`{"msg":"no level"}`,
+		`{"level":"error","msg":"also kept"}`,
+	}
+	if err := os.WriteFile(input, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	output := filepath.Join(dir, "out.log")
+
+	args := []string{"--file", output, "--input", input, "--filter", "level=error", "--compact"}
+	if err := newAppendCmd().RunE(nil, args); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry map[string]string
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid output line %q: %v", line, err)
+		}
+		got = append(got, entry["msg"])
+	}
+	if strings.Join(got, "|") != "kept|also kept" {
+		t.Fatalf("appended %q, want the two error entries", got)
+	}
+}
+
+func TestFormatAppendEntry(t *testing.T) {
+	now := time.Date(2026, 10, 4, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	cases := []struct {
+		name string
+		line string
+		opts appendOptions
+		want string
+	}{
+		{name: "verbatim", line: `{ "msg": "x" }`, want: `{ "msg": "x" }`},
+		{name: "compact", line: `{ "msg": "x",  "n": [1, 2] }`, opts: appendOptions{compact: true}, want: `{"msg":"x","n":[1,2]}`},
+		{name: "pretty", line: `{"msg":"x"}`, opts: appendOptions{pretty: true}, want: "{\n  \"msg\": \"x\"\n}"},
+		{name: "timestamp added", line: `{"msg":"x"}`, opts: appendOptions{timestamp: true}, want: `{"msg":"x","ts":"2026-10-04T07:30:00Z"}`},
+		{name: "timestamp kept", line: `{"ts":"earlier","msg":"x"}`, opts: appendOptions{timestamp: true}, want: `{"ts":"earlier","msg":"x"}`},
+		{name: "timestamp skips non-objects", line: `[1, 2]`, opts: appendOptions{timestamp: true, compact: true}, want: `[1,2]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(tc.line), &decoded); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			got, err := formatAppendEntry([]byte(tc.line), decoded, tc.opts, now)
+			if err != nil {
+				t.Fatalf("format: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAppendRejectsCompactWithPretty(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.log")
+	err := newAppendCmd().RunE(nil, []string{"--file", output, "--compact", "--pretty"})
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected --compact with --pretty to be rejected, got %v", err)
+	}
+}
+
+func TestAppendInputFile(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "logs", "out.log")
+
+	err := newAppendCmd().RunE(nil, []string{"--file", output, "--input", filepath.Join(dir, "missing.jsonl")})
+	if err == nil || !strings.Contains(err.Error(), "open input") {
+		t.Fatalf("expected a missing input to be reported, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("expected no log file after a bad --input, got %v", err)
+	}
+	if err := newAppendCmd().RunE(nil, []string{"--file", output, "--input", dir}); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Fatalf("expected a directory input to be rejected, got %v", err)
+	}
+
+	// "-" reads stdin explicitly.
+	stdin, err := os.CreateTemp(dir, "stdin")
+	if err != nil {
+		t.Fatalf("create stdin: %v", err)
+	}
+	if _, err := stdin.WriteString("{\"msg\":\"from stdin\"}\n"); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("rewind stdin: %v", err)
+	}
+	defer stdin.Close()
+	saved := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = saved }()
+
+	if err := newAppendCmd().RunE(nil, []string{"--file", output, "--input", "-"}); err != nil {
+		t.Fatalf("append from stdin: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if string(data) != "{\"msg\":\"from stdin\"}\n" {
+		t.Fatalf("output = %q", data)
+	}
+}
@@ -2,16 +2,23 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"lowkey/internal/daemon"
+	"lowkey/internal/logging"
+	"lowkey/internal/logs"
 	"lowkey/internal/state"
 	"lowkey/pkg/config"
+	"lowkey/pkg/output"
 )
 
 // newClearCmd creates the `clear` command, which is responsible for pruning
@@ -19,14 +26,26 @@ import (
 // up disk space.
 func newClearCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "clear",
+		Use:   "clear [--logs] [--state] [--older-than AGE] [--cache-orphans] [--yes]",
 		Short: "Prune logs and/or cached state",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			clearLogs, clearState, yes, remaining := parseClearArgs(args)
+			opts, remaining, err := parseClearArgs(args)
+			if err != nil {
+				return err
+			}
 			if len(remaining) > 0 {
 				return fmt.Errorf("clear: unexpected arguments: %v", remaining)
 			}
-			if !clearLogs && !clearState {
+			if opts.cacheOrphans && (opts.logs || opts.state || opts.olderThan > 0) {
+				return errors.New("clear: --cache-orphans cannot be combined with other selectors")
+			}
+			if opts.olderThan > 0 && opts.state {
+				return errors.New("clear: --older-than applies to logs only and cannot be combined with --state")
+			}
+			clearLogs, clearState, yes := opts.logs, opts.state, opts.yes
+			if opts.olderThan > 0 {
+				clearLogs = true
+			} else if !clearLogs && !clearState {
 				clearLogs, clearState = true, true
 			}
 
@@ -45,79 +64,197 @@ func newClearCmd() *cobra.Command {
 				return err
 			}
 
+			if opts.cacheOrphans {
+				return pruneCacheOrphans(stateDir, manifest)
+			}
+
 			logTargets := collectLogTargets(stateDir, manifest)
+			if opts.olderThan > 0 {
+				logTargets, err = collectAgedLogTargets(stateDir, manifest, time.Now().Add(-opts.olderThan))
+				if err != nil {
+					return err
+				}
+			}
 			stateTargets := collectStateTargets(stateDir)
 
-			fmt.Println("targets to clear:")
-			if clearLogs {
-				fmt.Println("  logs:")
-				if len(logTargets) == 0 {
-					fmt.Println("    (none found)")
-				} else {
-					for _, path := range logTargets {
+			jsonOutput := outputFormat == "json"
+			if jsonOutput && !yes {
+				return errors.New("clear: --output json requires --yes")
+			}
+
+			if !jsonOutput {
+				fmt.Println("targets to clear:")
+				if clearLogs {
+					fmt.Println("  logs:")
+					if len(logTargets) == 0 {
+						fmt.Println("    (none found)")
+					} else {
+						for _, path := range logTargets {
+							fmt.Printf("    - %s\n", path)
+						}
+					}
+				}
+				if clearState {
+					fmt.Println("  state:")
+					fmt.Printf("    - %s (manifest)\n", store.Path())
+					for _, path := range stateTargets {
 						fmt.Printf("    - %s\n", path)
 					}
 				}
-			}
-			if clearState {
-				fmt.Println("  state:")
-				fmt.Printf("    - %s (manifest)\n", store.Path())
-				for _, path := range stateTargets {
-					fmt.Printf("    - %s\n", path)
+
+				var pending []string
+				if clearLogs {
+					pending = append(pending, logTargets...)
 				}
-			}
+				if clearState {
+					pending = append(pending, store.Path())
+					pending = append(pending, stateTargets...)
+				}
+				fmt.Printf("total to free: %s\n", output.FormatBytes(totalSize(pending)))
 
-			if !yes {
-				fmt.Print("proceed? [y/N]: ")
-				reader := bufio.NewReader(os.Stdin)
-				answer, _ := reader.ReadString('\n')
-				answer = strings.ToLower(strings.TrimSpace(answer))
-				if answer != "y" && answer != "yes" {
+				if !yes && !confirm("proceed? [y/N]: ") {
 					fmt.Println("clear: aborted")
 					return nil
 				}
 			}
 
-			var errs []error
+			report := clearReport{Removed: []clearedFile{}, Errors: []string{}}
 			if clearLogs {
-				if err := removePaths(logTargets); err != nil {
-					errs = append(errs, err)
+				report.removeAll(logTargets)
+				// Only directories left empty are removed, so anything other
+				// than change logs inside .lowlog is preserved.
+				for _, dir := range lowlogDirs(manifest) {
+					_ = os.Remove(dir)
 				}
 			}
 			if clearState {
-				if err := store.Clear(); err != nil {
-					errs = append(errs, err)
-				}
-				if err := removePaths(stateTargets); err != nil {
-					errs = append(errs, err)
-				}
+				report.removeAll(append([]string{store.Path()}, stateTargets...))
 			}
 
-			if len(errs) > 0 {
-				return errors.Join(errs...)
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			}
+			if len(report.Errors) > 0 {
+				return fmt.Errorf("clear: %s", strings.Join(report.Errors, "; "))
+			}
+			if !jsonOutput {
+				fmt.Printf("clear: completed (%d files, %s freed)\n", len(report.Removed), output.FormatBytes(report.BytesFreed))
 			}
-
-			fmt.Println("clear: completed")
 			return nil
 		},
 	}
 }
 
 // collectLogTargets identifies all log files that should be considered for
-// clearing. It checks for rotated log files based on the base log path.
+// clearing. It checks for rotated log files based on the base log path and
+// includes the dated change logs, `YYYY-MM-DD.log` and their `.log.gz`
+// archives, in each watched directory's `.lowlog`. Other files there are left
+// alone.
 func collectLogTargets(stateDir string, manifest *config.Manifest) []string {
 	base := filepath.Join(stateDir, "lowkey.log")
 	if manifest != nil && manifest.LogPath != "" {
 		base = manifest.LogPath
 	}
-	matches, err := filepath.Glob(base + "*")
+	targets, err := filepath.Glob(base + "*")
+	if err != nil || len(targets) == 0 {
+		targets = []string{base}
+	}
+	for _, dir := range lowlogDirs(manifest) {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+		archives, _ := filepath.Glob(filepath.Join(dir, "*.log.gz"))
+		for _, path := range append(matches, archives...) {
+			if _, ok := logs.ParseLogDate(path); ok {
+				targets = append(targets, path)
+			}
+		}
+	}
+	return targets
+}
+
+// lowlogDirs returns the `.lowlog` directory of every watched directory in the
+// manifest.
+func lowlogDirs(manifest *config.Manifest) []string {
+	if manifest == nil {
+		return nil
+	}
+	dirs := make([]string, 0, len(manifest.Directories))
+	for _, dir := range manifest.Directories {
+		dirs = append(dirs, lowlogDir(dir))
+	}
+	return dirs
+}
+
+// collectAgedLogTargets returns the log files older than cutoff: rotated
+// daemon log archives whose rotation time precedes it, and dated `.lowlog`
+// change logs in the manifest's directories whose day ends before it. The
+// active daemon log and any other files are never selected.
+func collectAgedLogTargets(stateDir string, manifest *config.Manifest, cutoff time.Time) ([]string, error) {
+	base := filepath.Join(stateDir, "lowkey.log")
+	if manifest != nil && manifest.LogPath != "" {
+		base = manifest.LogPath
+	}
+	var targets []string
+	archives, _ := filepath.Glob(base + ".*")
+	for _, path := range archives {
+		if rotated, ok := logging.ArchiveTime(filepath.Base(base), path); ok && rotated.Before(cutoff) {
+			targets = append(targets, path)
+		}
+	}
+
+	if manifest == nil {
+		return targets, nil
+	}
+	dayCutoff := startOfDay(cutoff)
+	for _, dir := range manifest.Directories {
+		files, err := logs.NewReader(lowlogDir(dir)).DatedLogFiles()
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.Date.Before(dayCutoff) {
+				targets = append(targets, file.Path)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// pruneCacheOrphans drops entries from the persisted signature cache whose
+// paths lie outside every directory in the manifest and reports how many were
+// removed. Without a manifest nothing is watched, so every entry is an orphan.
+func pruneCacheOrphans(stateDir string, manifest *config.Manifest) error {
+	path := filepath.Join(stateDir, "cache.json")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return reportCacheOrphans(0)
+	}
+	cache, err := state.Load(path)
 	if err != nil {
-		return []string{base}
+		return err
+	}
+	var dirs []string
+	if manifest != nil {
+		dirs = manifest.Directories
 	}
-	if len(matches) == 0 {
-		return []string{base}
+	removed := cache.PruneOutside(dirs)
+	if removed > 0 {
+		if err := state.Save(cache, path); err != nil {
+			return err
+		}
+	}
+	return reportCacheOrphans(removed)
+}
+
+// reportCacheOrphans prints the number of orphaned cache entries removed.
+func reportCacheOrphans(removed int) error {
+	if outputFormat == "json" {
+		return json.NewEncoder(os.Stdout).Encode(map[string]int{"orphans_removed": removed})
 	}
-	return matches
+	fmt.Printf("clear: removed %d orphaned cache entries\n", removed)
+	return nil
 }
 
 // collectStateTargets gathers the paths of all state files, such as the cache
@@ -126,9 +263,73 @@ func collectStateTargets(stateDir string) []string {
 	return []string{
 		filepath.Join(stateDir, "cache.json"),
 		pidFilePath(stateDir),
+		pausedMarkerPath(stateDir),
+		filepath.Join(stateDir, daemon.SnapshotFilename),
+		filepath.Join(stateDir, daemon.HeartbeatFilename),
+		filepath.Join(stateDir, daemon.BloomCacheFilename),
 	}
 }
 
+// clearReport records the outcome of a clear run. It is emitted as-is for
+// `--output json`.
+type clearReport struct {
+	Removed    []clearedFile `json:"removed"`
+	BytesFreed int64         `json:"bytes_freed"`
+	Errors     []string      `json:"errors"`
+}
+
+// clearedFile is a single file removed by clear and the bytes it occupied.
+type clearedFile struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// removeAll deletes each path, stat-ing it first so the reclaimed bytes can be
+// reported. Missing files are skipped and failures are collected rather than
+// aborting the run.
+func (r *clearReport) removeAll(paths []string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				r.Errors = append(r.Errors, fmt.Sprintf("stat %s: %v", path, err))
+			}
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				r.Errors = append(r.Errors, fmt.Sprintf("remove %s: %v", path, err))
+			}
+			continue
+		}
+		r.Removed = append(r.Removed, clearedFile{Path: path, Bytes: info.Size()})
+		r.BytesFreed += info.Size()
+	}
+}
+
+// totalSize sums the on-disk size of paths, recursing into directories.
+// Missing or unreadable entries count as zero.
+func totalSize(paths []string) int64 {
+	var total int64
+	for _, path := range paths {
+		_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.Type().IsRegular() {
+				if info, err := d.Info(); err == nil {
+					total += info.Size()
+				}
+			}
+			return nil
+		})
+	}
+	return total
+}
+
 // removePaths deletes a list of files. It continues even if some deletions
 // fail and returns a consolidated error.
 func removePaths(paths []string) error {
@@ -153,22 +354,56 @@ func removePaths(paths []string) error {
 	return nil
 }
 
+// confirm prints prompt and reads a yes/no answer from stdin, returning true
+// only for an explicit "y" or "yes".
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// clearOptions holds the flags accepted by the `clear` command.
+type clearOptions struct {
+	logs         bool
+	state        bool
+	yes          bool
+	olderThan    time.Duration
+	cacheOrphans bool
+}
+
 // parseClearArgs processes the command-line arguments for the `clear` command,
-// identifying which components to clear (logs, state) and whether to bypass
-// the confirmation prompt.
-func parseClearArgs(args []string) (logs, state, yes bool, remaining []string) {
+// identifying which components to clear (logs, state), an optional age cutoff
+// for logs, and whether to bypass the confirmation prompt.
+func parseClearArgs(args []string) (opts clearOptions, remaining []string, err error) {
 	remaining = make([]string, 0, len(args))
-	for _, arg := range args {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
 		switch {
 		case arg == "--logs":
-			logs = true
+			opts.logs = true
 		case arg == "--state":
-			state = true
+			opts.state = true
 		case arg == "--yes" || arg == "-y":
-			yes = true
+			opts.yes = true
+		case arg == "--cache-orphans":
+			opts.cacheOrphans = true
+		case arg == "--older-than":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("clear: --older-than requires a value")
+			}
+			if opts.olderThan, err = parseAge(args[i+1]); err != nil {
+				return opts, nil, fmt.Errorf("clear: %w", err)
+			}
+			i++
+		case strings.HasPrefix(arg, "--older-than="):
+			if opts.olderThan, err = parseAge(arg[len("--older-than="):]); err != nil {
+				return opts, nil, fmt.Errorf("clear: %w", err)
+			}
 		default:
 			remaining = append(remaining, arg)
 		}
 	}
-	return logs, state, yes, remaining
+	return opts, remaining, nil
 }
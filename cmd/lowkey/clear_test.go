@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+	"lowkey/pkg/output"
+)
+
+func TestClearLogsOnlyPurgesDatedChangeLogs(t *testing.T) {
+	stateDir, watched := t.TempDir(), t.TempDir()
+	lowlog := filepath.Join(watched, ".lowlog")
+	if err := os.MkdirAll(lowlog, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	owned := []string{"2026-10-01.log", "2026-10-02.log", "2026-10-01.log.gz"}
+	unrelated := []string{"notes.log", "2026-10.log", "keep.txt"}
+	for _, name := range append(append([]string(nil), owned...), unrelated...) {
+		if err := os.WriteFile(filepath.Join(lowlog, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	manifest := &config.Manifest{Directories: []string{watched}}
+
+	targets := collectLogTargets(stateDir, manifest)
+	for _, name := range owned {
+		if !slices.Contains(targets, filepath.Join(lowlog, name)) {
+			t.Fatalf("expected %s among the targets %q", name, targets)
+		}
+	}
+	for _, name := range unrelated {
+		if slices.Contains(targets, filepath.Join(lowlog, name)) {
+			t.Fatalf("expected %s not to be a target", name)
+		}
+	}
+
+	report := clearReport{}
+	report.removeAll(targets)
+	if len(report.Errors) > 0 {
+		t.Fatalf("remove: %v", report.Errors)
+	}
+	for _, dir := range lowlogDirs(manifest) {
+		_ = os.Remove(dir)
+	}
+	entries, err := os.ReadDir(lowlog)
+	if err != nil {
+		t.Fatalf("expected .lowlog to be kept while it holds other files: %v", err)
+	}
+	var left []string
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	slices.Sort(unrelated)
+	if !slices.Equal(left, unrelated) {
+		t.Fatalf("left %q, want %q", left, unrelated)
+	}
+}
+
+func TestClearJSONReportListsRemovedFilesBytesAndErrors(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "lowkey")
+	t.Setenv("XDG_STATE_HOME", filepath.Dir(stateDir))
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save(&config.Manifest{Directories: []string{t.TempDir()}}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+	manifestInfo, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatalf("stat manifest: %v", err)
+	}
+	files := map[string]string{"lowkey.log": "0123456789", "lowkey.log.1": "01234"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(stateDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	// A directory in place of the cache file cannot be removed, so it is
+	// reported as an error without stopping the run.
+	if err := os.MkdirAll(filepath.Join(stateDir, "cache.json", "nested"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	previous := outputFormat
+	outputFormat = "json"
+	defer func() { outputFormat = previous }()
+	var runErr error
+	out := captureStdout(t, func() { runErr = newClearCmd().RunE(nil, []string{"--yes"}) })
+	if runErr == nil || !strings.Contains(runErr.Error(), "cache.json") {
+		t.Fatalf("expected the failed removal to be returned, got %v", runErr)
+	}
+
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("expected one JSON report, got %q: %v", out, err)
+	}
+	keys := make([]string, 0, len(report))
+	for key := range report {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"bytes_freed", "errors", "removed"}) {
+		t.Fatalf("report keys = %q", keys)
+	}
+
+	var decoded clearReport
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	want := map[string]int64{
+		filepath.Join(stateDir, "lowkey.log"):   10,
+		filepath.Join(stateDir, "lowkey.log.1"): 5,
+		store.Path():                            manifestInfo.Size(),
+	}
+	var total int64
+	for _, removed := range decoded.Removed {
+		size, ok := want[removed.Path]
+		if !ok || size != removed.Bytes {
+			t.Fatalf("unexpected removal %+v", removed)
+		}
+		delete(want, removed.Path)
+		total += removed.Bytes
+	}
+	if len(want) > 0 {
+		t.Fatalf("expected these to be removed too: %v", want)
+	}
+	if decoded.BytesFreed != total {
+		t.Fatalf("bytes_freed = %d, want %d", decoded.BytesFreed, total)
+	}
+	if len(decoded.Errors) != 1 || !strings.Contains(decoded.Errors[0], "remove "+filepath.Join(stateDir, "cache.json")) {
+		t.Fatalf("errors = %q", decoded.Errors)
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	defer func() {
+		os.Stdout = stdout
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestTotalSizeRecursesAndCountsMissingPathsAsZero(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "logs", "nested"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for path, size := range map[string]int{"a.log": 100, "logs/b.log": 20, "logs/nested/c.log": 3} {
+		if err := os.WriteFile(filepath.Join(dir, path), make([]byte, size), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	paths := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "logs"), filepath.Join(dir, "missing.log")}
+	if got := totalSize(paths); got != 123 {
+		t.Fatalf("totalSize = %d, want 123", got)
+	}
+}
+
+func TestClearPromptShowsTotalToFree(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "lowkey")
+	t.Setenv("XDG_STATE_HOME", filepath.Dir(stateDir))
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	watched := t.TempDir()
+	if err := store.Save(&config.Manifest{Directories: []string{watched}}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+	manifestInfo, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatalf("stat manifest: %v", err)
+	}
+	daemonLog := filepath.Join(stateDir, "lowkey.log")
+	if err := os.WriteFile(daemonLog, make([]byte, 2048), 0o644); err != nil {
+		t.Fatalf("write daemon log: %v", err)
+	}
+	changeLog := filepath.Join(watched, ".lowlog", "2026-10-01.log")
+	if err := os.MkdirAll(filepath.Dir(changeLog), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(changeLog, make([]byte, 1024), 0o644); err != nil {
+		t.Fatalf("write change log: %v", err)
+	}
+
+	// Answer the prompt with "n".
+	answer, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatalf("create stdin: %v", err)
+	}
+	defer answer.Close()
+	if _, err := answer.WriteString("n\n"); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	if _, err := answer.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("rewind stdin: %v", err)
+	}
+	saved := os.Stdin
+	os.Stdin = answer
+	defer func() { os.Stdin = saved }()
+
+	var runErr error
+	out := captureStdout(t, func() { runErr = newClearCmd().RunE(nil, nil) })
+	if runErr != nil {
+		t.Fatalf("clear: %v", runErr)
+	}
+	line := "total to free: " + output.FormatBytes(2048+1024+manifestInfo.Size()) + "\n"
+	at := strings.Index(out, line)
+	if at < 0 || at > strings.Index(out, "proceed? [y/N]") {
+		t.Fatalf("expected %q before the prompt, got %q", line, out)
+	}
+	if !strings.Contains(out, "clear: aborted") {
+		t.Fatalf("expected the declined prompt to abort, got %q", out)
+	}
+	for _, path := range []string{daemonLog, changeLog, store.Path()} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to be kept after declining: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// globalFlags are the flags execute strips before dispatching, so they are
+// offered at the top level rather than on any one command.
+var globalFlags = []string{"--config", "--output", "--profile", "--instance", "--state-dir"}
+
+// directoryFlags and fileFlags take a path value; completion offers
+// directories or files for the word following them.
+var (
+	directoryFlags = []string{"--dir", "--state-dir"}
+	fileFlags      = []string{"--config", "--file", "--input", "--manifest"}
+)
+
+// completionShells lists the shells `completion` can generate scripts for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// usageFlagPattern extracts flag names such as `--older-than` or `-y` from a
+// command's usage line.
+var usageFlagPattern = regexp.MustCompile(`(?:^|[\s\[|])(--?[A-Za-z][A-Za-z0-9-]*)`)
+
+// newCompletionCmd creates the `completion` command, which prints a shell
+// completion script. The script is derived from the registered commands and
+// the flags named in their usage lines, so it stays in step with the CLI.
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <bash|zsh|fish>",
+		Short: "Generate a shell completion script",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("completion: expected one shell (%s)", strings.Join(completionShells, ", "))
+			}
+			nodes := completionTree(rootCmd)
+			switch args[0] {
+			case "bash":
+				return writeBashCompletion(os.Stdout, nodes)
+			case "zsh":
+				return writeZshCompletion(os.Stdout, nodes)
+			case "fish":
+				return writeFishCompletion(os.Stdout, nodes)
+			default:
+				return fmt.Errorf("completion: unsupported shell %q (want %s)", args[0], strings.Join(completionShells, ", "))
+			}
+		},
+	}
+}
+
+// completionNode describes one command for completion purposes. Path holds
+// the command names from the root, e.g. ["logs", "prune"].
+type completionNode struct {
+	Path        []string
+	Short       string
+	Flags       []string
+	Subcommands []string
+	// Directories is set for commands that take directory arguments.
+	Directories bool
+	// Words are fixed positional values, such as the shells for completion.
+	Words []string
+}
+
+// completionTree flattens the command tree beneath root into nodes, the root
+// itself first.
+func completionTree(root *cobra.Command) []completionNode {
+	var nodes []completionNode
+	completion := completionCmd(root)
+	var walk func(cmd *cobra.Command, path []string)
+	walk = func(cmd *cobra.Command, path []string) {
+		node := completionNode{Path: path, Short: cmd.Short}
+		if len(path) == 0 {
+			node.Flags = append(node.Flags, globalFlags...)
+		} else {
+			node.Flags = usageFlags(cmd.Use)
+			node.Directories = strings.Contains(cmd.Use, "[dir ...]")
+		}
+		if cmd == completion {
+			node.Words = completionShells
+		}
+		for _, child := range cmd.Commands() {
+			node.Subcommands = append(node.Subcommands, child.Name())
+		}
+		nodes = append(nodes, node)
+		for _, child := range cmd.Commands() {
+			walk(child, append(append([]string(nil), path...), child.Name()))
+		}
+	}
+	walk(root, nil)
+	return nodes
+}
+
+// completionCmd returns root's `completion` subcommand, or nil.
+func completionCmd(root *cobra.Command) *cobra.Command {
+	for _, child := range root.Commands() {
+		if child.Name() == "completion" {
+			return child
+		}
+	}
+	return nil
+}
+
+// usageFlags returns the distinct flags named in a usage line, in order.
+func usageFlags(use string) []string {
+	var flags []string
+	seen := make(map[string]struct{})
+	for _, match := range usageFlagPattern.FindAllStringSubmatch(use, -1) {
+		flag := match[1]
+		if _, ok := seen[flag]; ok {
+			continue
+		}
+		seen[flag] = struct{}{}
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// candidates returns the words offered for node: subcommands, fixed values,
+// and flags.
+func (n completionNode) candidates() []string {
+	words := append(append([]string(nil), n.Subcommands...), n.Words...)
+	return append(words, n.Flags...)
+}
+
+func writeBashCompletion(w io.Writer, nodes []completionNode) error {
+	var b strings.Builder
+	b.WriteString("# bash completion for lowkey\n")
+	b.WriteString("# Load with: source <(lowkey completion bash)\n\n")
+	writeBashFunction(&b, nodes)
+	b.WriteString("complete -o filenames -F _lowkey lowkey\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeZshCompletion(w io.Writer, nodes []completionNode) error {
+	var b strings.Builder
+	b.WriteString("#compdef lowkey\n")
+	b.WriteString("# zsh completion for lowkey\n")
+	b.WriteString("# Load with: source <(lowkey completion zsh)\n\n")
+	b.WriteString("autoload -U +X bashcompinit && bashcompinit\n\n")
+	writeBashFunction(&b, nodes)
+	b.WriteString("complete -o filenames -F _lowkey lowkey\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeBashFunction emits the `_lowkey` completion function shared by the
+// bash and zsh (via bashcompinit) scripts. It walks the words typed so far to
+// find the deepest known command, then offers that command's candidates.
+func writeBashFunction(b *strings.Builder, nodes []completionNode) {
+	var transitions []string
+	for _, node := range nodes[1:] {
+		transitions = append(transitions, "lowkey/"+strings.Join(node.Path, "/"))
+	}
+
+	b.WriteString("_lowkey() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    local path=\"lowkey\" words=\"\" dirs=0 i\n\n")
+	fmt.Fprintf(b, "    case \"$prev\" in\n")
+	fmt.Fprintf(b, "        %s)\n            COMPREPLY=($(compgen -d -- \"$cur\"))\n            return\n            ;;\n", strings.Join(directoryFlags, "|"))
+	fmt.Fprintf(b, "        %s)\n            COMPREPLY=($(compgen -f -- \"$cur\"))\n            return\n            ;;\n", strings.Join(fileFlags, "|"))
+	b.WriteString("    esac\n\n")
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        case \"$path/${COMP_WORDS[i]}\" in\n")
+	fmt.Fprintf(b, "            %s)\n                path=\"$path/${COMP_WORDS[i]}\"\n                ;;\n", strings.Join(transitions, "|"))
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+	b.WriteString("    case \"$path\" in\n")
+	for _, node := range nodes {
+		key := strings.Join(append([]string{"lowkey"}, node.Path...), "/")
+		fmt.Fprintf(b, "        %s)\n", key)
+		fmt.Fprintf(b, "            words=%q\n", strings.Join(node.candidates(), " "))
+		if node.Directories {
+			b.WriteString("            dirs=1\n")
+		}
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n\n")
+	b.WriteString("    if [[ $dirs == 1 && $cur != -* ]]; then\n")
+	b.WriteString("        COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+	b.WriteString("    else\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	b.WriteString("    fi\n")
+	b.WriteString("}\n\n")
+}
+
+func writeFishCompletion(w io.Writer, nodes []completionNode) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for lowkey\n")
+	b.WriteString("# Load with: lowkey completion fish | source\n\n")
+	b.WriteString("complete -c lowkey -f\n")
+	for _, flag := range directoryFlags {
+		fmt.Fprintf(&b, "complete -c lowkey %s -r -a '(__fish_complete_directories)'\n", fishFlag(flag))
+	}
+	for _, flag := range fileFlags {
+		fmt.Fprintf(&b, "complete -c lowkey %s -r -F\n", fishFlag(flag))
+	}
+
+	shorts := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		shorts[strings.Join(node.Path, "/")] = node.Short
+	}
+	for _, node := range nodes {
+		condition := fishCondition(node)
+		for _, name := range node.Subcommands {
+			short := shorts[strings.Join(append(append([]string(nil), node.Path...), name), "/")]
+			fmt.Fprintf(&b, "complete -c lowkey -n %q -a %s -d %q\n", condition, name, short)
+		}
+		for _, word := range node.Words {
+			fmt.Fprintf(&b, "complete -c lowkey -n %q -a %s\n", condition, word)
+		}
+		for _, flag := range node.Flags {
+			fmt.Fprintf(&b, "complete -c lowkey -n %q %s\n", condition, fishFlag(flag))
+		}
+		if node.Directories {
+			fmt.Fprintf(&b, "complete -c lowkey -n %q -a '(__fish_complete_directories)'\n", condition)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishCondition returns the `-n` test selecting node: its own command has
+// been typed and none of its subcommands have.
+func fishCondition(node completionNode) string {
+	if len(node.Path) == 0 {
+		return "__fish_use_subcommand"
+	}
+	condition := "__fish_seen_subcommand_from " + node.Path[len(node.Path)-1]
+	if len(node.Subcommands) > 0 {
+		condition += "; and not __fish_seen_subcommand_from " + strings.Join(node.Subcommands, " ")
+	}
+	return condition
+}
+
+// fishFlag renders a flag as fish `-l`/`-s` options.
+func fishFlag(flag string) string {
+	if strings.HasPrefix(flag, "--") {
+		return "-l " + strings.TrimPrefix(flag, "--")
+	}
+	return "-s " + strings.TrimPrefix(flag, "-")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUsageFlags(t *testing.T) {
+	got := usageFlags("prune [--older-than AGE] [--dir D] [--list] [--yes|-y] [--dir D]")
+	want := []string{"--older-than", "--dir", "--list", "--yes", "-y"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("usageFlags = %v, want %v", got, want)
+	}
+}
+
+func TestBashCompletionOffersCandidates(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	var script strings.Builder
+	if err := writeBashCompletion(&script, completionTree(rootCmd)); err != nil {
+		t.Fatalf("write bash completion: %v", err)
+	}
+
+	// complete runs the generated _lowkey function for a command line whose
+	// last word is being completed and returns the offered words.
+	complete := func(t *testing.T, words ...string) []string {
+		t.Helper()
+		var quoted []string
+		for _, word := range words {
+			quoted = append(quoted, "'"+word+"'")
+		}
+		cmd := exec.Command(bash, "--norc", "--noprofile", "-c", script.String()+
+			"COMP_WORDS=("+strings.Join(quoted, " ")+")\n"+
+			"COMP_CWORD=$((${#COMP_WORDS[@]} - 1))\n"+
+			"_lowkey\n"+
+			"printf '%s\\n' \"${COMPREPLY[@]}\"\n")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("run completion for %v: %v", words, err)
+		}
+		return strings.Fields(string(out))
+	}
+
+	cases := []struct {
+		name  string
+		words []string
+		want  []string
+		not   []string
+	}{
+		{name: "top level", words: []string{"lowkey", ""}, want: []string{"watch", "logs", "completion", "--state-dir"}, not: []string{"prune"}},
+		{name: "prefix", words: []string{"lowkey", "sta"}, want: []string{"start", "status"}, not: []string{"stop"}},
+		{name: "subcommand", words: []string{"lowkey", "logs", ""}, want: []string{"prune"}, not: []string{"watch"}},
+		{name: "subcommand flags", words: []string{"lowkey", "logs", "prune", "--"}, want: []string{"--older-than", "--list"}},
+		{name: "shells", words: []string{"lowkey", "completion", ""}, want: completionShells},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := complete(t, tc.words...)
+			offered := make(map[string]bool, len(got))
+			for _, word := range got {
+				offered[word] = true
+			}
+			for _, word := range tc.want {
+				if !offered[word] {
+					t.Errorf("expected %q to be offered, got %v", word, got)
+				}
+			}
+			for _, word := range tc.not {
+				if offered[word] {
+					t.Errorf("expected %q not to be offered, got %v", word, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCompletionRejectsUnknownShell(t *testing.T) {
+	err := newCompletionCmd().RunE(nil, []string{"powershell"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported shell "powershell"`) {
+		t.Fatalf("expected an unsupported shell error, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+	"lowkey/pkg/output"
+)
+
+// newConfigCmd creates the `config` command group for inspecting manifests.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and validate manifests",
+	}
+	cmd.AddCommand(newConfigValidateCmd(), newConfigShowCmd())
+	return cmd
+}
+
+// newConfigValidateCmd creates the `config validate` command, which checks a
+// manifest without starting the daemon and reports every problem found. It
+// exits non-zero when the manifest is invalid, which makes it suitable for CI.
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [path|-]",
+		Short: "Check a manifest for problems before starting the daemon",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("config: unexpected arguments: %v", args[1:])
+			}
+			path, err := manifestPathToValidate(args)
+			if err != nil {
+				return err
+			}
+
+			var problems []string
+			manifest, err := loadManifest(path)
+			if err != nil {
+				problems = append(problems, err.Error())
+			} else if err := manifest.Validate(); err != nil {
+				problems = append(problems, splitErrors(err)...)
+			}
+
+			if outputFormat == "json" {
+				report := struct {
+					Path     string   `json:"path"`
+					Valid    bool     `json:"valid"`
+					Problems []string `json:"problems"`
+				}{Path: path, Valid: len(problems) == 0, Problems: append([]string{}, problems...)}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else if len(problems) == 0 {
+				fmt.Printf("config: %s is valid\n", path)
+			} else {
+				fmt.Printf("config: %s has %d problem(s):\n", path, len(problems))
+				for _, problem := range problems {
+					fmt.Printf("  - %s\n", problem)
+				}
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("config: %s is invalid", path)
+			}
+			return nil
+		},
+	}
+}
+
+// effectiveConfig is what `config show` reports: the manifest other
+// commands use and the file it was loaded from.
+type effectiveConfig struct {
+	Source string `json:"source"`
+	// Origin tells how Source was found: "--config", "home directory", or
+	// "state directory".
+	Origin   string           `json:"origin,omitempty"`
+	Manifest *config.Manifest `json:"manifest"`
+	Note     string           `json:"note,omitempty"`
+}
+
+// redacted replaces credentials in the manifest printed by `config show`.
+const redacted = "(redacted)"
+
+// newConfigShowCmd creates the `config show` command, which prints the
+// manifest in effect after --config, ~/.lowkey.json, and the state directory
+// manifest have been considered, normalized as the other commands see it.
+func newConfigShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration and where it came from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return fmt.Errorf("config: unexpected arguments: %v", args)
+			}
+			effective, err := resolveEffectiveConfig()
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(effective)
+			case "yaml":
+				return output.WriteYAML(os.Stdout, effective)
+			}
+			if effective.Manifest == nil {
+				fmt.Printf("config: %s\n", effective.Note)
+				return nil
+			}
+			fmt.Printf("config: loaded from %s (%s)\n", effective.Source, effective.Origin)
+			data, err := json.MarshalIndent(effective.Manifest, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		},
+	}
+}
+
+// resolveEffectiveConfig reports the manifest initConfig selected. A file
+// named by --config that initConfig could not load is loaded again so its
+// error is reported instead of being mistaken for a missing config.
+func resolveEffectiveConfig() (effectiveConfig, error) {
+	home := ""
+	if dir, err := os.UserHomeDir(); err == nil {
+		home = filepath.Join(dir, ".lowkey.json")
+	}
+	stateManifest := ""
+	if stateDir, err := state.DefaultStateDir(); err == nil {
+		stateManifest = filepath.Join(stateDir, "daemon.json")
+	}
+
+	if manifestFromConfig == nil {
+		if cfgFile != "" && cfgFile != stateManifest {
+			if _, err := config.LoadManifest(cfgFile); err != nil {
+				return effectiveConfig{}, err
+			}
+		}
+		return effectiveConfig{
+			Note: fmt.Sprintf("no configuration found; pass --config FILE, create %s, or start the daemon to store %s", home, stateManifest),
+		}, nil
+	}
+
+	effective := effectiveConfig{Source: cfgFile, Origin: "--config"}
+	switch cfgFile {
+	case home:
+		effective.Origin = "home directory"
+	case stateManifest:
+		effective.Origin = "state directory"
+	}
+	manifest := *manifestFromConfig
+	if manifest.WebhookToken != "" {
+		manifest.WebhookToken = redacted
+	}
+	if manifest.WebhookSecret != "" {
+		manifest.WebhookSecret = redacted
+	}
+	effective.Manifest = &manifest
+	return effective, nil
+}
+
+// manifestPathToValidate picks the manifest `config validate` checks: the
+// explicit argument, then the file selected by --config or found by
+// initConfig, then the manifest in the state directory.
+func manifestPathToValidate(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	stateDir, err := state.DefaultStateDir()
+	if err != nil {
+		return "", err
+	}
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(store.Path()); errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("config: no manifest found at %s; pass a path to validate", filepath.Clean(store.Path()))
+	}
+	return store.Path(), nil
+}
+
+// splitErrors flattens an error produced by errors.Join into its messages.
+func splitErrors(err error) []string {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+	var messages []string
+	for _, e := range joined.Unwrap() {
+		messages = append(messages, e.Error())
+	}
+	return messages
+}
@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"lowkey/internal/buildinfo"
 	"lowkey/internal/daemon"
 	"lowkey/internal/state"
 	"lowkey/pkg/config"
@@ -39,15 +40,20 @@ func runDaemonProcess() error {
 		return err
 	}
 
-	if existing, ok := readPID(stateDir); ok && processAlive(existing) {
+	if existing, ok := runningDaemonPID(stateDir); ok {
 		return fmt.Errorf("daemon: process already running with pid %d", existing)
 	}
 
-	metricsAddr := os.Getenv(daemonMetricsEnv)
+	observability, err := config.ResolveObservability(config.ObservabilityFlags{}, os.Getenv, manifest)
+	if err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+
 	var metrics *telemetry.Collector
-	if metricsAddr != "" {
+	if observability.MetricsAddr != "" {
 		collector := telemetry.NewCollector()
-		if err := collector.Start(metricsAddr); err != nil {
+		collector.SetToken(observability.MetricsToken)
+		if err := collector.Start(observability.MetricsAddr); err != nil {
 			return fmt.Errorf("daemon: start metrics server: %w", err)
 		}
 		metrics = collector
@@ -58,27 +64,54 @@ func runDaemonProcess() error {
 		}()
 	}
 
-	traceEnabled := os.Getenv(daemonTraceEnv) == "1"
-	tracer := telemetry.NewTracer(telemetry.TracerOptions{Enabled: traceEnabled})
+	tracer := telemetry.NewTracer(telemetry.TracerOptions{
+		Enabled:  observability.TraceEnabled,
+		Endpoint: observability.TraceEndpoint,
+	})
 
-	cleanupPID, err := writePIDFile(stateDir)
+	manager, err := daemon.NewManager(store, manifest)
 	if err != nil {
 		return err
 	}
-	defer cleanupPID()
+	manager.SetTelemetry(metrics, tracer)
+	return serveDaemon(stateDir, manager, manager.StartContext)
+}
 
-	manager, err := daemon.NewManager(store, manifest)
+// serveDaemon writes the PID file, runs start and keeps the daemon up until
+// SIGINT or SIGTERM. The pause, resume and reload handlers are installed
+// before the PID file exists: `pause`, `enable` and `disable` signal the PID
+// they find there, and left to Go's defaults SIGHUP would kill a daemon still
+// in its initial scan while SIGUSR1 and SIGUSR2 would be dropped.
+func serveDaemon(stateDir string, manager *daemon.Manager, start func(context.Context) error) error {
+	// A signal received while the initial scan is still walking a large tree
+	// cancels it instead of waiting for it to finish.
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if pauseSignal != nil {
+		pauseCh := make(chan os.Signal, 1)
+		signal.Notify(pauseCh, pauseSignal, resumeSignal)
+		defer signal.Stop(pauseCh)
+		defer removePausedMarker(stateDir)
+		go handlePauseSignals(sigCtx, manager, stateDir, pauseCh)
+	}
+	if reloadSignal != nil {
+		reloadCh := make(chan os.Signal, 1)
+		signal.Notify(reloadCh, reloadSignal)
+		defer signal.Stop(reloadCh)
+		go handleReloadSignals(sigCtx, manager, reloadCh)
+	}
+
+	cleanupPID, err := writePIDFile(stateDir)
 	if err != nil {
 		return err
 	}
-	manager.SetTelemetry(metrics, tracer)
-	if err := manager.Start(); err != nil {
+	defer cleanupPID()
+
+	if err := start(sigCtx); err != nil {
 		return err
 	}
 
-	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
 	<-sigCtx.Done()
 
 	done := make(chan struct{})
@@ -94,16 +127,76 @@ func runDaemonProcess() error {
 	return nil
 }
 
+// handlePauseSignals toggles change recording when the daemon receives the
+// pause or resume signal, mirroring the state in a marker file that `status`
+// reads.
+func handlePauseSignals(ctx context.Context, manager *daemon.Manager, stateDir string, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == pauseSignal {
+				manager.Pause()
+				_ = os.WriteFile(pausedMarkerPath(stateDir), []byte(time.Now().UTC().Format(time.RFC3339)), 0o644)
+			} else {
+				manager.Resume()
+				removePausedMarker(stateDir)
+			}
+		}
+	}
+}
+
+// handleReloadSignals applies the stored manifest whenever the daemon receives
+// the reload signal, as sent by `enable` and `disable`. Failures are logged
+// by the manager and the previous watcher keeps running.
+func handleReloadSignals(ctx context.Context, manager *daemon.Manager, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			_, _ = manager.ReconcileManifest()
+		}
+	}
+}
+
+// pausedMarkerPath returns the path of the file that exists while the daemon
+// is paused.
+func pausedMarkerPath(stateDir string) string {
+	return filepath.Join(stateDir, daemonPausedFile)
+}
+
+// pausedMarkerExists reports whether the daemon has recorded itself as paused.
+func pausedMarkerExists(stateDir string) bool {
+	_, err := os.Stat(pausedMarkerPath(stateDir))
+	return err == nil
+}
+
+// removePausedMarker deletes the paused marker, ignoring a missing file.
+func removePausedMarker(stateDir string) {
+	_ = os.Remove(pausedMarkerPath(stateDir))
+}
+
 // writePIDFile creates a file containing the current process ID. This PID file
 // is used by other commands to check the status of the daemon and to send it
-// signals. It returns a cleanup function to remove the PID file on exit.
+// signals. When the platform exposes it, the process start time is recorded on
+// a second line so a PID later reused by another process can be told apart.
+// The daemon's version and commit follow so `status` can report the build that
+// is actually running. It returns a cleanup function to remove the PID file on
+// exit.
 func writePIDFile(stateDir string) (func(), error) {
 	if err := os.MkdirAll(stateDir, 0o755); err != nil {
 		return nil, err
 	}
 	path := pidFilePath(stateDir)
-	record := []byte(strconv.Itoa(os.Getpid()))
-	if err := os.WriteFile(path, record, 0o644); err != nil {
+	record := strconv.Itoa(os.Getpid()) + "\n"
+	if started, ok := processStartTime(os.Getpid()); ok {
+		record += "started=" + started + "\n"
+	}
+	build := buildinfo.Get()
+	record += "version=" + build.Version + "\ncommit=" + build.Commit + "\n"
+	if err := os.WriteFile(path, []byte(record), 0o644); err != nil {
 		return nil, err
 	}
 	return func() {
@@ -117,18 +210,73 @@ func pidFilePath(stateDir string) string {
 	return filepath.Join(stateDir, daemonPIDFilename)
 }
 
-// readPID reads the process ID from the daemon's PID file. It returns the PID
-// and a boolean indicating whether the file was successfully read.
-func readPID(stateDir string) (int, bool) {
+// pidRecord is the parsed content of the daemon's PID file. Started is empty
+// for PID files written by older versions or on platforms without start-time
+// support; Version and Commit are empty for PID files written by older
+// versions.
+type pidRecord struct {
+	PID     int
+	Started string
+	Version string
+	Commit  string
+}
+
+// readPIDRecord parses the daemon's PID file. The first line holds the PID;
+// optional `key=value` lines follow.
+func readPIDRecord(stateDir string) (pidRecord, bool) {
 	data, err := os.ReadFile(pidFilePath(stateDir))
 	if err != nil {
-		return 0, false
+		return pidRecord{}, false
 	}
-	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
 	if err != nil {
+		return pidRecord{}, false
+	}
+	record := pidRecord{PID: pid}
+	for _, line := range lines[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch key {
+		case "started":
+			record.Started = value
+		case "version":
+			record.Version = value
+		case "commit":
+			record.Commit = value
+		}
+	}
+	return record, true
+}
+
+// runningDaemonPID returns the PID of the live daemon recorded in stateDir. It
+// reports false when there is no PID file or the recorded process is not our
+// daemon (see isOurDaemon).
+func runningDaemonPID(stateDir string) (int, bool) {
+	record, ok := readPIDRecord(stateDir)
+	if !ok || !isOurDaemon(record) {
 		return 0, false
 	}
-	return pid, true
+	return record.PID, true
+}
+
+// isOurDaemon reports whether the process in record is alive and is the
+// daemon that wrote the PID file. A PID reused by another process is detected
+// by a start time that differs from the recorded one or, where the process
+// environment is readable, by the absence of the daemon marker variable.
+// Platforms offering neither check fall back to liveness alone.
+func isOurDaemon(record pidRecord) bool {
+	if !processAlive(record.PID) {
+		return false
+	}
+	if record.Started != "" {
+		if started, ok := processStartTime(record.PID); ok && started != record.Started {
+			return false
+		}
+	}
+	if isDaemon, known := processIsDaemon(record.PID); known && !isDaemon {
+		return false
+	}
+	return true
 }
 
 // processAlive checks if a process with the given PID is currently running.
@@ -0,0 +1,93 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"lowkey/internal/daemon"
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+func TestServeDaemonHandlesSignalsDuringStartup(t *testing.T) {
+	stateDir := t.TempDir()
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	manifest := &config.Manifest{Directories: []string{t.TempDir()}}
+	if err := store.Save(manifest); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+	manager, err := daemon.NewManager(store, manifest)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	// start stands in for a long initial scan until the test releases it.
+	starting := make(chan struct{})
+	release := make(chan struct{})
+	started := make(chan struct{})
+	start := func(ctx context.Context) error {
+		close(starting)
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer close(started)
+		return manager.StartContext(ctx)
+	}
+	served := make(chan error, 1)
+	go func() { served <- serveDaemon(stateDir, manager, start) }()
+	<-starting
+
+	if _, ok := readPIDRecord(stateDir); !ok {
+		t.Fatalf("expected the PID file to be written before startup finishes")
+	}
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("find process: %v", err)
+	}
+	// Without a handler, Go's default action for the reload signal would end
+	// the test binary here.
+	if err := self.Signal(reloadSignal); err != nil {
+		t.Fatalf("send reload signal: %v", err)
+	}
+	if err := self.Signal(pauseSignal); err != nil {
+		t.Fatalf("send pause signal: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !pausedMarkerExists(stateDir) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !pausedMarkerExists(stateDir) {
+		t.Fatalf("expected a pause sent during startup to be acknowledged")
+	}
+
+	close(release)
+	<-started
+	if !manager.Status().Paused {
+		t.Fatalf("expected the daemon to stay paused once started")
+	}
+
+	if err := self.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("send SIGTERM: %v", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("daemon did not stop after SIGTERM")
+	}
+	if _, err := os.Stat(pidFilePath(stateDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected the PID file to be removed on shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/daemon"
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+// newDiffCmd creates the `diff` command, which previews what reconciling the
+// running daemon with another manifest would change.
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <manifest.json|->",
+		Short: "Compare the stored manifest with another one",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("diff: expected one manifest path, got %d arguments", len(args))
+			}
+			desired, err := loadManifest(args[0])
+			if err != nil {
+				return fmt.Errorf("diff: %w", err)
+			}
+
+			stateDir, err := state.DefaultStateDir()
+			if err != nil {
+				return err
+			}
+			store, err := state.NewManifestStore(stateDir)
+			if err != nil {
+				return err
+			}
+			current, err := store.Load()
+			if err != nil {
+				return err
+			}
+			// Without a stored manifest, everything in the new one is added.
+			if current == nil {
+				current = &config.Manifest{}
+			}
+
+			diff := daemon.DiffManifests(current, desired)
+			if outputFormat == "json" {
+				// Empty lists are printed as [] rather than null.
+				diff.Added = append([]string{}, diff.Added...)
+				diff.Removed = append([]string{}, diff.Removed...)
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(diff)
+			}
+			printManifestDiff(diff)
+			return nil
+		},
+	}
+}
+
+// printManifestDiff prints diff with one line per change: `+` and `-` for
+// added and removed directories, then changed settings as `old -> new`.
+func printManifestDiff(diff daemon.ManifestDiff) {
+	if diff.IsEmpty() {
+		fmt.Println("diff: no changes")
+		return
+	}
+	for _, dir := range diff.Added {
+		fmt.Printf("+ %s\n", dir)
+	}
+	for _, dir := range diff.Removed {
+		fmt.Printf("- %s\n", dir)
+	}
+	for _, setting := range []struct {
+		name   string
+		change *daemon.SettingChange
+	}{
+		{"log_path", diff.LogPath},
+		{"ignore_file", diff.IgnoreFile},
+		{"mirror", diff.Mirror},
+	} {
+		if setting.change != nil {
+			fmt.Printf("%s: %s -> %s\n", setting.name, orUnset(setting.change.From), orUnset(setting.change.To))
+		}
+	}
+}
+
+// orUnset returns value, or "(unset)" when it is empty.
+func orUnset(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/state"
+)
+
+// newEnableCmd creates the `enable` command, which resumes watching
+// directories previously disabled with `disable`.
+func newEnableCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "enable <dir> [dir ...]",
+		Short: "Resume watching disabled directories",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setDirectoriesEnabled("enable", args, true)
+		},
+	}
+}
+
+// newDisableCmd creates the `disable` command, which stops watching
+// directories while keeping them, and their settings, in the manifest.
+func newDisableCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "disable <dir> [dir ...]",
+		Short: "Stop watching directories without removing them from the manifest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setDirectoriesEnabled("disable", args, false)
+		},
+	}
+}
+
+// setDirectoriesEnabled flips the enabled state of dirs in the stored
+// manifest and asks a running daemon to reconcile with it.
+func setDirectoriesEnabled(name string, dirs []string, enabled bool) error {
+	if len(dirs) == 0 {
+		return fmt.Errorf("%s: provide at least one directory", name)
+	}
+	stateDir, err := state.DefaultStateDir()
+	if err != nil {
+		return err
+	}
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		return err
+	}
+	manifest, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		return fmt.Errorf("%s: no manifest stored; start the daemon first", name)
+	}
+
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("%s: resolve %s: %w", name, dir, err)
+		}
+		if err := manifest.SetEnabled(abs, enabled); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if len(manifest.EnabledDirectories()) == 0 {
+		return fmt.Errorf("%s: every directory would be disabled; use `lowkey stop` to stop the daemon instead", name)
+	}
+	if err := store.Save(manifest); err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		abs, _ := filepath.Abs(dir)
+		fmt.Printf("%sd %s\n", name, abs)
+	}
+
+	pid, running := runningDaemonPID(stateDir)
+	if !running {
+		return nil
+	}
+	if reloadSignal == nil {
+		fmt.Println("restart the daemon to apply the change")
+		return nil
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(reloadSignal); err != nil {
+		return fmt.Errorf("%s: signal daemon: %w", name, err)
+	}
+	return nil
+}
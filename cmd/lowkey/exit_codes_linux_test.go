@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+func TestStatusAndStopExitCodes(t *testing.T) {
+	running := func(t *testing.T) string {
+		daemon := startFakeDaemon(t)
+		return strconv.Itoa(daemon.PID) + "\nstarted=" + daemon.Started + "\n"
+	}
+	stale := func(t *testing.T) string {
+		exited := exec.Command("true")
+		if err := exited.Run(); err != nil {
+			t.Skipf("cannot run a child process: %v", err)
+		}
+		return strconv.Itoa(exited.ProcessState.Pid()) + "\n"
+	}
+	reused := func(t *testing.T) string {
+		// This test process is alive but is not a lowkey daemon.
+		return strconv.Itoa(os.Getpid()) + "\n"
+	}
+
+	tests := []struct {
+		name     string
+		cmd      func() *cobra.Command
+		manifest bool
+		pidFile  func(t *testing.T) string
+		want     int
+	}{
+		{name: "status running", cmd: newStatusCmd, manifest: true, pidFile: running, want: 0},
+		{name: "status stopped", cmd: newStatusCmd, manifest: true, want: exitNotRunning},
+		{name: "status stale pid", cmd: newStatusCmd, manifest: true, pidFile: stale, want: exitNotRunning},
+		{name: "status reused pid", cmd: newStatusCmd, manifest: true, pidFile: reused, want: exitNotRunning},
+		{name: "status not configured", cmd: newStatusCmd, want: exitNotConfigured},
+		{name: "stop running", cmd: newStopCmd, manifest: true, pidFile: running, want: 0},
+		{name: "stop stopped", cmd: newStopCmd, manifest: true, want: exitNotRunning},
+		{name: "stop stale pid", cmd: newStopCmd, manifest: true, pidFile: stale, want: exitNotRunning},
+		{name: "stop reused pid", cmd: newStopCmd, manifest: true, pidFile: reused, want: exitNotRunning},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stateDir := filepath.Join(t.TempDir(), "lowkey")
+			t.Setenv("XDG_STATE_HOME", filepath.Dir(stateDir))
+			store, err := state.NewManifestStore(stateDir)
+			if err != nil {
+				t.Fatalf("new store: %v", err)
+			}
+			if tc.manifest {
+				if err := store.Save(&config.Manifest{Directories: []string{t.TempDir()}}); err != nil {
+					t.Fatalf("save manifest: %v", err)
+				}
+			}
+			if tc.pidFile != nil {
+				if err := os.WriteFile(pidFilePath(stateDir), []byte(tc.pidFile(t)), 0o644); err != nil {
+					t.Fatalf("write pid file: %v", err)
+				}
+			}
+
+			var runErr error
+			captureStdout(t, func() { runErr = tc.cmd().RunE(nil, nil) })
+			code := 0
+			var exitErr exitCodeError
+			if errors.As(runErr, &exitErr) {
+				code = exitErr.code
+			} else if runErr != nil {
+				t.Fatalf("unexpected error: %v", runErr)
+			}
+			if code != tc.want {
+				t.Fatalf("exit code = %d, want %d", code, tc.want)
+			}
+		})
+	}
+}
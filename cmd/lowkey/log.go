@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -18,9 +27,35 @@ import (
 // and colorized output based on event types.
 func newLogCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "log [PATTERN]",
+		Use:   "log [--follow] [--since-boot] [--context N|-C N] [-A N] [-B N] [--limit N|--tail N|--head N] [PATTERN]",
 		Short: "View logs with optional grep pattern",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			window, args, err := parseLogContext(args)
+			if err != nil {
+				return err
+			}
+			limit, args, err := parseLogLimit(args)
+			if err != nil {
+				return err
+			}
+			follow, sinceBoot, args := parseLogFlags(args)
+			if follow && window.enabled() {
+				return errors.New("log: --context, -A and -B cannot be combined with --follow")
+			}
+			if limit.head > 0 && follow {
+				return errors.New("log: --head cannot be combined with --follow; use --tail N to start following after the last N entries")
+			}
+			if limit.enabled() && window.enabled() {
+				return errors.New("log: --limit, --head and --tail cannot be combined with --context, -A or -B")
+			}
+			jsonOutput := outputFormat == "json"
+			structured := jsonOutput || outputFormat == "yaml"
+			if structured && window.enabled() {
+				return fmt.Errorf("log: --context, -A and -B cannot be combined with --output %s", outputFormat)
+			}
+			if outputFormat == "yaml" && follow {
+				return errors.New("log: --output yaml cannot be combined with --follow; use --output json")
+			}
 			// Validate args count
 			if len(args) > 1 {
 				return errors.New("log command accepts at most one argument (pattern)")
@@ -34,44 +69,381 @@ func newLogCmd() *cobra.Command {
 			// Use the first watched directory's .lowlog
 			logDir := filepath.Join(dirs[0], ".lowlog")
 
-			// Check if log directory exists
-			if _, err := os.Stat(logDir); os.IsNotExist(err) {
-				fmt.Printf("no logs found at %s\n", logDir)
-				return nil
-			}
-
 			// Extract optional grep pattern
 			pattern := ""
 			if len(args) > 0 {
 				pattern = args[0]
 			}
 
+			// A structured export covers every watched directory, each
+			// entry naming the directory it was logged for.
+			if structured && !follow {
+				entries, err := readLogEntries(dirs, pattern, sinceBoot, limit)
+				if err != nil {
+					return err
+				}
+				if err := ensureRenderer(); err != nil {
+					return err
+				}
+				return outputRenderer.Logs(entries)
+			}
+
+			// Check if log directory exists
+			if _, err := os.Stat(logDir); os.IsNotExist(err) && !follow {
+				if !jsonOutput {
+					fmt.Printf("no logs found at %s\n", logDir)
+				}
+				return nil
+			}
+
 			// Read logs with optional filtering
 			reader := logs.NewReader(logDir)
-			lines, err := reader.ReadLines(pattern)
-			if err != nil {
-				return err
+			reader.SetSinceBoot(sinceBoot)
+			if window.enabled() {
+				blocks, err := reader.ReadContext(pattern, window.before, window.after)
+				if err != nil {
+					return err
+				}
+				if len(blocks) == 0 {
+					fmt.Printf("no logs found matching pattern: %s\n", pattern)
+					return nil
+				}
+				printContextBlocks(blocks)
+				return nil
 			}
+			emit := printColoredLogLine
+			if jsonOutput {
+				// Followed JSON output carries the parsed entries, one
+				// object per line; lines that do not parse are left out.
+				encoder := json.NewEncoder(os.Stdout)
+				emit = func(line string) {
+					if entry, ok := logs.ParseEntry(line); ok {
+						_ = encoder.Encode(entry)
+					}
+				}
+				lines, err := limit.read(reader, pattern)
+				if err != nil && !follow {
+					return err
+				}
+				for _, line := range lines {
+					emit(line)
+				}
+			} else {
+				lines, err := limit.read(reader, pattern)
+				if err != nil && !follow {
+					return err
+				}
 
-			if len(lines) == 0 {
-				if pattern != "" {
-					fmt.Printf("no logs found matching pattern: %s\n", pattern)
-				} else {
-					fmt.Println("no logs found")
+				if len(lines) == 0 && !follow {
+					if pattern != "" {
+						fmt.Printf("no logs found matching pattern: %s\n", pattern)
+					} else {
+						fmt.Println("no logs found")
+					}
+					return nil
+				}
+
+				// Print logs with color coding
+				for _, line := range lines {
+					printColoredLogLine(line)
 				}
+			}
+
+			if !follow {
 				return nil
 			}
 
-			// Print logs with color coding
-			for _, line := range lines {
-				printColoredLogLine(line)
+			var matcher *regexp.Regexp
+			if pattern != "" {
+				if matcher, err = regexp.Compile("(?i)" + pattern); err != nil {
+					return fmt.Errorf("invalid grep pattern: %w", err)
+				}
 			}
 
+			signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			err = followLogDir(signalCtx, logDir, func(line string) {
+				if strings.TrimSpace(line) == "" {
+					return
+				}
+				if matcher != nil && !matcher.MatchString(line) {
+					return
+				}
+				emit(line)
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
 			return nil
 		},
 	}
 }
 
+// parseLogFlags processes the command-line arguments for the `log` command,
+// extracting the --follow/-f and --since-boot flags if present.
+func parseLogFlags(args []string) (follow, sinceBoot bool, remaining []string) {
+	remaining = make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "--follow", "-f":
+			follow = true
+		case "--since-boot":
+			sinceBoot = true
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return follow, sinceBoot, remaining
+}
+
+// readLogEntries reads the parsed entries of the .lowlog directory of every
+// dir, sets their Directory, and merges them in time order. The limit
+// applies to the merged entries.
+func readLogEntries(dirs []string, pattern string, sinceBoot bool, limit logLimit) ([]logs.LogEntry, error) {
+	var entries []logs.LogEntry
+	for _, dir := range dirs {
+		logDir := filepath.Join(dir, ".lowlog")
+		if _, err := os.Stat(logDir); os.IsNotExist(err) {
+			continue
+		}
+		reader := logs.NewReader(logDir)
+		reader.SetSinceBoot(sinceBoot)
+		lines, err := limit.read(reader, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			if entry, ok := logs.ParseEntry(line); ok {
+				entry.Directory = dir
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	switch {
+	case limit.head > 0 && len(entries) > limit.head:
+		entries = entries[:limit.head]
+	case limit.tail > 0 && len(entries) > limit.tail:
+		entries = entries[len(entries)-limit.tail:]
+	}
+	return entries, nil
+}
+
+// logLimit bounds the entries `log` prints to the first or last few.
+type logLimit struct {
+	head, tail int
+}
+
+// enabled reports whether the output is bounded.
+func (l logLimit) enabled() bool {
+	return l.head > 0 || l.tail > 0
+}
+
+// read returns the matching log lines within the limit. Bounded reads stop
+// early instead of loading every log file.
+func (l logLimit) read(reader *logs.Reader, pattern string) ([]string, error) {
+	switch {
+	case l.head > 0:
+		return reader.HeadLines(pattern, l.head)
+	case l.tail > 0:
+		return reader.TailLines(pattern, l.tail)
+	default:
+		return reader.ReadLines(pattern)
+	}
+}
+
+// parseLogLimit extracts --head, --tail, and --limit, an alias of --tail
+// since the most recent entries are usually the interesting ones.
+func parseLogLimit(args []string) (logLimit, []string, error) {
+	var limit logLimit
+	head, args := extractOption(args, "--head")
+	tail, args := extractOption(args, "--tail")
+	last, args := extractOption(args, "--limit")
+	if tail != "" && last != "" && tail != last {
+		return logLimit{}, nil, errors.New("log: --tail and --limit are the same option; pass only one")
+	}
+	if last != "" {
+		tail = last
+	}
+	if head != "" && tail != "" {
+		return logLimit{}, nil, errors.New("log: --head cannot be combined with --tail or --limit")
+	}
+	for _, option := range []struct {
+		flag, value string
+		target      *int
+	}{
+		{"--head", head, &limit.head},
+		{"--tail", tail, &limit.tail},
+	} {
+		if option.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(option.value)
+		if err != nil || n <= 0 {
+			return logLimit{}, nil, fmt.Errorf("log: invalid %s %q: must be a positive number of entries", option.flag, option.value)
+		}
+		*option.target = n
+	}
+	return limit, args, nil
+}
+
+// logContext is the number of lines `log` prints before and after each match.
+type logContext struct {
+	before, after int
+}
+
+// enabled reports whether any context was requested.
+func (c logContext) enabled() bool {
+	return c.before > 0 || c.after > 0
+}
+
+// parseLogContext extracts --context/-C, -A and -B. --context sets both sides;
+// -A and -B override it for their side.
+func parseLogContext(args []string) (logContext, []string, error) {
+	var window logContext
+	both, args := extractOption(args, "--context", "-C")
+	after, args := extractOption(args, "-A")
+	before, args := extractOption(args, "-B")
+	for _, option := range []struct {
+		flag, value string
+		targets     []*int
+	}{
+		{"--context", both, []*int{&window.before, &window.after}},
+		{"-A", after, []*int{&window.after}},
+		{"-B", before, []*int{&window.before}},
+	} {
+		if option.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(option.value)
+		if err != nil || n < 0 {
+			return logContext{}, nil, fmt.Errorf("log: invalid %s %q: must be a non-negative number of lines", option.flag, option.value)
+		}
+		for _, target := range option.targets {
+			*target = n
+		}
+	}
+	return window, args, nil
+}
+
+// printContextBlocks prints the blocks returned by logs.Reader.ReadContext,
+// separated by "--" like grep. Matches keep their event colors; context
+// lines are dimmed.
+func printContextBlocks(blocks [][]logs.ContextLine) {
+	for i, block := range blocks {
+		if i > 0 {
+			fmt.Println("--")
+		}
+		for _, line := range block {
+			if line.Match {
+				printColoredLogLine(line.Text)
+			} else {
+				fmt.Println(colors.Colorize(line.Text, colors.Dim))
+			}
+		}
+	}
+}
+
+// followLogDir streams lines appended to the dated log files in logDir,
+// starting from the current end of the newest file. When a newer
+// `YYYY-MM-DD.log` file appears (for example at midnight), the remainder of
+// the previous file is drained before switching to the new one. Partial lines
+// are buffered until their newline arrives. It runs until ctx is canceled.
+func followLogDir(ctx context.Context, logDir string, emit func(string)) error {
+	reader := logs.NewReader(logDir)
+
+	current := ""
+	var offset int64
+	if files, err := reader.LogFiles(); err == nil && len(files) > 0 {
+		current = files[len(files)-1]
+		if info, err := os.Stat(current); err == nil {
+			offset = info.Size()
+		}
+	}
+
+	lines := &lineWriter{emit: emit}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if current != "" {
+			data, next, err := readAppended(current, offset)
+			if err != nil {
+				return err
+			}
+			offset = next
+			_, _ = lines.Write(data)
+		}
+
+		files, err := reader.LogFiles()
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 && files[len(files)-1] != current {
+			// Lines may have been appended to the old file since it was
+			// read above, before the new one appeared.
+			if current != "" {
+				data, _, err := readAppended(current, offset)
+				if err != nil {
+					return err
+				}
+				_, _ = lines.Write(data)
+			}
+			lines.Flush()
+			current = files[len(files)-1]
+			offset = 0
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(400 * time.Millisecond):
+		}
+	}
+}
+
+// readAppended returns the bytes written to path beyond offset along with the
+// new offset. A file that shrank is treated as truncated and re-read from the
+// start; a missing file yields no data.
+func readAppended(path string, offset int64) ([]byte, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, offset, nil
+		}
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if info.Size() == offset {
+		return nil, offset, nil
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	buffer := make([]byte, info.Size()-offset)
+	if _, err := io.ReadFull(file, buffer); err != nil {
+		return nil, offset, err
+	}
+	return buffer, info.Size(), nil
+}
+
 // printColoredLogLine prints a log line with appropriate color based on event type
 func printColoredLogLine(line string) {
 	// Determine color based on event type in the line
@@ -82,6 +454,10 @@ func printColoredLogLine(line string) {
 		color = colors.Yellow
 	} else if strings.Contains(line, "[DELETED]") {
 		color = colors.Red
+	} else if strings.Contains(line, "[ROOT_LOST]") {
+		color = colors.Red
+	} else if strings.Contains(line, "[BOOT]") || strings.Contains(line, "[ROOT_RESTORED]") {
+		color = colors.Blue
 	} else {
 		// No color for unrecognized format
 		fmt.Println(line)
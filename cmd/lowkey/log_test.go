@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadAppendedRereadsTruncatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2026-10-01.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data, offset, err := readAppended(path, 4)
+	if err != nil || string(data) != "two\n" || offset != 8 {
+		t.Fatalf("readAppended = %q, %d, %v; want the bytes past the offset", data, offset, err)
+	}
+	if data, offset, err = readAppended(path, offset); err != nil || len(data) != 0 || offset != 8 {
+		t.Fatalf("readAppended = %q, %d, %v; want nothing new", data, offset, err)
+	}
+
+	if err := os.WriteFile(path, []byte("new\n"), 0o644); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	if data, offset, err = readAppended(path, offset); err != nil || string(data) != "new\n" || offset != 4 {
+		t.Fatalf("readAppended = %q, %d, %v; want the truncated file from the start", data, offset, err)
+	}
+
+	if data, offset, err = readAppended(filepath.Join(filepath.Dir(path), "missing.log"), 7); err != nil || data != nil || offset != 7 {
+		t.Fatalf("readAppended on a missing file = %q, %d, %v", data, offset, err)
+	}
+}
+
+func TestFollowLogDirBuffersPartialLinesAndRotatesAtMidnight(t *testing.T) {
+	dir := t.TempDir()
+	today := filepath.Join(dir, "2026-10-01.log")
+	if err := os.WriteFile(today, []byte("history\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var mu sync.Mutex
+	var lines []string
+	snapshot := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- followLogDir(ctx, dir, func(line string) {
+			mu.Lock()
+			lines = append(lines, line)
+			mu.Unlock()
+		})
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	appendTo := func(path, text string) {
+		t.Helper()
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(text); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	waitFor := func(want ...string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for strings.Join(snapshot(), "|") != strings.Join(want, "|") {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q, got %q", want, snapshot())
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// Let the follower settle on the end of the existing file first.
+	time.Sleep(100 * time.Millisecond)
+	appendTo(today, "[NEW] par")
+	time.Sleep(900 * time.Millisecond)
+	if got := snapshot(); len(got) != 0 {
+		t.Fatalf("expected a partial line to be held back, got %q", got)
+	}
+	appendTo(today, "tial\n")
+	waitFor("[NEW] partial")
+
+	// At midnight the last lines of the old file are drained before the
+	// follower switches to the new one and reads it from the start.
+	appendTo(today, "[NEW] last of the day\n")
+	appendTo(filepath.Join(dir, "2026-10-02.log"), "[NEW] first of the next\n")
+	waitFor("[NEW] partial", "[NEW] last of the day", "[NEW] first of the next")
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/logs"
+	"lowkey/pkg/output"
+)
+
+// newLogsCmd creates the `logs` command group, which holds maintenance verbs
+// for the dated change logs kept in each watched directory's .lowlog folder.
+func newLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Manage .lowlog change log files",
+	}
+	cmd.AddCommand(newLogsPruneCmd())
+	return cmd
+}
+
+// newLogsPruneCmd creates the `logs prune` command, which lists dated
+// `.lowlog` files and deletes those older than a threshold. Only files named
+// `YYYY-MM-DD.log` are considered so user files in `.lowlog` are never touched.
+func newLogsPruneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune [--older-than AGE] [--dir D] [--list] [--yes]",
+		Short: "List or delete dated .lowlog files older than a threshold",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, remaining, err := parseLogsPruneFlags(args)
+			if err != nil {
+				return err
+			}
+			if len(remaining) > 0 {
+				return fmt.Errorf("logs prune: unexpected arguments: %v", remaining)
+			}
+			if opts.olderThan <= 0 && !opts.list {
+				return errors.New("logs prune: --older-than is required unless --list is given")
+			}
+
+			dirs := opts.dirs
+			if len(dirs) == 0 {
+				dirs = loadWatchTargetsFromConfig()
+			}
+			if len(dirs) == 0 {
+				return errors.New("logs prune: no watched directories configured; pass --dir")
+			}
+
+			var cutoff time.Time
+			if opts.olderThan > 0 {
+				cutoff = startOfDay(time.Now().Add(-opts.olderThan))
+			}
+
+			var candidates []logs.DatedLogFile
+			for _, dir := range dirs {
+				logDir := lowlogDir(dir)
+				files, err := logs.NewReader(logDir).DatedLogFiles()
+				if err != nil {
+					return err
+				}
+				var selected []logs.DatedLogFile
+				for _, file := range files {
+					if cutoff.IsZero() || file.Date.Before(cutoff) {
+						selected = append(selected, file)
+					}
+				}
+				if len(selected) == 0 {
+					continue
+				}
+				fmt.Printf("%s:\n", logDir)
+				for _, file := range selected {
+					fmt.Printf("  %s  %s\n", filepath.Base(file.Path), output.FormatBytes(file.Size))
+				}
+				candidates = append(candidates, selected...)
+			}
+
+			if len(candidates) == 0 {
+				fmt.Println("logs prune: no matching log files")
+				return nil
+			}
+			var total int64
+			for _, file := range candidates {
+				total += file.Size
+			}
+			fmt.Printf("%d files, %s\n", len(candidates), output.FormatBytes(total))
+			if opts.list {
+				return nil
+			}
+
+			if !opts.yes && !confirm("delete these files? [y/N]: ") {
+				fmt.Println("logs prune: aborted")
+				return nil
+			}
+
+			paths := make([]string, 0, len(candidates))
+			for _, file := range candidates {
+				paths = append(paths, file.Path)
+			}
+			if err := removePaths(paths); err != nil {
+				return err
+			}
+			fmt.Printf("logs prune: removed %d files\n", len(paths))
+			return nil
+		},
+	}
+}
+
+// logsPruneOptions holds the flags accepted by the `logs prune` command.
+type logsPruneOptions struct {
+	olderThan time.Duration
+	dirs      []string
+	list      bool
+	yes       bool
+}
+
+// parseLogsPruneFlags processes the command-line arguments for the `logs
+// prune` command.
+func parseLogsPruneFlags(args []string) (opts logsPruneOptions, remaining []string, err error) {
+	remaining = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--older-than":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("logs prune: --older-than requires a value")
+			}
+			if opts.olderThan, err = parseAge(args[i+1]); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--older-than="):
+			if opts.olderThan, err = parseAge(arg[len("--older-than="):]); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--dir":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("logs prune: --dir requires a value")
+			}
+			opts.dirs = append(opts.dirs, args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--dir="):
+			opts.dirs = append(opts.dirs, arg[len("--dir="):])
+		case arg == "--list":
+			opts.list = true
+		case arg == "--yes" || arg == "-y":
+			opts.yes = true
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return opts, remaining, nil
+}
+
+// parseAge parses an age such as "30d", "2w", or any time.ParseDuration value
+// ("36h"). Days and weeks are not supported by the standard parser but are the
+// natural units for log retention.
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if !strings.HasSuffix(value, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return d, nil
+}
+
+// lowlogDir returns the .lowlog directory for a watched directory. A path that
+// already points at a .lowlog directory is returned unchanged.
+func lowlogDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if filepath.Base(dir) == ".lowlog" {
+		return dir
+	}
+	return filepath.Join(dir, ".lowlog")
+}
+
+// startOfDay truncates t to local midnight.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
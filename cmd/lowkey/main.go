@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 )
@@ -17,6 +18,10 @@ func main() {
 		return
 	}
 	if err := execute(os.Args[1:]); err != nil {
+		var exit exitCodeError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		fmt.Fprintf(os.Stderr, "lowkey: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/state"
+)
+
+// newPauseCmd creates the `pause` command, which asks the running daemon to
+// stop recording changes without shutting down. This is useful during bulk
+// operations such as checkouts or builds.
+func newPauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause",
+		Short: "Temporarily stop recording changes in the running daemon",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return sendPauseSignal("pause", pauseSignal, true)
+		},
+	}
+}
+
+// newResumeCmd creates the `resume` command, which re-enables change recording
+// in a paused daemon. The daemon runs a safety scan on resume so changes made
+// while paused are still reported.
+func newResumeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume",
+		Short: "Resume recording changes in a paused daemon",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return sendPauseSignal("resume", resumeSignal, false)
+		},
+	}
+}
+
+// sendPauseSignal delivers sig to the running daemon and waits briefly for the
+// paused marker to reflect the requested state.
+func sendPauseSignal(name string, sig os.Signal, paused bool) error {
+	if sig == nil {
+		return fmt.Errorf("%s: not supported on this platform", name)
+	}
+	stateDir, err := state.DefaultStateDir()
+	if err != nil {
+		return err
+	}
+	pid, ok := runningDaemonPID(stateDir)
+	if !ok {
+		return errors.New(name + ": daemon is not running")
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(sig); err != nil {
+		return fmt.Errorf("%s: signal daemon: %w", name, err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pausedMarkerExists(stateDir) != paused && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if pausedMarkerExists(stateDir) != paused {
+		return fmt.Errorf("%s: daemon did not acknowledge the request", name)
+	}
+
+	if paused {
+		fmt.Println("daemon paused")
+	} else {
+		fmt.Println("daemon resumed")
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseSignal and resumeSignal ask the daemon to suspend and resume change
+// recording.
+var (
+	pauseSignal  os.Signal = syscall.SIGUSR1
+	resumeSignal os.Signal = syscall.SIGUSR2
+)
+
+// reloadSignal asks the daemon to reconcile with the stored manifest.
+var reloadSignal os.Signal = syscall.SIGHUP
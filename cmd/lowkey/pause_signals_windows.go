@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// Windows has no user-defined signals, so pause and resume are unsupported,
+// and a running daemon only picks up manifest edits when restarted.
+var (
+	pauseSignal  os.Signal
+	resumeSignal os.Signal
+	reloadSignal os.Signal
+)
@@ -0,0 +1,47 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStartTime returns the start time of pid in clock ticks since boot,
+// read from /proc/<pid>/stat. The value is opaque and only compared for
+// equality.
+func processStartTime(pid int) (string, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return "", false
+	}
+	// The command name is parenthesised and may contain spaces, so fields are
+	// counted from the closing parenthesis. starttime is field 22 overall,
+	// which is the 20th field after it.
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return "", false
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 20 {
+		return "", false
+	}
+	return fields[19], true
+}
+
+// processIsDaemon reports whether pid is a lowkey daemon by looking for the
+// daemon marker variable in /proc/<pid>/environ. known is false when the
+// environment cannot be read, for example for another user's process.
+func processIsDaemon(pid int) (isDaemon, known bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/environ")
+	if err != nil {
+		return false, false
+	}
+	for _, entry := range strings.Split(string(data), "\x00") {
+		if entry == daemonEnvKey+"=1" {
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// startFakeDaemon starts a long-running process marked as a lowkey daemon and
+// returns its PID record as the daemon would write it.
+func startFakeDaemon(t *testing.T) pidRecord {
+	t.Helper()
+	cmd := exec.Command("sleep", "30")
+	cmd.Env = append(os.Environ(), daemonEnvKey+"=1")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start a child process: %v", err)
+	}
+	// Reap the process as soon as it exits so it stops looking alive.
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-exited
+	})
+	started, ok := processStartTime(cmd.Process.Pid)
+	if !ok {
+		t.Skip("process start times are unavailable")
+	}
+	return pidRecord{PID: cmd.Process.Pid, Started: started}
+}
+
+func TestIsOurDaemonChecksStartTimeAndEnvironment(t *testing.T) {
+	daemon := startFakeDaemon(t)
+	if !isOurDaemon(daemon) {
+		t.Fatalf("expected the marked process with a matching start time to be our daemon")
+	}
+
+	// The PID now belongs to a process started at another time.
+	reused := daemon
+	reused.Started = "1"
+	if isOurDaemon(reused) {
+		t.Fatalf("expected a reused PID with another start time not to be our daemon")
+	}
+
+	// This test process is alive but lacks the daemon marker.
+	self, ok := processStartTime(os.Getpid())
+	if !ok {
+		t.Fatalf("expected the start time of the test process")
+	}
+	if isOurDaemon(pidRecord{PID: os.Getpid(), Started: self}) {
+		t.Fatalf("expected a process without the daemon marker not to be our daemon")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+// processStartTime is unavailable on this platform, so stale PID detection
+// falls back to checking that the process is alive.
+func processStartTime(pid int) (string, bool) {
+	return "", false
+}
+
+// processIsDaemon cannot inspect other processes on this platform.
+func processIsDaemon(pid int) (isDaemon, known bool) {
+	return false, false
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newReadCmd creates the `read` command, the read-side counterpart of
+// `append`. It streams a JSON lines file, re-emitting the entries that match
+// the --where conditions and time bounds exactly as they were stored.
+func newReadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "read --file PATH|- [--where KEY=VALUE]... [--since TIME] [--until TIME] [--time-field KEY]",
+		Short: "Read and filter JSON lines written by append",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, remaining, err := parseReadFlags(args, time.Now())
+			if err != nil {
+				return err
+			}
+			if len(remaining) > 0 {
+				return fmt.Errorf("read: unexpected arguments: %v", remaining)
+			}
+			if opts.file == "" {
+				return errors.New("read: --file is required")
+			}
+
+			input := io.ReadCloser(io.NopCloser(os.Stdin))
+			if opts.file != "-" {
+				file, err := os.Open(opts.file)
+				if err != nil {
+					return fmt.Errorf("read: %w", err)
+				}
+				input = file
+			}
+			defer input.Close()
+
+			out := bufio.NewWriter(os.Stdout)
+			defer out.Flush()
+			return readEntries(input, out, os.Stderr, opts)
+		},
+	}
+}
+
+// readOptions holds the flags accepted by the `read` command.
+type readOptions struct {
+	file      string
+	where     []fieldFilter
+	since     time.Time
+	until     time.Time
+	timeField string
+}
+
+// parseReadFlags processes the command-line arguments for the `read` command.
+// Relative --since/--until values are resolved against now.
+func parseReadFlags(args []string, now time.Time) (opts readOptions, remaining []string, err error) {
+	opts.timeField = "ts"
+	remaining = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--file" || arg == "-f":
+			// "-" is accepted here because it explicitly selects stdin.
+			if i+1 < len(args) && (args[i+1] == "-" || !strings.HasPrefix(args[i+1], "-")) {
+				opts.file = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--file="):
+			opts.file = arg[len("--file="):]
+		case arg == "--where":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("read: --where requires KEY=VALUE")
+			}
+			if err := opts.addWhere(args[i+1]); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--where="):
+			if err := opts.addWhere(arg[len("--where="):]); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--since" || arg == "--until":
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("read: %s requires a value", arg)
+			}
+			if err := opts.setBound(arg, args[i+1], now); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--since=") || strings.HasPrefix(arg, "--until="):
+			flag, value, _ := strings.Cut(arg, "=")
+			if err := opts.setBound(flag, value, now); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--time-field":
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				opts.timeField = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--time-field="):
+			opts.timeField = arg[len("--time-field="):]
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return opts, remaining, nil
+}
+
+// addWhere parses and records a --where KEY=VALUE condition.
+func (o *readOptions) addWhere(expr string) error {
+	filter, err := parseFieldFilter(expr)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	o.where = append(o.where, filter)
+	return nil
+}
+
+// setBound parses value for the --since or --until flag.
+func (o *readOptions) setBound(flag, value string, now time.Time) error {
+	bound, err := parseTimeBound(value, now)
+	if err != nil {
+		return fmt.Errorf("read: %s: %w", flag, err)
+	}
+	if flag == "--since" {
+		o.since = bound
+	} else {
+		o.until = bound
+	}
+	return nil
+}
+
+// parseTimeBound accepts an RFC3339 timestamp, a YYYY-MM-DD date (local
+// midnight), or an age such as "2h" or "7d" meaning that long before now.
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if age, err := parseAge(value); err == nil {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339, YYYY-MM-DD, or an age like 2h or 7d", value)
+}
+
+// readEntries streams JSON lines from in to out, one entry at a time, so files
+// of any size can be read. Blank lines are ignored and malformed lines are
+// reported to warn and skipped. Matching lines are written unchanged apart
+// from surrounding whitespace.
+//
+// When a time bound is set, entries are kept only if opts.timeField holds an
+// RFC3339 string or a Unix timestamp in seconds within [since, until];
+// entries without a usable timestamp are dropped.
+func readEntries(in io.Reader, out, warn io.Writer, opts readOptions) error {
+	reader := bufio.NewReader(in)
+	bounded := !opts.since.IsZero() || !opts.until.IsZero()
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("read: %w", readErr)
+		}
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			var decoded interface{}
+			if err := json.Unmarshal(trimmed, &decoded); err != nil {
+				fmt.Fprintf(warn, "read: line %d: skipping invalid JSON: %v\n", lineNo, err)
+			} else if matchesFieldFilters(decoded, opts.where) && (!bounded || entryWithin(decoded, opts)) {
+				if _, err := out.Write(append(trimmed, '\n')); err != nil {
+					return fmt.Errorf("read: %w", err)
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// entryWithin reports whether decoded carries a timestamp in opts.timeField
+// that falls inside the --since/--until bounds.
+func entryWithin(decoded interface{}, opts readOptions) bool {
+	object, ok := decoded.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	var ts time.Time
+	switch value := object[opts.timeField].(type) {
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return false
+		}
+		ts = parsed
+	case float64:
+		ts = time.Unix(0, int64(value*float64(time.Second)))
+	default:
+		return false
+	}
+	if !opts.since.IsZero() && ts.Before(opts.since) {
+		return false
+	}
+	if !opts.until.IsZero() && ts.After(opts.until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFixture is a small JSON lines file as written by `append`, with a blank
+// line and two malformed lines mixed in.
+const readFixture = `{"ts":"2026-10-01T09:00:00Z","level":"info","msg":"boot","count":1}
+{"ts":"2026-10-02T09:00:00Z","level":"error","msg":"disk full","count":3}
+
+{"ts":"2026-10-03T09:00:00Z","level":"info","msg":"recovered","ok":true}
+{"level":"info","msg":"no timestamp"}
+not json at all
+{"ts":1791104400,"level":"error","msg":"unix seconds"}
+{"ts":"2026-10-05T09:00:00Z","level":"info"
+`
+
+func TestReadEntriesFiltersFixture(t *testing.T) {
+	now := time.Date(2026, 10, 6, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "no filters keeps every valid entry",
+			want: []string{"boot", "disk full", "recovered", "no timestamp", "unix seconds"},
+		},
+		{
+			name: "where on a string field",
+			args: []string{"--where", "level=error"},
+			want: []string{"disk full", "unix seconds"},
+		},
+		{
+			name: "where on number and boolean fields",
+			args: []string{"--where=count=3"},
+			want: []string{"disk full"},
+		},
+		{
+			name: "every where condition must hold",
+			args: []string{"--where", "level=info", "--where", "ok=true"},
+			want: []string{"recovered"},
+		},
+		{
+			name: "since drops older and untimed entries",
+			args: []string{"--since", "2026-10-02T00:00:00Z"},
+			want: []string{"disk full", "recovered", "unix seconds"},
+		},
+		{
+			name: "until is inclusive",
+			args: []string{"--until=2026-10-02T09:00:00Z"},
+			want: []string{"boot", "disk full"},
+		},
+		{
+			name: "since as an age with until",
+			args: []string{"--since", "4d", "--until", "2026-10-03T12:00:00Z"},
+			want: []string{"disk full", "recovered"},
+		},
+		{
+			name: "unix timestamps are bounded too",
+			args: []string{"--since", "2026-10-04T00:00:00Z", "--where", "level=error"},
+			want: []string{"unix seconds"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, remaining, err := parseReadFlags(tc.args, now)
+			if err != nil || len(remaining) > 0 {
+				t.Fatalf("parse %q: %v, remaining %q", tc.args, err, remaining)
+			}
+			var out, warn bytes.Buffer
+			if err := readEntries(strings.NewReader(readFixture), &out, &warn, opts); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				_, rest, _ := strings.Cut(line, `"msg":"`)
+				msg, _, _ := strings.Cut(rest, `"`)
+				got = append(got, msg)
+			}
+			if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+
+			// The two malformed lines are skipped with a warning naming them.
+			for _, lineNo := range []string{"line 6:", "line 8:"} {
+				if !strings.Contains(warn.String(), lineNo) {
+					t.Fatalf("expected a warning for %s, got %q", lineNo, warn.String())
+				}
+			}
+			if n := strings.Count(warn.String(), "\n"); n != 2 {
+				t.Fatalf("expected 2 warnings, got %d: %q", n, warn.String())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,11 +42,22 @@ func init() {
 		newStartCmd(),
 		newStopCmd(),
 		newStatusCmd(),
+		newPauseCmd(),
+		newResumeCmd(),
+		newEnableCmd(),
+		newDisableCmd(),
 		newLogCmd(),
+		newLogsCmd(),
 		newTailCmd(),
 		newSummaryCmd(),
 		newClearCmd(),
 		newAppendCmd(),
+		newReadCmd(),
+		newConfigCmd(),
+		newDiffCmd(),
+		newSnapshotCmd(),
+		newCompletionCmd(),
+		newVersionCmd(),
 	)
 }
 
@@ -64,6 +76,25 @@ func execute(args []string) error {
 		outputFormat = format
 	}
 
+	// --instance is an alias for --profile: both namespace the state
+	// directory so independent daemons can run side by side.
+	profile, remaining := extractOption(remaining, "--profile")
+	instance, remaining := extractOption(remaining, "--instance")
+	if instance != "" {
+		if profile != "" && profile != instance {
+			return fmt.Errorf("--profile %q and --instance %q name different instances", profile, instance)
+		}
+		profile = instance
+	}
+	if err := state.SetProfile(profile); err != nil {
+		return err
+	}
+
+	stateDir, remaining := extractOption(remaining, "--state-dir")
+	if err := state.SetStateDir(stateDir); err != nil {
+		return err
+	}
+
 	rootCmd.SetArgs(remaining)
 	cobra.ExecuteInitializers()
 	if err := ensureRenderer(); err != nil {
@@ -154,10 +185,11 @@ func parseConfigFlag(args []string) (string, []string, error) {
 }
 
 // loadWatchTargetsFromConfig retrieves the list of directories to watch from the
-// manifest that was loaded from the configuration file.
+// manifest that was loaded from the configuration file, leaving out disabled
+// ones.
 func loadWatchTargetsFromConfig() []string {
 	if manifestFromConfig != nil {
-		return append([]string(nil), manifestFromConfig.Directories...)
+		return manifestFromConfig.EnabledDirectories()
 	}
 	return nil
 }
@@ -184,6 +216,53 @@ func renderStatus(status daemon.ManagerStatus) error {
 	return outputRenderer.Status(status)
 }
 
+// Exit statuses reported by `status` and `stop` so scripts can branch on the
+// daemon state. Any other failure exits with status 1.
+const (
+	// exitNotRunning is returned by `status` when a manifest is stored but the
+	// daemon is not running, and by `stop` when there was no daemon to stop.
+	exitNotRunning = 3
+	// exitNotConfigured is returned by `status` when no manifest is stored.
+	exitNotConfigured = 4
+)
+
+// exitCodeError makes the process exit with code once the command has already
+// reported its outcome, so main prints nothing further.
+type exitCodeError struct {
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// warn prints a non-fatal problem to stderr.
+func warn(msg string) {
+	fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+}
+
+// extractSwitch removes every occurrence of the boolean flags named by keys
+// from args and reports whether any was present.
+func extractSwitch(args []string, keys ...string) (bool, []string) {
+	found := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		matched := false
+		for _, key := range keys {
+			if arg == key {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			found = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return found, remaining
+}
+
 // extractOption manually parses a key-value option from the arguments list.
 // This is used for options that need to be processed before Cobra's parsing,
 // such as the --output format.
@@ -218,7 +297,8 @@ func extractOption(args []string, keys ...string) (string, []string) {
 			if value == "" {
 				if i+1 >= len(args) {
 					value = ""
-				} else if strings.HasPrefix(args[i+1], "-") {
+				} else if strings.HasPrefix(args[i+1], "-") && args[i+1] != "-" {
+					// A lone "-" is a value, conventionally stdin.
 					value = ""
 				} else {
 					value = args[i+1]
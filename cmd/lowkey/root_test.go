@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+func TestExecuteStateDirOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Cleanup(func() {
+		_ = state.SetStateDir("")
+		_ = state.SetProfile("")
+		cfgFile = ""
+		manifestFromConfig = nil
+	})
+	// A relative --state-dir is resolved against the working directory.
+	work := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(work); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	stateDir := filepath.Join(work, "state", "profiles", "work")
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save(&config.Manifest{Directories: []string{t.TempDir()}}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		// The manifest only exists beneath the override, namespaced by instance.
+		{name: "override and instance", args: []string{"--state-dir", "state", "--instance", "work", "status"}, want: exitNotRunning},
+		{name: "override only", args: []string{"--state-dir", "state", "status"}, want: exitNotConfigured},
+		{name: "instance only", args: []string{"--instance", "work", "status"}, want: exitNotConfigured},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgFile = ""
+			manifestFromConfig = nil
+			var runErr error
+			captureStdout(t, func() { runErr = execute(tc.args) })
+			var exitErr exitCodeError
+			if !errors.As(runErr, &exitErr) {
+				t.Fatalf("expected an exit code error, got %v", runErr)
+			}
+			if exitErr.code != tc.want {
+				t.Fatalf("exit code = %d, want %d", exitErr.code, tc.want)
+			}
+		})
+	}
+}
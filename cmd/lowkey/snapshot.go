@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/state"
+	"lowkey/internal/watcher"
+	"lowkey/pkg/config"
+)
+
+// newSnapshotCmd creates the `snapshot` command, which scans the watched
+// directories once and exports the signature of every tracked file, or
+// compares them with an earlier export.
+func newSnapshotCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot [--save FILE] [--compare FILE] [dir ...]",
+		Short: "Export or compare the signatures of every watched file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			savePath, args := extractOption(args, "--save")
+			comparePath, args := extractOption(args, "--compare")
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "-") {
+					return fmt.Errorf("snapshot: unknown flag %q", arg)
+				}
+			}
+			if savePath != "" && comparePath != "" {
+				return errors.New("snapshot: --save and --compare cannot be combined")
+			}
+
+			// Read the earlier snapshot first, so a bad path fails before
+			// the scan.
+			var older map[string]state.FileSignature
+			if comparePath != "" {
+				if _, err := os.Stat(comparePath); err != nil {
+					return fmt.Errorf("snapshot: %w", err)
+				}
+				cache, err := state.Load(comparePath)
+				if err != nil {
+					return fmt.Errorf("snapshot: %w", err)
+				}
+				older = cache.Snapshot()
+			}
+
+			files, err := scanSnapshot(args)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case comparePath != "":
+				diff := state.CompareSnapshots(older, files)
+				if outputFormat == "json" {
+					encoder := json.NewEncoder(os.Stdout)
+					encoder.SetIndent("", "  ")
+					return encoder.Encode(diff)
+				}
+				printSnapshotDiff(diff)
+				return nil
+			case savePath != "":
+				if err := state.Save(state.NewCacheFromSnapshot(files), savePath); err != nil {
+					return fmt.Errorf("snapshot: %w", err)
+				}
+				fmt.Fprintf(os.Stderr, "snapshot: saved %d files to %s\n", len(files), savePath)
+				return nil
+			default:
+				return state.WriteSnapshot(os.Stdout, files)
+			}
+		},
+	}
+}
+
+// scanSnapshot scans dirs, or the configured directories when dirs is empty,
+// with the ignore rules and hashing limits `watch` would use, and returns the
+// signature of every file it would track. Unreadable paths are warned about.
+func scanSnapshot(dirs []string) (map[string]state.FileSignature, error) {
+	if len(dirs) == 0 {
+		dirs = loadWatchTargetsFromConfig()
+	}
+	if len(dirs) == 0 {
+		return nil, errors.New("snapshot: provide at least one directory")
+	}
+	cwd, _ := os.Getwd()
+	manifest, err := config.BuildManifestFromArgs(cwd, dirs, warn)
+	if err != nil {
+		return nil, err
+	}
+
+	controllerConfig := watcher.ControllerConfig{
+		Directories: manifest.Directories,
+		IgnoreGlobs: discoverIgnoreFiles(manifest.Directories),
+	}
+	if manifestFromConfig != nil {
+		controllerConfig.IncludeGlobs = manifestFromConfig.Include
+		controllerConfig.SkipHidden = manifestFromConfig.SkipHidden
+		controllerConfig.ScanConcurrency = manifestFromConfig.ScanConcurrency
+		controllerConfig.Signature = state.SignatureConfig{
+			HashThreshold: manifestFromConfig.HashThreshold,
+			HashReadLimit: manifestFromConfig.HashReadLimit,
+		}
+	}
+	controller, err := watcher.NewController(controllerConfig)
+	if err != nil {
+		return nil, err
+	}
+	files, inaccessible, err := controller.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	for _, path := range inaccessible {
+		warn(fmt.Sprintf("skipped %s: permission denied", path))
+	}
+	return files, nil
+}
+
+// printSnapshotDiff prints one line per differing file: `+` added, `-`
+// removed, and `~` changed, then a count.
+func printSnapshotDiff(diff state.SnapshotDiff) {
+	if diff.IsEmpty() {
+		fmt.Println("snapshot: no changes")
+		return
+	}
+	for _, group := range []struct {
+		mark  string
+		paths []string
+	}{{"+", diff.Added}, {"-", diff.Removed}, {"~", diff.Changed}} {
+		for _, path := range group.paths {
+			fmt.Printf("%s %s\n", group.mark, path)
+		}
+	}
+	fmt.Printf("snapshot: %d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+}
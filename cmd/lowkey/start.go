@@ -19,15 +19,32 @@ import (
 // daemon manifest, and starting the daemon process.
 func newStartCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "start [dir ...]",
+		Use:   "start [--metrics ADDR] [--metrics-token TOKEN] [--trace] [--trace-endpoint URL] [--webhook URL] [--webhook-token TOKEN] [--verbose] [dir ...]",
 		Short: "Launch the background daemon",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			metricsAddr, traceEnabled, args := parseStartFlags(args)
+			verbose, args := extractSwitch(args, "--verbose", "-v")
+			flags, args := parseStartFlags(args)
+			webhookToken, args := extractOption(args, "--webhook-token")
+			webhookURL, args := extractOption(args, "--webhook")
 			manifestPath, remaining := extractOption(args, "--manifest", "-m")
 			manifest, err := resolveManifest(manifestPath, remaining)
 			if err != nil {
 				return err
 			}
+			if verbose {
+				// Persisted with the manifest so the daemon enables DEBUG
+				// logging of raw backend events.
+				manifest.LogLevel = config.LogLevelDebug
+			}
+			if webhookURL != "" {
+				manifest.WebhookURL = webhookURL
+			}
+			if webhookToken != "" {
+				manifest.WebhookToken = webhookToken
+			}
+			if err := config.CheckWebhookURL(manifest.WebhookURL); err != nil {
+				return fmt.Errorf("start: %w", err)
+			}
 
 			stateDir, err := state.DefaultStateDir()
 			if err != nil {
@@ -38,9 +55,19 @@ func newStartCmd() *cobra.Command {
 				return err
 			}
 
-			if pid, ok := readPID(stateDir); ok && processAlive(pid) {
+			if pid, ok := runningDaemonPID(stateDir); ok {
 				return fmt.Errorf("start: daemon already running with pid %d", pid)
 			}
+			// The daemon keeps its manifest, PID file, and log in the state
+			// directory, so it cannot run from a read-only one.
+			if err := state.CheckWritable(stateDir); err != nil {
+				return fmt.Errorf("start: %w; pass --state-dir DIR to use a writable location", err)
+			}
+
+			observability, err := config.ResolveObservability(flags, os.Getenv, manifest)
+			if err != nil {
+				return fmt.Errorf("start: %w", err)
+			}
 
 			if err := store.Save(manifest); err != nil {
 				return err
@@ -52,13 +79,9 @@ func newStartCmd() *cobra.Command {
 				fmt.Sprintf("%s=1", daemonEnvKey),
 				fmt.Sprintf("%s=%s", daemonManifestEnv, store.Path()),
 			)
-			if metricsAddr != "" {
-				env = append(env, fmt.Sprintf("%s=%s", daemonMetricsEnv, metricsAddr))
-			}
-			if traceEnabled {
-				env = append(env, fmt.Sprintf("%s=1", daemonTraceEnv))
-			}
-			proc.Env = env
+			// The resolved settings are appended last so they take precedence
+			// over any inherited values when the daemon resolves them again.
+			proc.Env = append(env, observability.Env()...)
 			proc.Stdout = os.Stdout
 			proc.Stderr = os.Stderr
 
@@ -78,28 +101,57 @@ func newStartCmd() *cobra.Command {
 // parseStartFlags processes the command-line arguments for the `start` command,
 // extracting flags related to telemetry, such as the metrics address and trace
 // enablement.
-func parseStartFlags(args []string) (metricsAddr string, traceEnabled bool, remaining []string) {
+func parseStartFlags(args []string) (flags config.ObservabilityFlags, remaining []string) {
 	remaining = make([]string, 0, len(args))
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch {
 		case arg == "--metrics":
 			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				metricsAddr = args[i+1]
+				flags.MetricsAddr = args[i+1]
 				i++
 			}
 		case strings.HasPrefix(arg, "--metrics="):
-			metricsAddr = arg[len("--metrics="):]
+			flags.MetricsAddr = arg[len("--metrics="):]
+		case arg == "--metrics-token":
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				flags.MetricsToken = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--metrics-token="):
+			flags.MetricsToken = arg[len("--metrics-token="):]
 		case arg == "--trace":
-			traceEnabled = true
+			enabled := true
+			flags.TraceEnabled = &enabled
 		case strings.HasPrefix(arg, "--trace="):
 			val := strings.ToLower(arg[len("--trace="):])
-			traceEnabled = val != "false" && val != "0"
+			enabled := val != "false" && val != "0"
+			flags.TraceEnabled = &enabled
+		case arg == "--trace-endpoint":
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				flags.TraceEndpoint = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--trace-endpoint="):
+			flags.TraceEndpoint = arg[len("--trace-endpoint="):]
 		default:
 			remaining = append(remaining, arg)
 		}
 	}
-	return metricsAddr, traceEnabled, remaining
+	return flags, remaining
+}
+
+// loadManifest loads the manifest at path, or reads it from stdin when path is
+// "-", resolving its relative paths against the working directory.
+func loadManifest(path string) (*config.Manifest, error) {
+	if path != "-" {
+		return config.LoadManifest(path)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("determine working directory: %w", err)
+	}
+	return config.ReadManifest(os.Stdin, cwd)
 }
 
 // resolveManifest determines the daemon manifest to use, prioritizing an
@@ -107,7 +159,7 @@ func parseStartFlags(args []string) (metricsAddr string, traceEnabled bool, rema
 // and finally building one from command-line arguments.
 func resolveManifest(manifestPath string, args []string) (*config.Manifest, error) {
 	if manifestPath != "" {
-		return config.LoadManifest(manifestPath)
+		return loadManifest(manifestPath)
 	}
 	if manifestFromConfig != nil {
 		return manifestFromConfig, nil
@@ -119,5 +171,5 @@ func resolveManifest(manifestPath string, args []string) (*config.Manifest, erro
 	if err != nil {
 		return nil, fmt.Errorf("start: determine working directory: %w", err)
 	}
-	return config.BuildManifestFromArgs(cwd, args)
+	return config.BuildManifestFromArgs(cwd, args, warn)
 }
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"lowkey/internal/buildinfo"
 	"lowkey/internal/daemon"
+	"lowkey/internal/reporting"
 	"lowkey/internal/state"
+	"lowkey/pkg/output"
 )
 
 // newStatusCmd creates the `status` command, which displays the current state
@@ -14,9 +23,40 @@ import (
 // watched, and the path to the manifest file.
 func newStatusCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "status",
+		Use:   "status [--oneline] [--tree] [--field NAME]",
 		Short: "Show daemon status",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			oneline, args := extractSwitch(args, "--oneline")
+			tree, args := extractSwitch(args, "--tree")
+			hasField := slices.ContainsFunc(args, func(arg string) bool {
+				return arg == "--field" || strings.HasPrefix(arg, "--field=")
+			})
+			field, args := extractOption(args, "--field")
+			if len(args) > 0 {
+				return fmt.Errorf("status: unexpected argument %q", args[0])
+			}
+			if hasField {
+				if field == "" {
+					return fmt.Errorf("status: --field requires a name (valid fields: %s)", strings.Join(output.StatusFieldNames(), ", "))
+				}
+				if oneline || tree {
+					return errors.New("status: --field cannot be combined with --oneline or --tree")
+				}
+				if !slices.Contains(output.StatusFieldNames(), field) {
+					return fmt.Errorf("status: unknown field %q (valid fields: %s)", field, strings.Join(output.StatusFieldNames(), ", "))
+				}
+			}
+			if oneline {
+				outputFormat = "oneline"
+				outputRenderer = nil
+			}
+			if tree {
+				if err := ensureRenderer(); err != nil {
+					return err
+				}
+				outputRenderer = output.WithDirectoryTree(outputRenderer)
+			}
+
 			stateDir, err := state.DefaultStateDir()
 			if err != nil {
 				return err
@@ -30,24 +70,73 @@ func newStatusCmd() *cobra.Command {
 				return err
 			}
 			if manifest == nil {
-				fmt.Println("status: no manifest stored; daemon is not configured")
-				return nil
+				if field != "" {
+					if err := output.WriteStatusField(os.Stdout, daemon.ManagerStatus{}, field); err != nil {
+						return err
+					}
+				} else if outputFormat == "" || outputFormat == "plain" || outputFormat == "text" {
+					fmt.Println("status: no manifest stored; daemon is not configured")
+				} else if err := renderStatus(daemon.ManagerStatus{}); err != nil {
+					return err
+				}
+				return exitCodeError{code: exitNotConfigured}
 			}
 
-			running := false
-			if pid, ok := readPID(stateDir); ok && processAlive(pid) {
-				running = true
-			}
+			pid, running := runningDaemonPID(stateDir)
 
 			status := daemon.ManagerStatus{
 				Running:      running,
+				PID:          pid,
 				Directories:  append([]string(nil), manifest.Directories...),
 				ManifestPath: store.Path(),
+				Paused:       running && pausedMarkerExists(stateDir),
+				Disabled:     append([]string(nil), manifest.Disabled...),
+			}
+			if record, ok := readPIDRecord(stateDir); ok && running {
+				status.Version, status.Commit = record.Version, record.Commit
+				warnStaleDaemon(record)
+			}
+			// The daemon persists its aggregator snapshot periodically, which
+			// is the only view of its counters available to this process.
+			if snapshot, err := reporting.LoadSnapshot(filepath.Join(stateDir, daemon.SnapshotFilename)); err == nil {
+				status.Summary = reporting.BuildSummary(snapshot, 5*time.Minute)
+				status.PerDirectory = snapshot.PerDirectory
 			}
-			if err := renderStatus(status); err != nil {
+			// The heartbeat file outlives the daemon, so it only describes a
+			// running one.
+			if running {
+				if heartbeat, err := daemon.LoadHeartbeat(filepath.Join(stateDir, daemon.HeartbeatFilename)); err == nil {
+					status.Heartbeat = heartbeat
+				}
+			}
+			if field != "" {
+				err = output.WriteStatusField(os.Stdout, status, field)
+			} else {
+				err = renderStatus(status)
+			}
+			if err != nil {
 				return err
 			}
+			if !running {
+				return exitCodeError{code: exitNotRunning}
+			}
 			return nil
 		},
 	}
 }
+
+// warnStaleDaemon warns when the running daemon was built from a different
+// version or commit than this binary, which happens when lowkey is upgraded
+// without restarting the daemon. PID files from daemons predating version
+// tracking are not reported.
+func warnStaleDaemon(record pidRecord) {
+	if record.Version == "" {
+		return
+	}
+	build := buildinfo.Get()
+	if record.Version == build.Version && record.Commit == build.Commit {
+		return
+	}
+	warn(fmt.Sprintf("daemon is running %s (commit %s) but this binary is %s (commit %s); restart the daemon to pick up the upgrade",
+		record.Version, record.Commit, build.Version, build.Commit))
+}
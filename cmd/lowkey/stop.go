@@ -29,22 +29,37 @@ func newStopCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			pid, ok := readPID(stateDir)
+			record, ok := readPIDRecord(stateDir)
 			if !ok {
 				fmt.Println("stop: daemon is not running")
 				_ = store.Clear()
-				return nil
+				return exitCodeError{code: exitNotRunning}
 			}
+			// Never signal a process we cannot confirm is our daemon: after a
+			// crash or reboot the recorded PID may belong to anything. The
+			// manifest is kept so `start` can bring the same watch back.
+			if !isOurDaemon(record) {
+				if processAlive(record.PID) {
+					fmt.Printf("stop: pid %d is not the lowkey daemon; removing stale pid file\n", record.PID)
+				} else {
+					fmt.Println("stop: daemon is not running; removing stale pid file")
+				}
+				if err := os.Remove(pidFilePath(stateDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				return exitCodeError{code: exitNotRunning}
+			}
+			pid := record.PID
 
 			if err := signalDaemon(pid); err != nil && !errors.Is(err, os.ErrProcessDone) {
 				return err
 			}
 
 			deadline := time.Now().Add(time.Duration(daemonShutdownGrace) * time.Second)
-			for processAlive(pid) && time.Now().Before(deadline) {
+			for isOurDaemon(record) && time.Now().Before(deadline) {
 				time.Sleep(200 * time.Millisecond)
 			}
-			if processAlive(pid) {
+			if isOurDaemon(record) {
 				_ = forceKill(pid)
 			}
 
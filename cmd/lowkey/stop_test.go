@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+func TestStopRemovesStalePIDFileAndKeepsManifest(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "lowkey")
+	t.Setenv("XDG_STATE_HOME", filepath.Dir(stateDir))
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if err := store.Save(&config.Manifest{Directories: []string{t.TempDir()}}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+
+	// A process that has exited leaves its PID behind in the file.
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Skipf("cannot run a child process: %v", err)
+	}
+	if err := os.WriteFile(pidFilePath(stateDir), []byte(strconv.Itoa(exited.ProcessState.Pid())+"\n"), 0o644); err != nil {
+		t.Fatalf("write pid file: %v", err)
+	}
+
+	var exitErr exitCodeError
+	if err := newStopCmd().RunE(nil, nil); !errors.As(err, &exitErr) || exitErr.code != exitNotRunning {
+		t.Fatalf("expected the not running exit code, got %v", err)
+	}
+	if _, err := os.Stat(pidFilePath(stateDir)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the stale pid file to be removed, got %v", err)
+	}
+	manifest, err := store.Load()
+	if err != nil || manifest == nil {
+		t.Fatalf("expected the manifest to be kept, got %v, %v", manifest, err)
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -17,9 +26,44 @@ import (
 // file system activity including most active files and hourly activity.
 func newSummaryCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "summary",
+		Use:   "summary [--since-boot] [--bucket DURATION] [--depth N] [--follow] [--interval DURATION]",
 		Short: "Show change statistics",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceBoot, args := extractSwitch(args, "--since-boot")
+			follow, args := extractSwitch(args, "--follow", "-f")
+			intervalValue, args := extractOption(args, "--interval")
+			interval := 2 * time.Second
+			if intervalValue != "" {
+				if !follow {
+					return errors.New("summary: --interval requires --follow")
+				}
+				d, err := time.ParseDuration(intervalValue)
+				if err != nil || d <= 0 {
+					return fmt.Errorf("summary: invalid --interval %q: must be a positive duration such as 2s or 1m", intervalValue)
+				}
+				interval = d
+			}
+			bucketValue, args := extractOption(args, "--bucket")
+			depthValue, args := extractOption(args, "--depth")
+			depth := 0
+			if depthValue != "" {
+				n, err := strconv.Atoi(depthValue)
+				if err != nil || n <= 0 {
+					return fmt.Errorf("summary: invalid --depth %q: must be a positive integer", depthValue)
+				}
+				depth = n
+			}
+			bucket := logs.DefaultChangeBucket
+			if bucketValue != "" {
+				d, err := time.ParseDuration(bucketValue)
+				if err != nil || d <= 0 {
+					return fmt.Errorf("summary: invalid --bucket %q: must be a positive duration such as 1m or 1h", bucketValue)
+				}
+				bucket = d
+			}
+			if len(args) > 0 {
+				return fmt.Errorf("summary: unexpected argument %q", args[0])
+			}
 			// Get the watched directories from config
 			dirs := loadWatchTargetsFromConfig()
 			if len(dirs) == 0 {
@@ -29,48 +73,180 @@ func newSummaryCmd() *cobra.Command {
 			// Use the first watched directory's .lowlog
 			logDir := filepath.Join(dirs[0], ".lowlog")
 
+			// Get statistics from logs
+			reader := logs.NewReader(logDir)
+			reader.SetSinceBoot(sinceBoot)
+			reader.SetChangeBucket(bucket)
+			reader.SetDirectoryDepth(depth)
+			jsonOutput := outputFormat == "json"
+
+			if follow {
+				signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+				defer stop()
+				view := summaryView{reader: reader, sinceBoot: sinceBoot, bucket: bucket, json: jsonOutput}
+				return followSummary(signalCtx, reader, interval, func(stats *logs.Stats) error {
+					return view.refresh(os.Stdout, stats, interval, time.Now())
+				})
+			}
+
 			// Check if log directory exists
 			if _, err := os.Stat(logDir); os.IsNotExist(err) {
+				if jsonOutput {
+					return json.NewEncoder(os.Stdout).Encode(&logs.Stats{})
+				}
 				fmt.Printf("no logs found at %s\n", logDir)
 				return nil
 			}
 
-			// Get statistics from logs
-			reader := logs.NewReader(logDir)
 			stats, err := reader.GetStats()
 			if err != nil {
 				return err
 			}
-
-			if stats.TotalEvents == 0 {
-				fmt.Println("no logs found")
-				return nil
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(stats)
 			}
+			printSummary(os.Stdout, reader, stats, sinceBoot, bucket)
+			return nil
+		},
+	}
+}
 
-			// Print summary header
-			colors.Println(colors.Blue, "=== File Monitor Summary ===")
-			colors.Printf(colors.Magenta, "Total events: %d\n", stats.TotalEvents)
-			colors.Printf(colors.Green, "  New files:      %d\n", stats.NewCount)
-			colors.Printf(colors.Yellow, "  Modified files: %d\n", stats.ModifiedCount)
-			colors.Printf(colors.Red, "  Deleted files:  %d\n", stats.DeletedCount)
-
-			// Print most active files
-			if len(stats.MostActiveFiles) > 0 {
-				colors.Println(colors.Blue, "\nMost active files:")
-				for _, file := range stats.MostActiveFiles {
-					fmt.Printf("  %d changes: %s\n", file.Count, file.Path)
-				}
-			}
+// followSummary recomputes the statistics every interval and passes them to
+// render, starting immediately, until ctx is canceled.
+func followSummary(ctx context.Context, reader *logs.Reader, interval time.Duration, render func(*logs.Stats) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		stats, err := reader.GetStats()
+		if err != nil {
+			return err
+		}
+		if err := render(stats); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
 
-			// Print activity by hour
-			if len(stats.ActivityByHour) > 0 {
-				colors.Println(colors.Blue, "\nActivity by hour:")
-				for _, hour := range stats.ActivityByHour {
-					fmt.Printf("  %s:00  %d events\n", hour.Hour, hour.Count)
-				}
+// summaryView holds what `summary --follow` needs to redraw the statistics.
+type summaryView struct {
+	reader    *logs.Reader
+	sinceBoot bool
+	bucket    time.Duration
+	json      bool
+}
+
+// refresh writes one update of `summary --follow` to w: a JSON line with
+// stats, or the screen cleared and redrawn under a header naming the interval
+// and the time of the update.
+func (v summaryView) refresh(w io.Writer, stats *logs.Stats, interval time.Duration, now time.Time) error {
+	if v.json {
+		return json.NewEncoder(w).Encode(stats)
+	}
+	// Clear the screen and redraw from the top-left corner.
+	fmt.Fprint(w, "\033[H\033[2J")
+	fmt.Fprintf(w, "Every %s: lowkey summary    %s\n\n", interval, now.Format("2006-01-02 15:04:05"))
+	printSummary(w, v.reader, stats, v.sinceBoot, v.bucket)
+	return nil
+}
+
+// printSummary renders stats as colorized text to w.
+func printSummary(w io.Writer, reader *logs.Reader, stats *logs.Stats, sinceBoot bool, bucket time.Duration) {
+	if stats.TotalEvents == 0 {
+		fmt.Fprintln(w, "no logs found")
+		return
+	}
+
+	// Print summary header
+	colors.Fprintln(w, colors.Blue, "=== File Monitor Summary ===")
+	if sinceBoot {
+		if boot, ok, err := reader.LastBoot(); err == nil && ok {
+			fmt.Fprintf(w, "Since boot at %s\n", boot.Format("2006-01-02 15:04:05"))
+		}
+	}
+	colors.Fprintf(w, colors.Magenta, "%d events across %d files (%d distinct %s)\n",
+		stats.TotalEvents, stats.FileCount, stats.DistinctChanges, bucketLabel(bucket))
+	colors.Fprintf(w, colors.Green, "  New files:      %d\n", stats.NewCount)
+	colors.Fprintf(w, colors.Yellow, "  Modified files: %d\n", stats.ModifiedCount)
+	colors.Fprintf(w, colors.Red, "  Deleted files:  %d\n", stats.DeletedCount)
+
+	// Print most active files
+	if len(stats.MostActiveFiles) > 0 {
+		colors.Fprintln(w, colors.Blue, "\nMost active files:")
+		for _, file := range stats.MostActiveFiles {
+			if file.Distinct < file.Count {
+				fmt.Fprintf(w, "  %d changes (%d distinct): %s\n", file.Count, file.Distinct, file.Path)
+			} else {
+				fmt.Fprintf(w, "  %d changes: %s\n", file.Count, file.Path)
 			}
+		}
+	}
 
-			return nil
-		},
+	// Print the busiest file types
+	if len(stats.ByExtension) > 0 {
+		colors.Fprintln(w, colors.Blue, "\nTop extensions:")
+		for _, ext := range topCounts(stats.ByExtension, 5) {
+			fmt.Fprintf(w, "  %d changes: %s\n", stats.ByExtension[ext], ext)
+		}
+	}
+
+	// Print the busiest directories
+	if len(stats.ByDirectory) > 0 {
+		colors.Fprintln(w, colors.Blue, "\nBusiest directories:")
+		for _, dir := range topCounts(stats.ByDirectory, 5) {
+			fmt.Fprintf(w, "  %d changes: %s\n", stats.ByDirectory[dir], dir)
+		}
+	}
+
+	// Print activity by hour
+	if len(stats.ActivityByHour) > 0 {
+		colors.Fprintln(w, colors.Blue, "\nActivity by hour:")
+		for _, hour := range stats.ActivityByHour {
+			fmt.Fprintf(w, "  %s:00  %d events\n", hour.Hour, hour.Count)
+		}
+	}
+}
+
+// topCounts returns the n keys with the most changes, busiest first and
+// ties in name order.
+func topCounts(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// bucketLabel names the distinct changes counted per bucket, such as
+// "change-minutes" for the default one-minute bucket.
+func bucketLabel(bucket time.Duration) string {
+	switch bucket {
+	case time.Second:
+		return "change-seconds"
+	case time.Minute:
+		return "change-minutes"
+	case time.Hour:
+		return "change-hours"
+	}
+	label := bucket.String()
+	if strings.HasSuffix(label, "m0s") {
+		label = strings.TrimSuffix(label, "0s")
+	}
+	if strings.HasSuffix(label, "h0m") {
+		label = strings.TrimSuffix(label, "0m")
 	}
+	return label + " change windows"
 }
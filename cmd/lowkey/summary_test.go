@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lowkey/internal/logs"
+	"lowkey/pkg/colors"
+)
+
+func TestSummaryViewRefreshRedrawsTextAndEncodesJSON(t *testing.T) {
+	colors.DisableColor()
+	stats := &logs.Stats{TotalEvents: 3, FileCount: 2, NewCount: 2, ModifiedCount: 1}
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	var text bytes.Buffer
+	view := summaryView{reader: logs.NewReader(t.TempDir()), bucket: time.Minute}
+	if err := view.refresh(&text, stats, 2*time.Second, now); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	for _, want := range []string{"\033[H\033[2J", "Every 2s: lowkey summary    2026-10-16 09:30:00\n\n", "3 events across 2 files", "Modified files: 1"} {
+		if !strings.Contains(text.String(), want) {
+			t.Fatalf("expected %q in the redrawn screen, got %q", want, text.String())
+		}
+	}
+
+	var encoded bytes.Buffer
+	view.json = true
+	if err := view.refresh(&encoded, stats, 2*time.Second, now); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	var decoded logs.Stats
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil || decoded.TotalEvents != 3 {
+		t.Fatalf("expected one JSON object with the stats, got %q (%v)", encoded.String(), err)
+	}
+	if strings.Contains(encoded.String(), "\033") {
+		t.Fatalf("expected no screen control codes in JSON output")
+	}
+}
+
+func TestFollowSummaryRecomputesEachInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "2026-10-16.log")
+	if err := os.WriteFile(path, []byte("[2026-10-16 09:00:00] [NEW] a.txt (1 bytes)\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var totals []int
+	err := followSummary(ctx, logs.NewReader(dir), 10*time.Millisecond, func(stats *logs.Stats) error {
+		totals = append(totals, stats.TotalEvents)
+		switch len(totals) {
+		case 1:
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = file.WriteString("[2026-10-16 09:01:00] [MODIFIED] a.txt (+1 bytes)\n")
+			return err
+		case 2:
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if len(totals) != 2 || totals[0] != 1 || totals[1] != 2 {
+		t.Fatalf("expected an update per interval picking up the new change, got %v", totals)
+	}
+}
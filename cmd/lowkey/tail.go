@@ -1,19 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"lowkey/internal/logging"
 	"lowkey/internal/state"
+	"lowkey/pkg/colors"
 	"lowkey/pkg/config"
 )
 
@@ -22,16 +27,25 @@ import (
 // activity as it happens.
 func newTailCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "tail",
+		Use:   "tail [--lines N|-n N] [--timeout DURATION] [--no-color] [--output json]",
 		Short: "Follow daemon logs in real time",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			noColor, args := extractSwitch(args, "--no-color")
+			opts, args, err := parseTailFlags(args)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				return fmt.Errorf("tail: unexpected arguments: %v", args)
+			}
+
 			stateDir, err := state.DefaultStateDir()
 			if err != nil {
 				return err
 			}
 
 			logPath := filepath.Join(stateDir, "lowkey.log")
-			if stored, ok := readPID(stateDir); ok && processAlive(stored) {
+			if _, ok := runningDaemonPID(stateDir); ok {
 				if manifest, err := loadStoredManifest(stateDir); err == nil && manifest != nil && manifest.LogPath != "" {
 					logPath = manifest.LogPath
 				}
@@ -40,8 +54,19 @@ func newTailCmd() *cobra.Command {
 			signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
 
-			fmt.Printf("tailing %s\n", logPath)
-			if err := tailFile(signalCtx, logPath); err != nil && !errors.Is(err, context.Canceled) {
+			if noColor {
+				colors.DisableColor()
+			}
+			// Output is buffered to whole lines so each can be colored or
+			// re-encoded; a trailing partial line is flushed on exit.
+			out := &lineWriter{emit: printColoredDaemonLine}
+			if outputFormat == "json" {
+				out.emit = newJSONLineEmitter(os.Stdout)
+			} else {
+				fmt.Printf("tailing %s\n", logPath)
+			}
+			defer out.Flush()
+			if err := tailFile(signalCtx, logPath, out, opts); err != nil && !errors.Is(err, context.Canceled) {
 				return err
 			}
 			return nil
@@ -49,13 +74,54 @@ func newTailCmd() *cobra.Command {
 	}
 }
 
-// tailFile follows a file, printing new content as it is written. It handles
-// file creation, truncation, and rotation, making it robust for tailing log
-// files. The function continues until the provided context is canceled.
-func tailFile(ctx context.Context, path string) error {
+// tailOptions holds the flags of the `tail` command.
+type tailOptions struct {
+	// lines is the number of existing lines printed before following.
+	lines int
+	// timeout bounds the wait for a missing log file to appear; zero waits
+	// until canceled.
+	timeout time.Duration
+}
+
+// parseTailFlags extracts --lines/-n and --timeout from args.
+func parseTailFlags(args []string) (tailOptions, []string, error) {
+	var opts tailOptions
+	lines, args := extractOption(args, "--lines", "-n")
+	if lines != "" {
+		n, err := strconv.Atoi(lines)
+		if err != nil || n < 0 {
+			return opts, nil, fmt.Errorf("tail: invalid --lines %q: must be a non-negative number", lines)
+		}
+		opts.lines = n
+	}
+	timeout, args := extractOption(args, "--timeout")
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return opts, nil, fmt.Errorf("tail: invalid --timeout %q: must be a positive duration such as 10s", timeout)
+		}
+		opts.timeout = d
+	}
+	return opts, args, nil
+}
+
+// tailFile follows a file, writing new content to out as it is written. It
+// handles file creation, truncation, and rotation, making it robust for
+// tailing log files. Truncation restarts from the beginning of the file, and
+// a rotation is detected by the path resolving to a different file. The last
+// opts.lines lines already in the file are written first. The function
+// continues until the provided context is canceled, or fails when the file
+// does not appear within opts.timeout.
+func tailFile(ctx context.Context, path string, out io.Writer, opts tailOptions) error {
 	var file *os.File
 	var err error
 
+	var deadline <-chan time.Time
+	if opts.timeout > 0 {
+		timer := time.NewTimer(opts.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
 	for {
 		file, err = os.Open(path)
 		if err == nil {
@@ -67,15 +133,24 @@ func tailFile(ctx context.Context, path string) error {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("tail: log file not found: %s did not appear within %s", path, opts.timeout)
 		case <-time.After(500 * time.Millisecond):
 		}
 	}
-	defer file.Close()
+	defer func() { file.Close() }()
 
 	offset, err := file.Seek(0, io.SeekEnd)
 	if err != nil {
 		return err
 	}
+	if opts.lines > 0 {
+		start, err := lastLinesOffset(file, offset, opts.lines)
+		if err != nil {
+			return err
+		}
+		offset = start
+	}
 
 	for {
 		select {
@@ -87,37 +162,175 @@ func tailFile(ctx context.Context, path string) error {
 		info, err := os.Stat(path)
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
-				time.Sleep(500 * time.Millisecond)
+				if err := sleepContext(ctx, 500*time.Millisecond); err != nil {
+					return err
+				}
 				continue
 			}
 			return err
 		}
 
-		if info.Size() < offset {
-			file.Close()
-			file, err = os.Open(path)
+		// A rotation renames the file away and creates a new one at path, so
+		// the open file no longer matches it even if the new file has
+		// already grown past offset. Finish the old file, then switch over.
+		opened, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if !os.SameFile(opened, info) {
+			if _, err := copyRange(file, offset, opened.Size(), out); err != nil {
+				return err
+			}
+			next, err := os.Open(path)
 			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
 				return err
 			}
+			file.Close()
+			file = next
+			offset = 0
+			continue
+		}
+
+		if info.Size() < offset {
 			offset = 0
 			continue
 		}
 
 		if info.Size() == offset {
-			time.Sleep(400 * time.Millisecond)
+			if err := sleepContext(ctx, 400*time.Millisecond); err != nil {
+				return err
+			}
 			continue
 		}
 
-		toRead := info.Size() - offset
-		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		if offset, err = copyRange(file, offset, info.Size(), out); err != nil {
 			return err
 		}
-		buffer := make([]byte, toRead)
-		if _, err := io.ReadFull(file, buffer); err != nil {
-			return err
+	}
+}
+
+// copyRange writes the bytes of file between offset and end to out and
+// returns the offset reached.
+func copyRange(file *os.File, offset, end int64, out io.Writer) (int64, error) {
+	if end <= offset {
+		return offset, nil
+	}
+	buffer := make([]byte, end-offset)
+	n, err := file.ReadAt(buffer, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return offset, err
+	}
+	if _, err := out.Write(buffer[:n]); err != nil {
+		return offset, err
+	}
+	return offset + int64(n), nil
+}
+
+// sleepContext pauses for d, returning early with ctx's error when it is
+// canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// lastLinesOffset returns the offset at which the last n lines of file begin,
+// given its size. It reads backwards in blocks so only the tail of a large log
+// is scanned. A final line without a trailing newline counts as a line.
+func lastLinesOffset(file *os.File, size int64, n int) (int64, error) {
+	const blockSize = 8192
+	buffer := make([]byte, blockSize)
+	end := size
+	newlines := 0
+	for end > 0 {
+		start := end - blockSize
+		if start < 0 {
+			start = 0
+		}
+		chunk := buffer[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			newlines++
+			if newlines == n {
+				return start + int64(i) + 1, nil
+			}
 		}
-		offset = info.Size()
-		fmt.Print(string(buffer))
+		end = start
+	}
+	return 0, nil
+}
+
+// lineWriter buffers written bytes and invokes emit once per complete line,
+// without the trailing newline. Partial lines are held until their newline
+// arrives.
+type lineWriter struct {
+	pending []byte
+	emit    func(line string)
+}
+
+// Write implements io.Writer.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			return len(p), nil
+		}
+		w.emit(string(w.pending[:idx]))
+		w.pending = w.pending[idx+1:]
+	}
+}
+
+// Flush emits any buffered partial line.
+func (w *lineWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.emit(string(w.pending))
+		w.pending = nil
+	}
+}
+
+// newJSONLineEmitter returns a line handler that re-encodes daemon log lines
+// as `{ts, level, msg}` JSON objects. Lines that are already JSON or that do
+// not follow the logger format are passed through unchanged.
+func newJSONLineEmitter(w io.Writer) func(string) {
+	encoder := json.NewEncoder(w)
+	return func(line string) {
+		entry, ok := logging.ParseLine(line)
+		if !ok {
+			fmt.Fprintln(w, line)
+			return
+		}
+		_ = encoder.Encode(entry)
+	}
+}
+
+// printColoredDaemonLine prints a daemon log line colored by its level:
+// errors red, debug messages dimmed, and the rest as is.
+// Colors are dropped when disabled, so it prints plain lines then.
+func printColoredDaemonLine(line string) {
+	entry, ok := logging.ParseLine(line)
+	if !ok {
+		fmt.Println(line)
+		return
+	}
+	switch entry.Level {
+	case "ERROR":
+		fmt.Println(colors.Colorize(line, colors.Red))
+	case "DEBUG":
+		fmt.Println(colors.Colorize(line, colors.Dim))
+	default:
+		fmt.Println(line)
 	}
 }
 
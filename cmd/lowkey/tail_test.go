@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lowkey/pkg/colors"
+)
+
+// syncBuffer is a bytes.Buffer safe for a writer and a reader goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailFileFollowsRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lowkey.log")
+	if err := os.WriteFile(path, []byte("before\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- tailFile(ctx, path, out, tailOptions{}) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q, got %q", want, out.String())
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	appendLine := func(line string) {
+		t.Helper()
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(line + "\n"); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	appendLine("first")
+	waitFor("first\n")
+
+	// Rotate the way logging.Rotator does, with a last line the tail has not
+	// read yet and a new file that is already longer than the old offset.
+	appendLine("last before rotation")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	appendLine(strings.Repeat("x", 64))
+	appendLine("after rotation")
+	waitFor("after rotation\n")
+
+	got := out.String()
+	if strings.Contains(got, "before\n") {
+		t.Fatalf("expected tail to start at the end of the file, got %q", got)
+	}
+	for _, line := range []string{"first", "last before rotation", strings.Repeat("x", 64)} {
+		if !strings.Contains(got, line+"\n") {
+			t.Fatalf("expected %q in output, got %q", line, got)
+		}
+	}
+}
+
+func TestTailFilePrintsTheLastLines(t *testing.T) {
+	var long strings.Builder
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&long, "line %04d\n", i)
+	}
+	tests := []struct {
+		name    string
+		content string
+		lines   int
+		want    string
+	}{
+		{name: "none", content: "a\nb\nc\n", lines: 0, want: ""},
+		{name: "last two", content: "a\nb\nc\n", lines: 2, want: "b\nc\n"},
+		{name: "more than the file holds", content: "a\nb\nc\n", lines: 10, want: "a\nb\nc\n"},
+		{name: "partial last line", content: "a\nb\nc", lines: 2, want: "b\nc"},
+		{name: "across read blocks", content: long.String(), lines: 2500, want: long.String()[500*len("line 0000\n"):]},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lowkey.log")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			out := &syncBuffer{}
+			done := make(chan error, 1)
+			go func() { done <- tailFile(ctx, path, out, tailOptions{lines: tc.lines}) }()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(out.String()) < len(tc.want) && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			// Give a tail that printed too much the chance to show it.
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Fatalf("tail: %v", err)
+			}
+			if got := out.String(); got != tc.want {
+				t.Fatalf("output = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTailFileGivesUpWhenTheLogDoesNotAppear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lowkey.log")
+	start := time.Now()
+	err := tailFile(context.Background(), path, &syncBuffer{}, tailOptions{timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "did not appear within 100ms") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected tail to give up after the timeout, took %s", elapsed)
+	}
+}
+
+func TestParseTailFlags(t *testing.T) {
+	opts, rest, err := parseTailFlags([]string{"-n", "5", "--timeout=2s", "extra"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if opts.lines != 5 || opts.timeout != 2*time.Second || len(rest) != 1 || rest[0] != "extra" {
+		t.Fatalf("got %+v and %q", opts, rest)
+	}
+	for _, args := range [][]string{{"--lines=-1"}, {"--lines", "many"}, {"--timeout", "0s"}, {"--timeout", "soon"}} {
+		if _, _, err := parseTailFlags(args); err == nil {
+			t.Fatalf("expected %q to be rejected", args)
+		}
+	}
+}
+
+func TestTailColorsDaemonLinesByLevel(t *testing.T) {
+	const (
+		errorLine = "2024/05/01 10:00:00 ERROR scan failed"
+		debugLine = "2024/05/01 10:00:01 DEBUG queued 3 events"
+		infoLine  = "2024/05/01 10:00:02 INFO daemon started"
+		plainLine = "not a daemon log line"
+	)
+	input := errorLine + "\n" + debugLine + "\n" + infoLine + "\n" + plainLine
+
+	// emit feeds input through a line writer in uneven chunks, as tailFile
+	// does, and returns what was printed.
+	emit := func(t *testing.T) string {
+		t.Helper()
+		return captureStdout(t, func() {
+			out := &lineWriter{emit: printColoredDaemonLine}
+			for _, chunk := range []string{input[:7], input[7:50], input[50:]} {
+				if _, err := out.Write([]byte(chunk)); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+			}
+			out.Flush()
+		})
+	}
+	t.Cleanup(colors.DisableColor)
+
+	colors.EnableColor()
+	want := colors.Red + errorLine + colors.Reset + "\n" +
+		colors.Dim + debugLine + colors.Reset + "\n" +
+		infoLine + "\n" +
+		plainLine + "\n"
+	if got := emit(t); got != want {
+		t.Fatalf("colored output = %q, want %q", got, want)
+	}
+
+	// --no-color disables colors before the first line is printed.
+	colors.DisableColor()
+	if got := emit(t); got != input+"\n" {
+		t.Fatalf("uncolored output = %q, want %q", got, input+"\n")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lowkey/internal/buildinfo"
+)
+
+// newVersionCmd creates the `version` command, which prints the version, git
+// commit, and build date of this binary. With --output json it prints a single
+// object with the same fields for scripts.
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the lowkey version and build information",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return fmt.Errorf("version: unexpected argument %q", args[0])
+			}
+			info := buildinfo.Get()
+			if outputFormat == "json" {
+				return json.NewEncoder(os.Stdout).Encode(info)
+			}
+			fmt.Printf("lowkey %s\n", info.Version)
+			fmt.Printf("commit: %s\n", info.Commit)
+			fmt.Printf("built: %s\n", info.Date)
+			fmt.Printf("go: %s\n", info.GoVersion)
+			return nil
+		},
+	}
+}
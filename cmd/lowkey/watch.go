@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -14,7 +18,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"lowkey/internal/events"
+	"lowkey/internal/hooks"
+	"lowkey/internal/logging"
 	"lowkey/internal/reporting"
+	"lowkey/internal/state"
 	"lowkey/internal/watcher"
 	"lowkey/pkg/colors"
 	"lowkey/pkg/config"
@@ -25,11 +33,15 @@ import (
 // starting a background daemon.
 func newWatchCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "watch [--log] [dir ...]",
+		Use:   "watch [--log] [--log-path DIR] [--collapse-repeats] [--verbose] [--quiet] [--dry-run] [--detect-binary] [--event-buffer N] [--max-files N] [--no-hidden] [--track-mode] [--track-owner] [--exec CMD] [--exec-batch CMD] [--exec-restart] [--exec-quiet] [--webhook URL] [--webhook-token TOKEN] [--mirror DIR] [--ignore-older-than DUR] [--ignore-newer-than DUR] [--safety-scan off|CRON] [--warm-up DUR] [--only glob] [--exclude glob] [--drop-ignore pattern] [dir ...]",
 		Short: "Run Lowkey in foreground for the supplied directories",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Parse the --log flag from arguments
-			enableLogging, args := parseWatchFlags(args)
+			opts, args, err := parseWatchFlags(args)
+			if err != nil {
+				return err
+			}
+			// --log-path implies --log.
+			enableLogging := opts.log || opts.logPath != ""
 			if len(args) == 0 {
 				args = loadWatchTargetsFromConfig()
 			}
@@ -37,19 +49,56 @@ func newWatchCmd() *cobra.Command {
 				return errors.New("provide at least one directory to watch")
 			}
 			cwd, _ := os.Getwd()
-			manifest, err := config.BuildManifestFromArgs(cwd, args)
+			manifest, err := config.BuildManifestFromArgs(cwd, args, warn)
 			if err != nil {
 				return err
 			}
 
+			var logRoot string
+			if opts.logPath != "" {
+				if logRoot, err = filepath.Abs(opts.logPath); err != nil {
+					return fmt.Errorf("watch: resolve --log-path: %w", err)
+				}
+				for _, dir := range manifest.Directories {
+					if !state.PathWithin(logRoot, dir) {
+						continue
+					}
+					rel, err := filepath.Rel(dir, logRoot)
+					if err != nil || rel == "." {
+						return fmt.Errorf("watch: --log-path %s must not be a watched directory", opts.logPath)
+					}
+					// Keep the watcher from reporting its own log writes.
+					opts.exclude = append(opts.exclude, filepath.ToSlash(rel)+"/**")
+				}
+			}
+
+			discovered := discoverIgnoreFiles(manifest.Directories)
+			for _, pattern := range opts.dropIgnore {
+				if !slices.Contains(discovered, strings.TrimSpace(pattern)) {
+					warn(fmt.Sprintf("--drop-ignore %q matches no ignore pattern", pattern))
+				}
+			}
+			ignorePatterns := applyPatternOverrides(discovered, opts.exclude, opts.dropIgnore)
+			if opts.dryRun {
+				return runDryRun(manifest.Directories, opts, ignorePatterns)
+			}
+
 			signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
 			defer stopSignals()
 
-			changes := make(chan reporting.Change, 256)
+			bufferSize := opts.eventBuffer
+			if bufferSize <= 0 {
+				bufferSize = events.DefaultEventBuffer
+			}
+			changes := make(chan reporting.Change, bufferSize)
 			aggregator := reporting.NewAggregator()
 
 			// Initialize the logger pool for .lowlog directories if enabled
 			loggerPool := watcher.NewWatchLoggerPool(enableLogging)
+			if logRoot != "" {
+				loggerPool.SetLogRoot(logRoot)
+			}
+			loggerPool.SetCollapseRepeats(opts.collapse)
 			if enableLogging {
 				// Add directories to logger pool
 				for _, dir := range manifest.Directories {
@@ -60,6 +109,130 @@ func newWatchCmd() *cobra.Command {
 			}
 			defer loggerPool.Close()
 
+			// --verbose sends watcher diagnostics, including every raw
+			// backend event and why it was or was not recorded, to stderr.
+			var logger *logging.Logger
+			if opts.verbose {
+				logger = logging.NewWriter(os.Stderr)
+				logger.SetDebug(true)
+			}
+
+			execCommand, batchCommand, execRestart := opts.exec, opts.execBatch, opts.execRestart
+			if execCommand == "" && batchCommand == "" && manifestFromConfig != nil {
+				execCommand = manifestFromConfig.ExecOnChange
+				batchCommand = manifestFromConfig.ExecBatch
+				execRestart = execRestart || manifestFromConfig.ExecRestart
+			}
+			var hook *hooks.Exec
+			if execCommand != "" || batchCommand != "" {
+				execLogger := logger
+				if execLogger == nil {
+					execLogger = logging.NewWriter(os.Stderr)
+				}
+				execConfig := hooks.ExecConfig{
+					Command:      execCommand,
+					BatchCommand: batchCommand,
+					Restart:      execRestart,
+					Logger:       execLogger,
+				}
+				// --exec-quiet keeps the command's output off the
+				// terminal; the end of it is logged when a run fails.
+				if !opts.execQuiet {
+					execConfig.Stdout, execConfig.Stderr = os.Stdout, os.Stderr
+				}
+				hook, err = hooks.NewExec(execConfig)
+				if err != nil {
+					return fmt.Errorf("watch: %w", err)
+				}
+				// Deferred before the controller is stopped, so this runs
+				// last and the final burst of changes is not lost.
+				defer hook.Close()
+			}
+
+			webhookURL, webhookToken := opts.webhook, opts.webhookToken
+			if webhookURL == "" && manifestFromConfig != nil {
+				webhookURL = manifestFromConfig.WebhookURL
+			}
+			if webhookToken == "" && manifestFromConfig != nil {
+				webhookToken = manifestFromConfig.WebhookToken
+			}
+			var webhook *hooks.Webhook
+			if webhookURL != "" {
+				webhookLogger := logger
+				if webhookLogger == nil {
+					webhookLogger = logging.NewWriter(os.Stderr)
+				}
+				webhookConfig := hooks.WebhookConfig{
+					URL:    webhookURL,
+					Token:  webhookToken,
+					Logger: webhookLogger,
+				}
+				if manifestFromConfig != nil {
+					webhookConfig.Secret = manifestFromConfig.WebhookSecret
+					webhookConfig.Headers = manifestFromConfig.WebhookHeaders
+					webhookConfig.BatchSize = manifestFromConfig.WebhookBatchSize
+					if webhookConfig.BatchInterval, err = manifestFromConfig.WebhookBatchWait(); err != nil {
+						return fmt.Errorf("watch: %w", err)
+					}
+				}
+				webhook, err = hooks.NewWebhook(webhookConfig)
+				if err != nil {
+					return fmt.Errorf("watch: %w", err)
+				}
+				defer webhook.Close()
+			}
+
+			mirrorDest := opts.mirror
+			if mirrorDest != "" {
+				if mirrorDest, err = filepath.Abs(mirrorDest); err != nil {
+					return fmt.Errorf("watch: resolve --mirror: %w", err)
+				}
+			} else if manifestFromConfig != nil {
+				mirrorDest = manifestFromConfig.Mirror
+			}
+			var mirror *hooks.Mirror
+			if mirrorDest != "" {
+				if err := config.CheckMirror(mirrorDest, manifest.Directories); err != nil {
+					return fmt.Errorf("watch: %w", err)
+				}
+				mirrorLogger := logger
+				if mirrorLogger == nil {
+					mirrorLogger = logging.NewWriter(os.Stderr)
+				}
+				mirror, err = hooks.NewMirror(hooks.MirrorConfig{
+					Dest:   mirrorDest,
+					Roots:  manifest.Directories,
+					Logger: mirrorLogger,
+				})
+				if err != nil {
+					return fmt.Errorf("watch: %w", err)
+				}
+				defer mirror.Close()
+			}
+
+			olderThan, newerThan := opts.olderThan, opts.newerThan
+			if olderThan == 0 && newerThan == 0 && manifestFromConfig != nil {
+				if olderThan, newerThan, err = manifestFromConfig.AgeWindow(); err != nil {
+					return fmt.Errorf("watch: %w", err)
+				}
+			}
+
+			warmUp := opts.warmUp
+			if warmUp == 0 && manifestFromConfig != nil {
+				if warmUp, err = manifestFromConfig.WarmUpPeriod(); err != nil {
+					return fmt.Errorf("watch: %w", err)
+				}
+			}
+
+			safetyScan := opts.safetyScan
+			if safetyScan == "" && manifestFromConfig != nil {
+				safetyScan = manifestFromConfig.SafetyScan
+			}
+			scanOff, scanSchedule, err := config.ParseSafetyScan(safetyScan)
+			if err != nil {
+				return fmt.Errorf("watch: %w", err)
+			}
+
 			onChange := func(change reporting.Change) {
 				select {
 				case <-signalCtx.Done():
@@ -67,12 +240,10 @@ func newWatchCmd() *cobra.Command {
 				default:
 				}
 
-				// Log to .lowlog directory if enabled
-				if enableLogging {
-					if err := loggerPool.LogChange(change); err != nil {
-						// Don't fail on logging errors, just warn
-						fmt.Printf("warning: failed to log change: %v\n", err)
-					}
+				if !watcher.IsMarker(change.Type) {
+					hook.Notify(change)
+					webhook.Notify(change)
+					mirror.Notify(change)
 				}
 
 				select {
@@ -81,15 +252,57 @@ func newWatchCmd() *cobra.Command {
 				}
 			}
 
-			ignorePatterns := discoverIgnoreFiles(manifest.Directories)
-
-			controller, err := watcher.NewController(watcher.ControllerConfig{
+			controllerConfig := watcher.ControllerConfig{
 				Directories:  manifest.Directories,
 				IgnoreGlobs:  ignorePatterns,
-				Aggregator:   aggregator,
-				PollInterval: 20 * time.Second,
-				OnChange:     onChange,
-			})
+				IncludeGlobs: opts.only,
+				IgnoreFiles:  config.IgnoreFiles(nil, manifest.Directories),
+				ReloadIgnore: func() ([]string, error) {
+					return applyPatternOverrides(discoverIgnoreFiles(manifest.Directories), opts.exclude, nil), nil
+				},
+				Aggregator:        aggregator,
+				Logger:            logger,
+				PollInterval:      20 * time.Second,
+				OnChange:          onChange,
+				DetectBinary:      opts.detectBinary,
+				EventBuffer:       bufferSize,
+				MaxTrackedFiles:   opts.maxFiles,
+				SkipHidden:        opts.noHidden,
+				TrackMode:         opts.trackMode || manifestFromConfig != nil && manifestFromConfig.TrackMode,
+				TrackOwner:        opts.trackOwner || manifestFromConfig != nil && manifestFromConfig.TrackOwner,
+				IgnoreOlderThan:   olderThan,
+				IgnoreNewerThan:   newerThan,
+				WarmUp:            warmUp,
+				DisableSafetyScan: scanOff,
+				ScanSchedule:      scanSchedule,
+			}
+			// Log to the .lowlog directories if enabled; logging errors
+			// are only warned about.
+			if enableLogging {
+				controllerConfig.Sinks = []watcher.LogSink{loggerPool}
+				controllerConfig.OnSinkError = func(err error) {
+					fmt.Printf("warning: failed to log change: %v\n", err)
+				}
+			}
+			if manifestFromConfig != nil {
+				controllerConfig.ChurnThreshold = manifestFromConfig.ChurnThreshold
+				controllerConfig.Signature = state.SignatureConfig{
+					HashThreshold: manifestFromConfig.HashThreshold,
+					HashReadLimit: manifestFromConfig.HashReadLimit,
+				}
+			}
+			if !opts.quiet {
+				controllerConfig.OnChurn = func(report watcher.ChurnReport) {
+					warn(fmt.Sprintf("%s changed %d times within a second (most often %s); add %q to .lowkey to ignore it",
+						report.Directory, report.Rate, report.TopPath, report.Suggestion))
+				}
+			}
+			// Report the first scan, which can take minutes on a large
+			// tree, so the command does not look hung.
+			if !opts.quiet && stderrIsTerminal() {
+				controllerConfig.OnScanProgress = printScanProgress
+			}
+			controller, err := watcher.NewController(controllerConfig)
 			if err != nil {
 				return err
 			}
@@ -97,13 +310,25 @@ func newWatchCmd() *cobra.Command {
 			if err := controller.Start(); err != nil {
 				return err
 			}
+			if err := loggerPool.LogBoot(time.Now()); err != nil {
+				fmt.Printf("warning: failed to log startup marker: %v\n", err)
+			}
 			defer controller.Stop()
 
-			fmt.Printf("watching %s\n", strings.Join(manifest.Directories, ", "))
-			if enableLogging {
-				fmt.Println("logging changes to .lowlog directories")
+			jsonOutput := outputFormat == "json"
+			if !jsonOutput && !opts.quiet {
+				fmt.Printf("watching %s\n", strings.Join(manifest.Directories, ", "))
+				if opts.logPath != "" {
+					fmt.Printf("logging changes under %s\n", opts.logPath)
+				} else if enableLogging {
+					fmt.Println("logging changes to .lowlog directories")
+				}
+				if mirrorDest != "" {
+					fmt.Printf("mirroring changes to %s\n", mirrorDest)
+				}
+				fmt.Println("press Ctrl+C to stop")
 			}
-			fmt.Println("press Ctrl+C to stop")
+			encoder := json.NewEncoder(os.Stdout)
 
 			var wg sync.WaitGroup
 			wg.Add(1)
@@ -114,6 +339,22 @@ func newWatchCmd() *cobra.Command {
 					case <-signalCtx.Done():
 						return
 					case change := <-changes:
+						if jsonOutput {
+							_ = encoder.Encode(change)
+							continue
+						}
+						if change.Type == watcher.ChangeLimit {
+							fmt.Fprintf(os.Stderr, "error: tracked file limit reached while scanning %s; new files are not tracked (narrow the watch or raise --max-files)\n", change.Path)
+							continue
+						}
+						if change.Type == watcher.ChangeRootLost {
+							fmt.Fprintf(os.Stderr, "error: watched directory %s no longer exists; waiting for it to reappear\n", change.Path)
+							continue
+						}
+						if change.Type == watcher.ChangeRootRestored {
+							fmt.Fprintf(os.Stderr, "watched directory %s is back; tracking resumed\n", change.Path)
+							continue
+						}
 						// Print with color based on event type
 						eventType := strings.ToUpper(change.Type)
 						switch eventType {
@@ -131,56 +372,382 @@ func newWatchCmd() *cobra.Command {
 			}()
 
 			<-signalCtx.Done()
-			fmt.Println("stopping watcher...")
+			if !jsonOutput && !opts.quiet {
+				fmt.Println("stopping watcher...")
+			}
 			wg.Wait()
 			return nil
 		},
 	}
 }
 
+// printScanProgress writes a progress report of the first scan to stderr.
+func printScanProgress(p watcher.ScanProgress) {
+	if p.Done {
+		fmt.Fprintf(os.Stderr, "initial scan complete: %s files in %s\n", formatCount(p.Files), p.Elapsed.Round(100*time.Millisecond))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "scanned %s files...\n", formatCount(p.Files))
+}
+
+// formatCount renders n with thousands separators, as in "12,340".
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+// stderrIsTerminal reports whether stderr is attached to a terminal.
+func stderrIsTerminal() bool {
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// watchOptions holds the flags accepted by the `watch` command.
+type watchOptions struct {
+	log          bool
+	verbose      bool
+	quiet        bool
+	dryRun       bool
+	detectBinary bool
+	eventBuffer  int
+	maxFiles     int
+	noHidden     bool
+	trackMode    bool
+	trackOwner   bool
+	only         []string
+	exclude      []string
+	dropIgnore   []string
+	exec         string
+	execBatch    string
+	execRestart  bool
+	execQuiet    bool
+	logPath      string
+	collapse     bool
+	webhook      string
+	webhookToken string
+	mirror       string
+	olderThan    time.Duration
+	newerThan    time.Duration
+	safetyScan   string
+	warmUp       time.Duration
+}
+
 // parseWatchFlags processes the command-line arguments for the `watch` command,
-// extracting the --log flag if present.
-func parseWatchFlags(args []string) (enableLogging bool, remaining []string) {
+// extracting its flags and returning the remaining directory arguments.
+func parseWatchFlags(args []string) (opts watchOptions, remaining []string, err error) {
 	remaining = make([]string, 0, len(args))
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch {
 		case arg == "--log":
-			enableLogging = true
+			opts.log = true
 		case strings.HasPrefix(arg, "--log="):
 			val := strings.ToLower(arg[len("--log="):])
-			enableLogging = val != "false" && val != "0"
+			opts.log = val != "false" && val != "0"
+		case arg == "--log-path":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --log-path requires a directory")
+			}
+			opts.logPath = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--log-path="):
+			opts.logPath = arg[len("--log-path="):]
+		case arg == "--verbose" || arg == "-v":
+			opts.verbose = true
+		case arg == "--quiet" || arg == "-q":
+			opts.quiet = true
+		case arg == "--dry-run":
+			opts.dryRun = true
+		case arg == "--detect-binary":
+			opts.detectBinary = true
+		case arg == "--event-buffer":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --event-buffer requires a value")
+			}
+			if opts.eventBuffer, err = parseEventBuffer(args[i+1]); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--event-buffer="):
+			if opts.eventBuffer, err = parseEventBuffer(arg[len("--event-buffer="):]); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--max-files":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --max-files requires a value")
+			}
+			if opts.maxFiles, err = parseMaxFiles(args[i+1]); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--max-files="):
+			if opts.maxFiles, err = parseMaxFiles(arg[len("--max-files="):]); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--no-hidden":
+			opts.noHidden = true
+		case arg == "--collapse-repeats":
+			opts.collapse = true
+		case arg == "--track-mode":
+			opts.trackMode = true
+		case arg == "--track-owner":
+			opts.trackOwner = true
+		case arg == "--exec":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --exec requires a command")
+			}
+			opts.exec = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--exec="):
+			opts.exec = arg[len("--exec="):]
+		case arg == "--exec-batch":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --exec-batch requires a command")
+			}
+			opts.execBatch = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--exec-batch="):
+			opts.execBatch = arg[len("--exec-batch="):]
+		case arg == "--exec-restart":
+			opts.execRestart = true
+		case arg == "--exec-quiet":
+			opts.execQuiet = true
+		case arg == "--webhook":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --webhook requires a URL")
+			}
+			opts.webhook = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--webhook="):
+			opts.webhook = arg[len("--webhook="):]
+		case arg == "--mirror":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --mirror requires a directory")
+			}
+			opts.mirror = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--mirror="):
+			opts.mirror = arg[len("--mirror="):]
+		case arg == "--ignore-older-than" || arg == "--ignore-newer-than":
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("watch: %s requires a duration", arg)
+			}
+			if err := opts.setAge(arg, args[i+1]); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--ignore-older-than=") || strings.HasPrefix(arg, "--ignore-newer-than="):
+			flag, value, _ := strings.Cut(arg, "=")
+			if err := opts.setAge(flag, value); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--safety-scan":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --safety-scan requires off, interval, or a cron expression")
+			}
+			opts.safetyScan = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--safety-scan="):
+			opts.safetyScan = arg[len("--safety-scan="):]
+		case arg == "--warm-up":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --warm-up requires a duration")
+			}
+			if opts.warmUp, err = parseWarmUp(args[i+1]); err != nil {
+				return opts, nil, err
+			}
+			i++
+		case strings.HasPrefix(arg, "--warm-up="):
+			if opts.warmUp, err = parseWarmUp(arg[len("--warm-up="):]); err != nil {
+				return opts, nil, err
+			}
+		case arg == "--webhook-token":
+			if i+1 >= len(args) {
+				return opts, nil, errors.New("watch: --webhook-token requires a token")
+			}
+			opts.webhookToken = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--webhook-token="):
+			opts.webhookToken = arg[len("--webhook-token="):]
+		case arg == "--only":
+			if i+1 < len(args) {
+				opts.only = append(opts.only, args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "--only="):
+			opts.only = append(opts.only, arg[len("--only="):])
+		case arg == "--exclude":
+			if i+1 < len(args) {
+				opts.exclude = append(opts.exclude, args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "--exclude="):
+			opts.exclude = append(opts.exclude, arg[len("--exclude="):])
+		case arg == "--drop-ignore":
+			if i+1 < len(args) {
+				opts.dropIgnore = append(opts.dropIgnore, args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "--drop-ignore="):
+			opts.dropIgnore = append(opts.dropIgnore, arg[len("--drop-ignore="):])
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			return opts, nil, fmt.Errorf("watch: unknown flag %q", arg)
 		default:
 			remaining = append(remaining, arg)
 		}
 	}
-	return enableLogging, remaining
+	if len(opts.dropIgnore) > 0 && !opts.dryRun {
+		return opts, nil, errors.New("watch: --drop-ignore only applies to --dry-run")
+	}
+	return opts, remaining, nil
 }
 
-// discoverIgnoreFiles searches for `.lowkey` ignore files in the specified
-// directories and aggregates their patterns. This allows for per-directory
-// ignore rules in addition to a global ignore file.
-func discoverIgnoreFiles(dirs []string) []string {
-	patterns := make([]string, 0)
-	seen := make(map[string]struct{})
+// parseEventBuffer validates an --event-buffer value, which must be a positive
+// integer.
+func parseEventBuffer(value string) (int, error) {
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("watch: invalid --event-buffer %q: must be a positive integer", value)
+	}
+	return size, nil
+}
 
-	// Always ignore .lowlog directories to prevent recursive logging
-	patterns = append(patterns, ".lowlog")
-	seen[".lowlog"] = struct{}{}
-	for _, dir := range dirs {
-		candidate := filepath.Join(dir, ".lowkey")
-		if _, err := os.Stat(candidate); err != nil {
+// parseMaxFiles validates a --max-files value: a positive limit, or -1 to
+// track files without a limit.
+// setAge sets the age window bound named by flag, --ignore-older-than or
+// --ignore-newer-than, from a Go duration such as "1h".
+func (opts *watchOptions) setAge(flag, value string) error {
+	age, err := time.ParseDuration(value)
+	if err != nil || age <= 0 {
+		return fmt.Errorf("watch: invalid %s %q: must be a positive duration such as 30m or 1h", flag, value)
+	}
+	if flag == "--ignore-older-than" {
+		opts.olderThan = age
+	} else {
+		opts.newerThan = age
+	}
+	return nil
+}
+
+// parseWarmUp validates a --warm-up value, a positive Go duration such as
+// "2s".
+func parseWarmUp(value string) (time.Duration, error) {
+	warmUp, err := time.ParseDuration(value)
+	if err != nil || warmUp <= 0 {
+		return 0, fmt.Errorf("watch: invalid --warm-up %q: must be a positive duration such as 2s", value)
+	}
+	return warmUp, nil
+}
+
+func parseMaxFiles(value string) (int, error) {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit == 0 || limit < -1 {
+		return 0, fmt.Errorf("watch: invalid --max-files %q: must be a positive integer or -1 for no limit", value)
+	}
+	return limit, nil
+}
+
+// applyPatternOverrides layers the --exclude and --drop-ignore flags on top of
+// the discovered ignore patterns: excludes are appended as extra ignore
+// patterns, while each dropped pattern is removed from the set when it equals
+// one of them, so its effect can be compared in a dry run without editing the
+// `.lowkey` file. Dropping a pattern does not un-ignore paths matched by
+// another one.
+func applyPatternOverrides(patterns, exclude, dropped []string) []string {
+	drop := make(map[string]struct{}, len(dropped))
+	for _, pattern := range dropped {
+		drop[strings.TrimSpace(pattern)] = struct{}{}
+	}
+
+	result := make([]string, 0, len(patterns)+len(exclude))
+	seen := make(map[string]struct{}, len(patterns)+len(exclude))
+	for _, pattern := range append(append([]string(nil), patterns...), exclude...) {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
 			continue
 		}
-		loaded, err := config.LoadIgnorePatterns(candidate)
-		if err != nil {
+		if _, ok := drop[pattern]; ok {
 			continue
 		}
-		for _, pattern := range loaded {
-			if _, ok := seen[pattern]; ok {
-				continue
-			}
-			seen[pattern] = struct{}{}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		result = append(result, pattern)
+	}
+	return result
+}
+
+// runDryRun performs a single scan with the include, hidden, and ignore
+// filters and prints how many files would be tracked and ignored, without
+// starting the event loop or writing any logs.
+func runDryRun(dirs []string, opts watchOptions, ignorePatterns []string) error {
+	controller, err := watcher.NewController(watcher.ControllerConfig{
+		Directories:  dirs,
+		IgnoreGlobs:  ignorePatterns,
+		IncludeGlobs: opts.only,
+		SkipHidden:   opts.noHidden,
+	})
+	if err != nil {
+		return err
+	}
+	report, err := controller.DryRun()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("dry run: %s\n", strings.Join(dirs, ", "))
+	fmt.Printf("  tracked files: %d\n", report.Tracked)
+	fmt.Printf("  ignored files: %d\n", report.Ignored)
+	if len(report.Inaccessible) > 0 {
+		fmt.Printf("  inaccessible: %d\n", len(report.Inaccessible))
+		for _, path := range report.Inaccessible {
+			fmt.Printf("    - %s\n", path)
+		}
+	}
+	if len(report.ByPattern) == 0 {
+		return nil
+	}
+
+	patterns := make([]string, 0, len(report.ByPattern))
+	for pattern := range report.ByPattern {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if report.ByPattern[patterns[i]] != report.ByPattern[patterns[j]] {
+			return report.ByPattern[patterns[i]] > report.ByPattern[patterns[j]]
+		}
+		return patterns[i] < patterns[j]
+	})
+	fmt.Println("ignored by pattern:")
+	for _, pattern := range patterns {
+		fmt.Printf("  %6d  %s\n", report.ByPattern[pattern], pattern)
+	}
+	return nil
+}
+
+// discoverIgnoreFiles aggregates the patterns from the `.lowkey` file in each
+// directory and the user-level ignore file, in that order of precedence. Files
+// that cannot be read are reported on stderr and skipped.
+func discoverIgnoreFiles(dirs []string) []string {
+	// Always ignore .lowlog directories to prevent recursive logging
+	patterns := []string{".lowlog"}
+	loaded, err := config.ResolveIgnorePatterns(nil, dirs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	for _, pattern := range loaded {
+		if pattern != ".lowlog" {
 			patterns = append(patterns, pattern)
 		}
 	}
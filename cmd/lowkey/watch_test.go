@@ -0,0 +1,28 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDropIgnoreOnlyAppliesToDryRun(t *testing.T) {
+	if _, _, err := parseWatchFlags([]string{"--include", "build", "."}); err == nil || !strings.Contains(err.Error(), "unknown flag") {
+		t.Fatalf("expected an unknown flag to be rejected rather than watched, got %v", err)
+	}
+	if _, _, err := parseWatchFlags([]string{"--drop-ignore", "build", "."}); err == nil || !strings.Contains(err.Error(), "--dry-run") {
+		t.Fatalf("expected --drop-ignore without --dry-run to fail, got %v", err)
+	}
+	opts, remaining, err := parseWatchFlags([]string{"--dry-run", "--drop-ignore=build", "--exclude", "*.tmp", "."})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"."}) {
+		t.Fatalf("remaining = %q", remaining)
+	}
+
+	got := applyPatternOverrides([]string{"build", "node_modules", "build/keep.txt"}, opts.exclude, opts.dropIgnore)
+	if want := []string{"node_modules", "build/keep.txt", "*.tmp"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("patterns = %q, want %q", got, want)
+	}
+}
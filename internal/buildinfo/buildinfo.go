@@ -0,0 +1,78 @@
+// Package buildinfo exposes the version of the running lowkey binary.
+//
+// Release builds inject the values at link time:
+//
+//	go build -ldflags "-X lowkey/internal/buildinfo.Version=v0.2.0 \
+//	  -X lowkey/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X lowkey/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Builds without ldflags fall back to the VCS metadata the Go toolchain embeds.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags -X at build time. They are variables, not constants, so
+// the linker can overwrite them.
+var (
+	// Version is the release version, "dev" for local builds.
+	Version = "dev"
+	// Commit is the git revision the binary was built from.
+	Commit = ""
+	// Date is the build time, conventionally RFC3339 in UTC.
+	Date = ""
+)
+
+// Info describes a build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary. Commit and Date
+// not injected at link time are filled from the embedded VCS settings when
+// available and are "unknown" otherwise.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		modified := false
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+					if len(info.Commit) > 12 {
+						info.Commit = info.Commit[:12]
+					}
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && Commit == "" && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.Date == "" {
+		info.Date = "unknown"
+	}
+	return info
+}
+
+// String renders the build as a single line, for example
+// "v0.2.0 (commit 1a2b3c4, built 2025-10-03T12:00:00Z)".
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", i.Version, i.Commit, i.Date)
+}
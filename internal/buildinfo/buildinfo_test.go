@@ -0,0 +1,29 @@
+package buildinfo
+
+import "testing"
+
+func TestGetPrefersInjectedValues(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+	Version, Commit, Date = "v1.2.3", "abc1234", "2025-10-03T12:00:00Z"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "abc1234" || info.Date != "2025-10-03T12:00:00Z" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.GoVersion == "" {
+		t.Fatal("expected the Go version to be reported")
+	}
+	if got, want := info.String(), "v1.2.3 (commit abc1234, built 2025-10-03T12:00:00Z)"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestGetFillsUnknownFields(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+	Version, Commit, Date = "dev", "", ""
+
+	info := Get()
+	if info.Commit == "" || info.Date == "" {
+		t.Fatalf("expected commit and date to be filled, got %+v", info)
+	}
+}
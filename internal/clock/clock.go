@@ -0,0 +1,116 @@
+// Package clock abstracts access to the current time so that time-sensitive
+// behavior (log gaps, date rollover, supervisor backoff, rotation timestamps)
+// can be exercised deterministically in tests.
+//
+// Production code uses Real, which defers to the time package. Tests can
+// substitute a Fake and advance it explicitly.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock reports the current time and delivers timer notifications.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is a Clock backed by the system time.
+type Real struct{}
+
+// Now returns time.Now().
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// OrReal returns c, or a Real clock when c is nil. It lets components accept an
+// optional clock without nil checks at every call site.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+	return c
+}
+
+// Fake is a manually driven Clock for tests. Time only moves when Advance or
+// Set is called, at which point any timers that have come due fire. It is safe
+// for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake returns a Fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake's current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once the clock has been
+// advanced by at least d. Non-positive durations fire immediately.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d and fires any timers that are due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(target)
+}
+
+// Set moves the clock to t and fires any timers that are due.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	pending := f.waiters[:0]
+	for _, waiter := range f.waiters {
+		if !waiter.deadline.After(t) {
+			waiter.ch <- t
+			continue
+		}
+		pending = append(pending, waiter)
+	}
+	f.waiters = pending
+}
+
+// Waiters reports how many timers are pending. Tests use it to synchronise
+// with goroutines that are about to block on After.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
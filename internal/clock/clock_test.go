@@ -0,0 +1,32 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAfterFiresOnAdvance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	ch := fake.After(time.Minute)
+	fake.Advance(30 * time.Second)
+	select {
+	case <-ch:
+		t.Fatalf("timer fired early")
+	default:
+	}
+
+	fake.Advance(30 * time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(start.Add(time.Minute)) {
+			t.Fatalf("unexpected fire time: %s", got)
+		}
+	default:
+		t.Fatalf("timer did not fire after advancing past its deadline")
+	}
+	if fake.Waiters() != 0 {
+		t.Fatalf("expected no pending waiters, got %d", fake.Waiters())
+	}
+}
@@ -0,0 +1,174 @@
+package clock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. It matches the minutes named by five
+// space-separated fields, as in crontab(5):
+//
+//	minute (0-59) hour (0-23) day-of-month (1-31) month (1-12) day-of-week (0-7, 0 and 7 are Sunday)
+//
+// Each field is `*`, a value, a range `a-b`, any of those followed by a step
+// `/n`, or a comma-separated list of them. When both day fields are
+// restricted, a day matching either one matches, as in cron. The shorthands
+// @hourly, @daily (or @midnight), @weekly, and @monthly are accepted too.
+type Schedule struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domAny and dowAny record a day field starting with `*`, which does
+	// not restrict the day.
+	domAny bool
+	dowAny bool
+}
+
+// scheduleShorthands maps the accepted @-names to their expressions.
+var scheduleShorthands = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseSchedule parses a cron expression; see Schedule for the syntax.
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if full, ok := scheduleShorthands[spec]; ok {
+		spec = full
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("clock: schedule %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+
+	s := &Schedule{expr: expr}
+	bounds := []struct {
+		name     string
+		min, max int
+		set      *uint64
+	}{
+		{"minute", 0, 59, &s.minute},
+		{"hour", 0, 23, &s.hour},
+		{"day-of-month", 1, 31, &s.dom},
+		{"month", 1, 12, &s.month},
+		{"day-of-week", 0, 7, &s.dow},
+	}
+	for i, field := range fields {
+		set, err := parseScheduleField(field, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("clock: schedule %q: %s: %w", expr, bounds[i].name, err)
+		}
+		*bounds[i].set = set
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseScheduleField returns the values matched by one field as a bit set.
+func parseScheduleField(field string, low, high int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		start, end := low, high
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = scheduleValue(from, low, high); err != nil {
+				return 0, err
+			}
+			if end, err = scheduleValue(to, low, high); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := scheduleValue(rangePart, low, high)
+			if err != nil {
+				return 0, err
+			}
+			start = value
+			if !hasStep {
+				end = value
+			}
+		}
+		for v := start; v <= end; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// scheduleValue parses a single number within [low, high].
+func scheduleValue(raw string, low, high int) (int, error) {
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", raw)
+	}
+	if v < low || v > high {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, low, high)
+	}
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first minute strictly after t that the schedule matches,
+// in t's location. It returns the zero time when nothing matches within five
+// years, as for "0 0 30 2 *".
+func (s *Schedule) Next(t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		switch {
+		case s.month&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case s.hour&(1<<uint(next.Hour())) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case s.minute&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's rule for the two day fields: with both
+// restricted, either may match.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,41 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2025, 1, 1, 10, 30, 15, 0, time.UTC) // a Wednesday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2025, 1, 2, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 15th or any Monday.
+		{"0 12 15 * 1", time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tc := range cases {
+		schedule, err := ParseSchedule(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tc.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: Next = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParseScheduleRejectsMalformedExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "@yearly", "a * * * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
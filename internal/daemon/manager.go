@@ -12,10 +12,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"lowkey/internal/buildinfo"
+	"lowkey/internal/events"
+	"lowkey/internal/hooks"
 	"lowkey/internal/logging"
 	"lowkey/internal/reporting"
 	"lowkey/internal/state"
@@ -24,6 +29,36 @@ import (
 	"lowkey/pkg/telemetry"
 )
 
+// SnapshotFilename is the file in the state directory where the daemon
+// persists its aggregator snapshot for CLI processes to read.
+const SnapshotFilename = "summary.json"
+
+// HeartbeatFilename is the file in the state directory where the daemon
+// persists its supervisor heartbeat, next to the aggregator snapshot.
+const HeartbeatFilename = "heartbeat.json"
+
+// BloomCacheFilename is the file in the state directory where the daemon
+// caches the Bloom filter built from its ignore patterns.
+const BloomCacheFilename = "ignore.bloom"
+
+// stateFilenames are the files the daemon and CLI keep in the state
+// directory. They are ignored one by one when a watched directory overlaps the
+// state directory, since ignoring the whole directory would hide the watched
+// files too.
+var stateFilenames = []string{
+	"daemon.json",
+	"daemon.pid",
+	"daemon.paused",
+	"cache.json",
+	SnapshotFilename,
+	HeartbeatFilename,
+	BloomCacheFilename,
+	"profiles",
+}
+
+// snapshotInterval controls how often the aggregator snapshot is persisted.
+const snapshotInterval = 5 * time.Second
+
 // Manager coordinates the watcher lifecycle, manifest persistence, and logging.
 // It acts as the central orchestrator for the daemon, handling the startup and
 // shutdown of the file system monitoring process. It is safe for concurrent use.
@@ -33,11 +68,42 @@ type Manager struct {
 	controller *watcher.Controller
 	aggregator *reporting.Aggregator
 	logger     *logging.Logger
-	mux        sync.Mutex
-	running    bool
+	// logPath is the file the logger writes to; rotated copies share its
+	// name with a suffix.
+	logPath string
+	mux     sync.Mutex
+	running bool
+	// pauseMux guards paused. It is separate from mux so Pause and Resume
+	// do not wait for an initial scan or a restart holding mux; controller
+	// is only replaced with both held, so either lock is enough to read it.
+	pauseMux sync.Mutex
+	// paused records that change recording was paused, so that every
+	// controller the manager starts in place of the current one is paused
+	// too.
+	paused bool
+	// startedAt is when the manager last started; it is zero until then.
+	startedAt  time.Time
 	metrics    *telemetry.Collector
 	tracer     *telemetry.Tracer
 	supervisor *Supervisor
+	// exec runs the manifest's ExecOnChange and ExecBatch commands while the
+	// manager is running; it is nil when neither is configured.
+	exec atomic.Pointer[hooks.Exec]
+	// webhook posts changes to the manifest's WebhookURL while the manager is
+	// running; it is nil when no URL is configured.
+	webhook atomic.Pointer[hooks.Webhook]
+	// mirror copies changed files to the manifest's Mirror directory while
+	// the manager is running; it is nil when no mirror is configured.
+	mirror atomic.Pointer[hooks.Mirror]
+
+	snapshotCancel context.CancelFunc
+	snapshotDone   chan struct{}
+
+	// ctx is the context passed to StartContext, which bounds every
+	// controller the manager starts.
+	ctx context.Context
+	// newBackend overrides the watcher's event backend; nil uses the default.
+	newBackend func(events.BackendConfig) (events.Backend, error)
 }
 
 // NewManager creates a new Manager for the provided manifest and store.
@@ -62,27 +128,23 @@ func NewManager(store *state.ManifestStore, manifest *config.Manifest) (*Manager
 		return nil, err
 	}
 	logger := logging.New(rotator)
+	logger.SetDebug(manifest.LogLevel == config.LogLevelDebug)
 	aggregator := reporting.NewAggregator()
-	ignorePatterns, err := resolveIgnorePatterns(manifest)
-	if err != nil {
-		return nil, err
-	}
 
 	m := &Manager{
 		store:      store,
 		manifest:   manifest,
 		aggregator: aggregator,
 		logger:     logger,
+		logPath:    filepath.Join(logDir, logName),
+		ctx:        context.Background(),
+	}
+	ignorePatterns, err := m.resolveIgnorePatterns(manifest)
+	if err != nil {
+		return nil, err
 	}
 
-	ctrl, err := watcher.NewController(watcher.ControllerConfig{
-		Directories:  manifest.Directories,
-		IgnoreGlobs:  ignorePatterns,
-		Aggregator:   aggregator,
-		Logger:       logger,
-		PollInterval: 30 * time.Second,
-		OnChange:     m.handleChange,
-	})
+	ctrl, err := watcher.NewController(m.controllerConfig(manifest, ignorePatterns))
 	if err != nil {
 		return nil, err
 	}
@@ -91,45 +153,233 @@ func NewManager(store *state.ManifestStore, manifest *config.Manifest) (*Manager
 	return m, nil
 }
 
-func resolveIgnorePatterns(manifest *config.Manifest) ([]string, error) {
-	if manifest == nil || manifest.IgnoreFile == "" {
+// controllerConfig builds the watcher configuration for manifest, wiring the
+// controller to the manager's aggregator, logger, and change hook. The
+// ignore patterns are reloaded whenever one of their files changes on disk.
+func (m *Manager) controllerConfig(manifest *config.Manifest, ignorePatterns []string) watcher.ControllerConfig {
+	minPoll, maxPoll, err := manifest.ScanIntervalBounds()
+	if err != nil && m.logger != nil {
+		m.logger.Errorf("daemon: %v; keeping the safety-scan interval fixed", err)
+	}
+	scanOff, scanSchedule, err := manifest.SafetyScanSchedule()
+	if err != nil && m.logger != nil {
+		m.logger.Errorf("daemon: %v; using the safety-scan interval", err)
+	}
+	olderThan, newerThan, err := manifest.AgeWindow()
+	if err != nil && m.logger != nil {
+		m.logger.Errorf("daemon: %v; not ignoring files by age", err)
+	}
+	warmUp, err := manifest.WarmUpPeriod()
+	if err != nil && m.logger != nil {
+		m.logger.Errorf("daemon: %v; starting without a warm-up", err)
+	}
+	return watcher.ControllerConfig{
+		Directories:       manifest.EnabledDirectories(),
+		IgnoreGlobs:       ignorePatterns,
+		IncludeGlobs:      manifest.Include,
+		Aggregator:        m.aggregator,
+		Logger:            m.logger,
+		PollInterval:      30 * time.Second,
+		OnChange:          m.handleChange,
+		EventBuffer:       manifest.EventBuffer,
+		MaxTrackedFiles:   manifest.MaxTrackedFiles,
+		ChurnThreshold:    manifest.ChurnThreshold,
+		ScanConcurrency:   manifest.ScanConcurrency,
+		Signature:         state.SignatureConfig{HashThreshold: manifest.HashThreshold, HashReadLimit: manifest.HashReadLimit},
+		TrackMode:         manifest.TrackMode,
+		TrackOwner:        manifest.TrackOwner,
+		SkipHidden:        manifest.SkipHidden,
+		IgnoreFiles:       config.IgnoreFiles(manifest, manifest.EnabledDirectories()),
+		ReloadIgnore:      func() ([]string, error) { return m.resolveIgnorePatterns(manifest) },
+		MinPollInterval:   minPoll,
+		MaxPollInterval:   maxPoll,
+		DisableSafetyScan: scanOff,
+		ScanSchedule:      scanSchedule,
+		IgnoreOlderThan:   olderThan,
+		IgnoreNewerThan:   newerThan,
+		WarmUp:            warmUp,
+		NewBackend:        m.newBackend,
+		BloomCache:        filepath.Join(filepath.Dir(m.store.Path()), BloomCacheFilename),
+	}
+}
+
+// resolveIgnorePatterns merges the layered ignore files for manifest; see
+// config.ResolveIgnorePatterns for the precedence order. The daemon's own log
+// and state files are always ignored, so watching a directory that holds
+// them does not report every log line as a change.
+func (m *Manager) resolveIgnorePatterns(manifest *config.Manifest) ([]string, error) {
+	if manifest == nil {
 		return nil, nil
 	}
-	patterns, err := config.LoadIgnorePatterns(manifest.IgnoreFile)
+	patterns, err := config.ResolveIgnorePatterns(manifest, manifest.EnabledDirectories())
 	if err != nil {
 		return nil, fmt.Errorf("daemon: load ignore patterns: %w", err)
 	}
-	return patterns, nil
+	return append(patterns, m.ownFilePatterns(manifest.EnabledDirectories())...), nil
+}
+
+// ownFilePatterns returns absolute ignore patterns for the active log file,
+// its rotated copies, and the state directory. The state directory is
+// ignored as a whole unless one of dirs is it or lies beneath it, in which
+// case only the files the daemon keeps there are.
+func (m *Manager) ownFilePatterns(dirs []string) []string {
+	var patterns []string
+	if m.logPath != "" {
+		if logPath, err := filepath.Abs(m.logPath); err == nil {
+			patterns = append(patterns, filepath.ToSlash(logPath), filepath.ToSlash(logPath)+".*")
+		}
+	}
+	stateDir, err := filepath.Abs(filepath.Dir(m.store.Path()))
+	if err != nil {
+		return patterns
+	}
+	for _, dir := range dirs {
+		if abs, err := filepath.Abs(dir); err == nil && state.PathWithin(abs, stateDir) {
+			for _, name := range stateFilenames {
+				patterns = append(patterns, filepath.ToSlash(filepath.Join(stateDir, name)))
+			}
+			return patterns
+		}
+	}
+	return append(patterns, filepath.ToSlash(stateDir))
 }
 
 // Start persists the manifest and launches the watcher controller and supervisor.
 // This method is idempotent and will not restart the manager if it is already
-// running. It is the primary entry point for activating the daemon's monitoring
-// functionality.
+// running, except that a watcher that stopped on its own is replaced. It is
+// the primary entry point for activating the daemon's monitoring
+// functionality. It is StartContext with context.Background().
 func (m *Manager) Start() error {
+	return m.StartContext(context.Background())
+}
+
+// StartContext is Start with a context bounding the watchers' lifetime, so a
+// shutdown signal received while the initial scan of a large tree is still
+// running aborts it promptly. The context is kept for watchers started later
+// by reconciliation or the supervisor. Stop must still be called.
+func (m *Manager) StartContext(ctx context.Context) error {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 	if m.running {
-		return nil
+		if m.controller.Running() {
+			return nil
+		}
+		return m.restartController()
 	}
 
 	if err := m.store.Save(m.manifest); err != nil {
 		return fmt.Errorf("daemon: save manifest: %w", err)
 	}
-	if err := m.controller.Start(); err != nil {
+	if err := m.controller.StartContext(ctx); err != nil {
 		return err
 	}
+	m.ctx = ctx
+	m.exec.Store(m.newExec(m.manifest))
+	m.webhook.Store(m.newWebhook(m.manifest))
+	m.mirror.Store(m.newMirror(m.manifest))
 	if m.logger != nil {
-		m.logger.Infof("daemon started with %d directories", len(m.manifest.Directories))
+		m.logger.Infof("daemon %s started with %d directories", buildinfo.Get(), len(m.manifest.EnabledDirectories()))
 	}
 	if m.supervisor != nil {
 		m.supervisor.Start()
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	m.snapshotCancel = cancel
+	m.snapshotDone = make(chan struct{})
+	go m.persistSnapshots(ctx, m.snapshotDone)
+
 	m.running = true
+	m.startedAt = time.Now()
 	return nil
 }
 
+// restartController replaces a controller whose monitor exited on its own
+// with a fresh one started from the old signature cache, so files already
+// known are not reported again. The caller must hold m.mux.
+func (m *Manager) restartController() error {
+	if err := m.ctx.Err(); err != nil {
+		return fmt.Errorf("daemon: not restarting the watcher: %w", err)
+	}
+	ignorePatterns, err := m.resolveIgnorePatterns(m.manifest)
+	if err != nil {
+		return err
+	}
+	cfg := m.controllerConfig(m.manifest, ignorePatterns)
+	cfg.Cache = carryOverCache(m.controller.Cache(), m.manifest.EnabledDirectories(), nil)
+	ctrl, err := watcher.NewController(cfg)
+	if err != nil {
+		return err
+	}
+	m.pauseMux.Lock()
+	if m.paused {
+		ctrl.Pause()
+	}
+	m.pauseMux.Unlock()
+	m.controller.Stop()
+	if err := ctrl.StartContext(m.ctx); err != nil {
+		return err
+	}
+	m.setController(ctrl)
+	if m.logger != nil {
+		m.logger.Info("watcher restarted")
+	}
+	return nil
+}
+
+// setController makes ctrl the current controller, first pausing or resuming
+// it to match a Pause or Resume that arrived while it was being prepared. The
+// caller must hold m.mux.
+func (m *Manager) setController(ctrl *watcher.Controller) {
+	m.pauseMux.Lock()
+	defer m.pauseMux.Unlock()
+	switch {
+	case ctrl == nil:
+	case m.paused && !ctrl.Paused():
+		ctrl.Pause()
+	case !m.paused && ctrl.Paused():
+		ctrl.Resume()
+	}
+	m.controller = ctrl
+}
+
+// SnapshotPath returns where the aggregator snapshot is persisted.
+func (m *Manager) SnapshotPath() string {
+	return filepath.Join(filepath.Dir(m.store.Path()), SnapshotFilename)
+}
+
+// persistSnapshots writes the aggregator snapshot on a timer until ctx is
+// canceled, then writes it one final time so the last counts survive shutdown.
+func (m *Manager) persistSnapshots(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.saveSnapshot()
+			return
+		case <-ticker.C:
+			m.saveSnapshot()
+		}
+	}
+}
+
+func (m *Manager) saveSnapshot() {
+	if m.supervisor != nil {
+		path := filepath.Join(filepath.Dir(m.store.Path()), HeartbeatFilename)
+		if err := SaveHeartbeat(path, m.supervisor.Snapshot()); err != nil && m.logger != nil {
+			m.logger.Errorf("persist heartbeat: %v", err)
+		}
+	}
+	if m.aggregator == nil {
+		return
+	}
+	if err := reporting.SaveSnapshot(m.SnapshotPath(), m.aggregator.Snapshot()); err != nil && m.logger != nil {
+		m.logger.Errorf("persist snapshot: %v", err)
+	}
+}
+
 // Stop halts the watcher and supervisor, marking the manager as idle.
 // This method provides a graceful shutdown of the daemon's monitoring activities.
 func (m *Manager) Stop() {
@@ -139,23 +389,113 @@ func (m *Manager) Stop() {
 		return
 	}
 	m.running = false
+	cancelSnapshots, snapshotDone := m.snapshotCancel, m.snapshotDone
 	m.mux.Unlock()
 
 	m.controller.Stop()
+	m.exec.Swap(nil).Close()
+	m.webhook.Swap(nil).Close()
+	m.mirror.Swap(nil).Close()
 	if m.supervisor != nil {
 		m.supervisor.Stop()
 	}
+	if cancelSnapshots != nil {
+		cancelSnapshots()
+		<-snapshotDone
+	}
 	if m.logger != nil {
 		m.logger.Info("daemon stopped")
 	}
 }
 
+// Pause suspends change recording without stopping the watcher. Events that
+// arrive while paused are dropped; Resume reconciles them with a safety scan.
+func (m *Manager) Pause() {
+	m.pauseMux.Lock()
+	m.paused = true
+	if m.controller != nil {
+		m.controller.Pause()
+	}
+	m.pauseMux.Unlock()
+	if m.logger != nil {
+		m.logger.Info("change recording paused")
+	}
+}
+
+// Resume re-enables change recording and schedules an immediate safety scan so
+// nothing that changed while paused is missed.
+func (m *Manager) Resume() {
+	m.pauseMux.Lock()
+	m.paused = false
+	if m.controller != nil {
+		m.controller.Resume()
+	}
+	m.pauseMux.Unlock()
+	if m.logger != nil {
+		m.logger.Info("change recording resumed")
+	}
+}
+
 // SetTelemetry attaches metrics and tracer instances to the manager, enabling
 // observability features. This allows the manager to report performance
 // metrics and trace information.
 func (m *Manager) SetTelemetry(metrics *telemetry.Collector, tracer *telemetry.Tracer) {
 	m.metrics = metrics
 	m.tracer = tracer
+	if metrics != nil {
+		metrics.RegisterGauge("lowkey_cache_bytes",
+			fmt.Sprintf("Estimated memory held by the signature cache: per entry, path length plus hash length plus %d bytes of fixed overhead.", state.CacheEntryOverhead),
+			func() float64 { return float64(m.cacheBytes()) })
+		metrics.RegisterGauge("lowkey_uptime_seconds",
+			"Seconds since the daemon started watching, or 0 while it is stopped.",
+			func() float64 { return m.uptime().Seconds() })
+		metrics.RegisterGauge("lowkey_seconds_since_last_event",
+			"Seconds since the most recent recorded change, including the startup marker; a value that keeps rising on a busy tree suggests the watcher stopped working.",
+			func() float64 { return m.sinceLastChange().Seconds() })
+	}
+}
+
+// uptime returns how long the manager has been running, or 0 while stopped.
+func (m *Manager) uptime() time.Duration {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if !m.running {
+		return 0
+	}
+	return time.Since(m.startedAt)
+}
+
+// sinceLastChange returns the time elapsed since the aggregator's latest
+// change, or since the latest marker such as the watcher's startup until a
+// change is recorded. It returns 0 when neither has been recorded.
+func (m *Manager) sinceLastChange() time.Duration {
+	if m.aggregator == nil {
+		return 0
+	}
+	snapshot := m.aggregator.Snapshot()
+	last := snapshot.LastChange
+	if last == nil {
+		last = snapshot.LastMarker
+	}
+	if last == nil {
+		return 0
+	}
+	return time.Since(last.Timestamp)
+}
+
+// cacheBytes estimates the memory held by the running controller's signature
+// cache, or returns 0 when there is none.
+func (m *Manager) cacheBytes() int64 {
+	m.mux.Lock()
+	var cache *state.Cache
+	if m.controller != nil {
+		cache = m.controller.Cache()
+	}
+	m.mux.Unlock()
+	if cache == nil {
+		return 0
+	}
+	return cache.EstimatedBytes()
 }
 
 // Status reports the current run state, tracked directories, and other
@@ -167,6 +507,7 @@ func (m *Manager) Status() ManagerStatus {
 
 	dirs := make([]string, len(m.manifest.Directories))
 	copy(dirs, m.manifest.Directories)
+	disabled := append([]string(nil), m.manifest.Disabled...)
 
 	snapshot := reporting.Snapshot{}
 	if m.aggregator != nil {
@@ -178,18 +519,143 @@ func (m *Manager) Status() ManagerStatus {
 		heartbeat = m.supervisor.Snapshot()
 	}
 
+	var trackedFiles int
+	var trackedBytes int64
+	var inaccessible []string
+	var limitDir string
+	var churning []watcher.ChurnReport
+	if m.controller != nil {
+		inaccessible = m.controller.InaccessiblePaths()
+		limitDir, _ = m.controller.TrackingLimit()
+		churning = m.controller.Churning()
+		if cache := m.controller.Cache(); cache != nil {
+			trackedFiles = cache.Len()
+			trackedBytes = cache.TotalSize()
+		}
+	}
+
+	pid := 0
+	if m.running {
+		pid = os.Getpid()
+	}
+	// A watcher that died on its own leaves the manager marked as running;
+	// reporting it as stopped lets the supervisor restart it.
+	running := m.running && m.controller != nil && m.controller.Running()
+	var watcherErr string
+	if m.controller != nil {
+		if err := m.controller.Err(); err != nil {
+			watcherErr = err.Error()
+		}
+	}
+	build := buildinfo.Get()
+
 	return ManagerStatus{
-		Running:      m.running,
-		Directories:  dirs,
-		ManifestPath: m.store.Path(),
-		Summary:      reporting.BuildSummary(snapshot, 5*time.Minute),
-		Heartbeat:    heartbeat,
+		Running:                running,
+		PID:                    pid,
+		Directories:            dirs,
+		Disabled:               disabled,
+		ManifestPath:           m.store.Path(),
+		Summary:                reporting.BuildSummary(snapshot, 5*time.Minute),
+		Heartbeat:              heartbeat,
+		TrackedFiles:           trackedFiles,
+		TrackedBytes:           trackedBytes,
+		Paused:                 m.controller != nil && m.controller.Paused(),
+		Inaccessible:           inaccessible,
+		TrackingLimitDirectory: limitDir,
+		Churning:               churning,
+		WatcherError:           watcherErr,
+		Version:                build.Version,
+		Commit:                 build.Commit,
+		PerDirectory:           snapshot.PerDirectory,
+	}
+}
+
+// newExec returns the hook running manifest's ExecOnChange and ExecBatch
+// commands, or nil when neither is configured or it cannot be created.
+func (m *Manager) newExec(manifest *config.Manifest) *hooks.Exec {
+	if manifest.ExecOnChange == "" && manifest.ExecBatch == "" {
+		return nil
+	}
+	hook, err := hooks.NewExec(hooks.ExecConfig{
+		Command:      manifest.ExecOnChange,
+		BatchCommand: manifest.ExecBatch,
+		Restart:      manifest.ExecRestart,
+		Logger:       m.logger,
+	})
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Errorf("daemon: exec on change: %v", err)
+		}
+		return nil
 	}
+	return hook
+}
+
+// newWebhook returns the hook posting changes to manifest's WebhookURL, or nil
+// when none is configured or it cannot be created. Failed deliveries are
+// counted in the error metric.
+func (m *Manager) newWebhook(manifest *config.Manifest) *hooks.Webhook {
+	if manifest.WebhookURL == "" {
+		return nil
+	}
+	batchInterval, err := manifest.WebhookBatchWait()
+	if err != nil && m.logger != nil {
+		m.logger.Errorf("daemon: %v; using the default webhook batch interval", err)
+	}
+	hook, err := hooks.NewWebhook(hooks.WebhookConfig{
+		URL:           manifest.WebhookURL,
+		Token:         manifest.WebhookToken,
+		Secret:        manifest.WebhookSecret,
+		Headers:       manifest.WebhookHeaders,
+		BatchSize:     manifest.WebhookBatchSize,
+		BatchInterval: batchInterval,
+		Logger:        m.logger,
+		OnFailure: func(error) {
+			if m.metrics != nil {
+				m.metrics.IncError()
+			}
+		},
+	})
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Errorf("daemon: webhook: %v", err)
+		}
+		return nil
+	}
+	return hook
+}
+
+// newMirror returns the hook copying changes to manifest's Mirror directory,
+// or nil when none is configured or it cannot be created.
+func (m *Manager) newMirror(manifest *config.Manifest) *hooks.Mirror {
+	if manifest.Mirror == "" {
+		return nil
+	}
+	hook, err := hooks.NewMirror(hooks.MirrorConfig{
+		Dest:   manifest.Mirror,
+		Roots:  manifest.EnabledDirectories(),
+		Logger: m.logger,
+	})
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Errorf("daemon: mirror: %v", err)
+		}
+		return nil
+	}
+	return hook
 }
 
 func (m *Manager) handleChange(change reporting.Change) {
+	if !watcher.IsMarker(change.Type) {
+		m.exec.Load().Notify(change)
+		m.webhook.Load().Notify(change)
+		m.mirror.Load().Notify(change)
+	}
 	if m.metrics != nil {
 		m.metrics.IncEvent()
+		if change.Type == watcher.ChangeRootLost {
+			m.metrics.IncError()
+		}
 	}
 	if m.tracer != nil && m.tracer.Enabled() {
 		span, _ := m.tracer.StartSpan(context.Background(), "watcher.change")
@@ -203,9 +669,35 @@ func (m *Manager) handleChange(change reporting.Change) {
 // snapshot of the daemon's operational status, including its running state,
 // watched directories, and performance metrics.
 type ManagerStatus struct {
-	Running      bool
+	Running bool
+	// PID is the process id of the running daemon, or zero when it is not
+	// running.
+	PID          int `json:",omitempty"`
 	Directories  []string
 	ManifestPath string
 	Summary      reporting.Summary
 	Heartbeat    Heartbeat
+	TrackedFiles int
+	TrackedBytes int64
+	Paused       bool
+	// Disabled lists the entries of Directories that are not watched.
+	Disabled []string `json:",omitempty"`
+	// Inaccessible lists watched paths skipped because they could not be read.
+	Inaccessible []string `json:",omitempty"`
+	// TrackingLimitDirectory names the watched directory being scanned when
+	// the tracked file limit was reached. It is empty while under the limit.
+	TrackingLimitDirectory string `json:",omitempty"`
+	// Churning lists the directories that changed faster than the churn
+	// threshold within the last minute, busiest first.
+	Churning []watcher.ChurnReport `json:",omitempty"`
+	// WatcherError is the error that stopped the watcher on its own, until
+	// the supervisor restarts it.
+	WatcherError string `json:",omitempty"`
+	// Version and Commit identify the daemon's build, so a daemon left
+	// running across an upgrade can be spotted.
+	Version string `json:",omitempty"`
+	Commit  string `json:",omitempty"`
+	// PerDirectory counts the changes recorded in each directory, keyed by
+	// the directory of the changed path.
+	PerDirectory map[string]int `json:",omitempty"`
 }
@@ -0,0 +1,200 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"lowkey/internal/events"
+	"lowkey/internal/reporting"
+	"lowkey/internal/state"
+	"lowkey/internal/watcher"
+	"lowkey/pkg/config"
+)
+
+func TestUptimeAndLastChangeAgeGauges(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	manager, err := NewManager(store, &config.Manifest{Directories: []string{t.TempDir()}})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if manager.uptime() != 0 || manager.sinceLastChange() != 0 {
+		t.Fatalf("expected both gauges to read 0 before start")
+	}
+
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if uptime := manager.uptime(); uptime < 20*time.Millisecond || uptime > time.Minute {
+		t.Fatalf("expected uptime since start, got %s", uptime)
+	}
+	// Until a change is recorded, the age is that of the startup marker.
+	if age := manager.sinceLastChange(); age <= 0 || age > time.Minute {
+		t.Fatalf("expected the age of the startup marker, got %s", age)
+	}
+
+	manager.aggregator.Record(reporting.Change{Path: "a.txt", Type: "MODIFY", Timestamp: time.Now().Add(-time.Hour)})
+	if age := manager.sinceLastChange(); age < time.Hour {
+		t.Fatalf("expected an hour since the last change, got %s", age)
+	}
+
+	manager.Stop()
+	if uptime := manager.uptime(); uptime != 0 {
+		t.Fatalf("expected uptime 0 once stopped, got %s", uptime)
+	}
+}
+
+func TestManagerIgnoresItsOwnLogAndStateFiles(t *testing.T) {
+	stateDir := t.TempDir()
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	manager, err := NewManager(store, &config.Manifest{Directories: []string{stateDir}})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+	time.Sleep(100 * time.Millisecond)
+	before := manager.aggregator.Snapshot().Count
+
+	for i := 0; i < 10; i++ {
+		manager.logger.Infof("log line %d", i)
+	}
+	notes := filepath.Join(stateDir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snapshot := manager.aggregator.Snapshot()
+		if snapshot.LastChange != nil && snapshot.LastChange.Path == notes {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be reported, last change %+v", notes, snapshot.LastChange)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	manager.logger.Info("another line")
+	time.Sleep(200 * time.Millisecond)
+	snapshot := manager.aggregator.Snapshot()
+	if snapshot.Count != before+1 || snapshot.LastChange.Path != notes {
+		t.Fatalf("expected only %s to be reported, got %d changes, last %+v", notes, snapshot.Count-before, snapshot.LastChange)
+	}
+}
+
+func TestOwnFilePatternsKeepWatchedStateDirVisible(t *testing.T) {
+	stateDir := t.TempDir()
+	store, err := state.NewManifestStore(stateDir)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	m := &Manager{store: store, logPath: filepath.Join(stateDir, "lowkey.log")}
+
+	outside := m.ownFilePatterns([]string{t.TempDir()})
+	if !slices.Contains(outside, filepath.ToSlash(stateDir)) {
+		t.Fatalf("expected the whole state dir to be ignored, got %v", outside)
+	}
+	inside := m.ownFilePatterns([]string{stateDir})
+	if slices.Contains(inside, filepath.ToSlash(stateDir)) {
+		t.Fatalf("expected only the daemon's files to be ignored when the state dir is watched, got %v", inside)
+	}
+	if !slices.Contains(inside, filepath.ToSlash(filepath.Join(stateDir, SnapshotFilename))) {
+		t.Fatalf("expected %s to be ignored, got %v", SnapshotFilename, inside)
+	}
+}
+
+func TestPauseCarriesOverToRestartedController(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	manager, err := NewManager(store, &config.Manifest{Directories: []string{t.TempDir()}})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+
+	manager.Pause()
+	manager.mux.Lock()
+	err = manager.restartController()
+	manager.mux.Unlock()
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if !manager.Status().Paused {
+		t.Fatalf("expected the restarted controller to be paused")
+	}
+
+	manager.Resume()
+	manager.mux.Lock()
+	err = manager.restartController()
+	manager.mux.Unlock()
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if manager.Status().Paused {
+		t.Fatalf("expected the restarted controller to record changes after Resume")
+	}
+}
+
+func TestPauseDoesNotWaitForStartup(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	manifest := &config.Manifest{Directories: []string{t.TempDir()}}
+	manager, err := NewManager(store, manifest)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	scanning := make(chan struct{})
+	release := make(chan struct{})
+	manager.newBackend = func(cfg events.BackendConfig) (events.Backend, error) {
+		close(scanning)
+		<-release
+		return events.NewPollingBackend(20*time.Millisecond, cfg)
+	}
+	if manager.controller, err = watcher.NewController(manager.controllerConfig(manifest, nil)); err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+
+	started := make(chan error, 1)
+	go func() { started <- manager.Start() }()
+	<-scanning
+
+	paused := make(chan struct{})
+	go func() {
+		manager.Pause()
+		close(paused)
+	}()
+	select {
+	case <-paused:
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatalf("Pause waited for the initial scan to finish")
+	}
+
+	close(release)
+	if err := <-started; err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+	if !manager.Status().Paused {
+		t.Fatalf("expected a pause received during startup to hold once started")
+	}
+}
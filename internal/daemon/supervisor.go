@@ -10,8 +10,23 @@ package daemon
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
+
+	"lowkey/internal/clock"
+)
+
+const (
+	// initialBackoff is the delay applied after the first failed probe.
+	initialBackoff = time.Second
+	// maxBackoff caps the exponential restart backoff.
+	maxBackoff = 30 * time.Second
 )
 
 // Heartbeat captures daemon liveness metadata for CLI consumers. It includes
@@ -24,6 +39,29 @@ type Heartbeat struct {
 	Restarts     int       `json:"restarts"`
 	LastError    string    `json:"last_error,omitempty"`
 	BackoffUntil time.Time `json:"backoff_until,omitempty"`
+	// RecentErrors holds the last maxRecentErrors supervisor errors, oldest
+	// first. Unlike LastError, they are kept across successful probes, so a
+	// watcher that keeps failing and recovering leaves a trail.
+	RecentErrors []HeartbeatError `json:"recent_errors,omitempty"`
+}
+
+// HeartbeatError is a supervisor error and when it was observed.
+type HeartbeatError struct {
+	At    time.Time `json:"at"`
+	Error string    `json:"error"`
+}
+
+// maxRecentErrors caps Heartbeat.RecentErrors.
+const maxRecentErrors = 10
+
+// recordError sets LastError and appends it to RecentErrors, dropping the
+// oldest entries beyond maxRecentErrors.
+func (h *Heartbeat) recordError(at time.Time, message string) {
+	h.LastError = message
+	h.RecentErrors = append(h.RecentErrors, HeartbeatError{At: at, Error: message})
+	if excess := len(h.RecentErrors) - maxRecentErrors; excess > 0 {
+		h.RecentErrors = slices.Delete(h.RecentErrors, 0, excess)
+	}
 }
 
 // Supervisor monitors the daemon manager and restarts it if it becomes
@@ -32,6 +70,7 @@ type Heartbeat struct {
 type Supervisor struct {
 	manager  *Manager
 	interval time.Duration
+	clock    clock.Clock
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -48,13 +87,26 @@ func NewSupervisor(manager *Manager, interval time.Duration) *Supervisor {
 	if interval <= 0 {
 		interval = 5 * time.Second
 	}
+	now := time.Now()
 	return &Supervisor{
 		manager:   manager,
 		interval:  interval,
-		heartbeat: Heartbeat{LastCheck: time.Now(), LastChange: time.Now()},
+		clock:     clock.Real{},
+		heartbeat: Heartbeat{LastCheck: now, LastChange: now},
 	}
 }
 
+// SetClock replaces the clock driving probe intervals, backoff, and heartbeat
+// timestamps. It must be called before Start.
+func (s *Supervisor) SetClock(c clock.Clock) {
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.clock = clock.OrReal(c)
+}
+
 // Start launches the supervision loop in a new goroutine. The call is
 // idempotent; if the supervisor is already running, this method has no effect.
 func (s *Supervisor) Start() {
@@ -105,40 +157,42 @@ func (s *Supervisor) Stop() {
 
 func (s *Supervisor) loop(ctx context.Context) {
 	defer s.wg.Done()
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
 
-	backoff := time.Second
+	backoff := initialBackoff
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-s.clock.After(s.interval):
 			supervisorErr := s.probe()
 			if supervisorErr == nil {
-				backoff = time.Second
+				backoff = initialBackoff
 				continue
 			}
 
-			backoff *= 2
-			if backoff > 30*time.Second {
-				backoff = 30 * time.Second
-			}
-			s.setBackoff(time.Now().Add(backoff))
-			timer := time.NewTimer(backoff)
+			backoff = nextBackoff(backoff)
+			s.setBackoff(s.clock.Now().Add(backoff))
 			select {
 			case <-ctx.Done():
-				timer.Stop()
 				return
-			case <-timer.C:
+			case <-s.clock.After(backoff):
 			}
 		}
 	}
 }
 
+// nextBackoff doubles the current restart backoff, capped at maxBackoff.
+func nextBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > maxBackoff {
+		next = maxBackoff
+	}
+	return next
+}
+
 func (s *Supervisor) probe() error {
 	s.updateHeartbeat(func(h *Heartbeat) {
-		h.LastCheck = time.Now()
+		h.LastCheck = s.clock.Now()
 		h.LastError = ""
 		h.BackoffUntil = time.Time{}
 	})
@@ -148,17 +202,18 @@ func (s *Supervisor) probe() error {
 		s.updateHeartbeat(func(h *Heartbeat) {
 			if !h.Running {
 				h.Running = true
-				h.LastChange = time.Now()
+				h.LastChange = s.clock.Now()
 			}
 		})
 		return nil
 	}
 
-	// Attempt a restart when the manager reports not running.
+	// Attempt a restart when the manager reports not running, which includes
+	// a watcher that stopped on its own.
 	if err := s.manager.Start(); err != nil {
 		s.updateHeartbeat(func(h *Heartbeat) {
 			h.Running = false
-			h.LastError = err.Error()
+			h.recordError(s.clock.Now(), err.Error())
 		})
 		return err
 	}
@@ -166,8 +221,17 @@ func (s *Supervisor) probe() error {
 	s.updateHeartbeat(func(h *Heartbeat) {
 		h.Running = true
 		h.Restarts++
-		h.LastChange = time.Now()
+		h.LastChange = s.clock.Now()
+		h.LastError = status.WatcherError
+		if status.WatcherError != "" {
+			h.recordError(h.LastChange, status.WatcherError)
+		}
 	})
+	if status.WatcherError != "" {
+		// Back off before the next probe so a watcher that keeps failing
+		// is not restarted in a tight loop.
+		return errors.New(status.WatcherError)
+	}
 	return nil
 }
 
@@ -176,7 +240,9 @@ func (s *Supervisor) probe() error {
 func (s *Supervisor) Snapshot() Heartbeat {
 	s.mux.RLock()
 	defer s.mux.RUnlock()
-	return s.heartbeat
+	heartbeat := s.heartbeat
+	heartbeat.RecentErrors = slices.Clone(heartbeat.RecentErrors)
+	return heartbeat
 }
 
 func (s *Supervisor) updateHeartbeat(mutator func(*Heartbeat)) {
@@ -190,3 +256,41 @@ func (s *Supervisor) setBackoff(until time.Time) {
 		h.BackoffUntil = until
 	})
 }
+
+// SaveHeartbeat atomically writes heartbeat as JSON to path, so `status` in
+// another process can show the supervisor's view of the daemon.
+func SaveHeartbeat(path string, heartbeat Heartbeat) error {
+	data, err := json.Marshal(heartbeat)
+	if err != nil {
+		return fmt.Errorf("daemon: encode heartbeat: %w", err)
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "heartbeat-*.json")
+	if err != nil {
+		return fmt.Errorf("daemon: create temp heartbeat: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("daemon: write heartbeat: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("daemon: close temp heartbeat: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), path); err != nil {
+		return fmt.Errorf("daemon: replace heartbeat %q: %w", path, err)
+	}
+	return nil
+}
+
+// LoadHeartbeat reads a heartbeat written by SaveHeartbeat.
+func LoadHeartbeat(path string) (Heartbeat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("daemon: read heartbeat %q: %w", path, err)
+	}
+	var heartbeat Heartbeat
+	if err := json.Unmarshal(data, &heartbeat); err != nil {
+		return Heartbeat{}, fmt.Errorf("daemon: decode heartbeat %q: %w", path, err)
+	}
+	return heartbeat, nil
+}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lowkey/internal/events"
+	"lowkey/internal/state"
+	"lowkey/internal/watcher"
+	"lowkey/pkg/config"
+)
+
+func TestNextBackoffDoublesUntilCap(t *testing.T) {
+	backoff := initialBackoff
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}
+	for i, expected := range want {
+		backoff = nextBackoff(backoff)
+		if backoff != expected {
+			t.Fatalf("step %d: expected backoff %s, got %s", i, expected, backoff)
+		}
+	}
+}
+
+func TestHeartbeatKeepsRecentErrorsAcrossRecoveries(t *testing.T) {
+	var heartbeat Heartbeat
+	start := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	for i := 0; i < maxRecentErrors+3; i++ {
+		heartbeat.recordError(start.Add(time.Duration(i)*time.Second), fmt.Sprintf("failure %d", i))
+		heartbeat.LastError = "" // a later successful probe
+	}
+	if len(heartbeat.RecentErrors) != maxRecentErrors {
+		t.Fatalf("expected %d recent errors, got %d", maxRecentErrors, len(heartbeat.RecentErrors))
+	}
+	if first := heartbeat.RecentErrors[0]; first.Error != "failure 3" || !first.At.Equal(start.Add(3*time.Second)) {
+		t.Fatalf("expected the oldest errors to be dropped, got %+v", first)
+	}
+
+	path := filepath.Join(t.TempDir(), HeartbeatFilename)
+	if err := SaveHeartbeat(path, heartbeat); err != nil {
+		t.Fatalf("SaveHeartbeat: %v", err)
+	}
+	loaded, err := LoadHeartbeat(path)
+	if err != nil {
+		t.Fatalf("LoadHeartbeat: %v", err)
+	}
+	if len(loaded.RecentErrors) != maxRecentErrors || loaded.RecentErrors[maxRecentErrors-1].Error != "failure 12" {
+		t.Fatalf("unexpected loaded heartbeat %+v", loaded)
+	}
+}
+
+// failingBackend is an event backend that cannot watch anything.
+type failingBackend struct {
+	events chan events.Event
+	errors chan error
+}
+
+func (b *failingBackend) Events() <-chan events.Event { return b.events }
+func (b *failingBackend) Errors() <-chan error        { return b.errors }
+func (b *failingBackend) Add(path string) error {
+	return errors.New("add " + path + ": device not ready")
+}
+func (b *failingBackend) Remove(string) error    { return nil }
+func (b *failingBackend) WatchedPaths() []string { return nil }
+func (b *failingBackend) Close() error           { return nil }
+
+func TestSupervisorRestartsWatcherThatFailed(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	dir := t.TempDir()
+	manifest := &config.Manifest{Directories: []string{dir}}
+	manager, err := NewManager(store, manifest)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	manager.newBackend = func(events.BackendConfig) (events.Backend, error) {
+		return &failingBackend{events: make(chan events.Event), errors: make(chan error)}, nil
+	}
+	if manager.controller, err = watcher.NewController(manager.controllerConfig(manifest, nil)); err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.Status().Running && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := manager.Status()
+	if status.Running {
+		t.Fatalf("expected status to report the failed watcher as not running")
+	}
+	if !strings.Contains(status.WatcherError, "device not ready") {
+		t.Fatalf("expected the watcher error in status, got %q", status.WatcherError)
+	}
+
+	if err := manager.supervisor.probe(); err == nil {
+		t.Fatalf("expected the probe to report the watcher error so it backs off")
+	}
+	heartbeat := manager.supervisor.Snapshot()
+	if heartbeat.Restarts != 1 {
+		t.Fatalf("expected one restart, got %d", heartbeat.Restarts)
+	}
+	if !strings.Contains(heartbeat.LastError, "device not ready") {
+		t.Fatalf("expected the watcher error in the heartbeat, got %q", heartbeat.LastError)
+	}
+	if len(heartbeat.RecentErrors) != 1 || heartbeat.RecentErrors[0].Error != heartbeat.LastError {
+		t.Fatalf("expected the watcher error in the recent errors, got %+v", heartbeat.RecentErrors)
+	}
+}
@@ -9,10 +9,13 @@
 package daemon
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
-	"time"
 
+	"lowkey/internal/state"
 	"lowkey/internal/watcher"
 	"lowkey/pkg/config"
 )
@@ -23,30 +26,69 @@ import (
 type ManifestDiff struct {
 	Added   []string `json:"added"`
 	Removed []string `json:"removed"`
+	// Renamed lists removed directories whose contents now live under an
+	// added one. Such pairs appear here instead of in Added and Removed.
+	Renamed []DirRename `json:"renamed,omitempty"`
+	// LogPath, IgnoreFile, and Mirror are set when that setting differs.
+	LogPath    *SettingChange `json:"log_path,omitempty"`
+	IgnoreFile *SettingChange `json:"ignore_file,omitempty"`
+	Mirror     *SettingChange `json:"mirror,omitempty"`
+}
+
+// SettingChange records a manifest setting changing value. An empty value
+// means the setting is unset.
+type SettingChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DirRename records a watched directory moved from one path to another.
+type DirRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
 // IsEmpty reports whether the diff contains any changes. This is a convenient
 // way to check if a reconciliation resulted in any modifications.
 func (d ManifestDiff) IsEmpty() bool {
-	return len(d.Added) == 0 && len(d.Removed) == 0
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0 &&
+		d.LogPath == nil && d.IgnoreFile == nil && d.Mirror == nil
 }
 
 // DiffManifests computes the delta between the current and desired manifests.
 // It identifies which directories have been added or removed, returning a
-// ManifestDiff that represents these changes.
+// ManifestDiff that represents these changes. Only enabled directories are
+// compared, so disabling a directory removes it and enabling it adds it. The
+// log path, ignore file, and mirror directory are compared too.
 func DiffManifests(current, desired *config.Manifest) ManifestDiff {
 	diff := ManifestDiff{}
+	var from, to config.Manifest
+	if current != nil {
+		from = *current
+	}
+	if desired != nil {
+		to = *desired
+	}
+	if from.LogPath != to.LogPath {
+		diff.LogPath = &SettingChange{From: from.LogPath, To: to.LogPath}
+	}
+	if from.IgnoreFile != to.IgnoreFile {
+		diff.IgnoreFile = &SettingChange{From: from.IgnoreFile, To: to.IgnoreFile}
+	}
+	if from.Mirror != to.Mirror {
+		diff.Mirror = &SettingChange{From: from.Mirror, To: to.Mirror}
+	}
 
 	currentSet := make(map[string]struct{})
 	if current != nil {
-		for _, dir := range current.Directories {
+		for _, dir := range current.EnabledDirectories() {
 			currentSet[dir] = struct{}{}
 		}
 	}
 
 	desiredSet := make(map[string]struct{})
 	if desired != nil {
-		for _, dir := range desired.Directories {
+		for _, dir := range desired.EnabledDirectories() {
 			desiredSet[dir] = struct{}{}
 		}
 	}
@@ -88,31 +130,126 @@ func (m *Manager) ReconcileManifest() (ManifestDiff, error) {
 	if diff.IsEmpty() {
 		return diff, nil
 	}
+	m.mux.Lock()
+	if m.running && m.controller != nil {
+		diff = detectRenames(m.controller.Cache(), diff)
+	}
+	m.mux.Unlock()
 
 	if err := m.applyManifest(desired, diff); err != nil {
+		if m.logger != nil {
+			m.logger.Errorf("daemon: reconcile manifest: %v", err)
+		}
 		return diff, err
 	}
 	return diff, nil
 }
 
+// detectRenames pairs removed and added directories that are the same tree
+// moved to a new path and reports them in Renamed. A pair qualifies when the
+// removed directory no longer exists and every file cached beneath it is
+// present under the added directory with an unchanged size, modification
+// time, and mode. Anything less clean stays an addition plus a removal, and
+// the added directory is scanned from scratch.
+func detectRenames(cache *state.Cache, diff ManifestDiff) ManifestDiff {
+	if cache == nil || len(diff.Added) == 0 || len(diff.Removed) == 0 {
+		return diff
+	}
+
+	claimed := make(map[string]bool)
+	var removed []string
+	for _, from := range diff.Removed {
+		to := ""
+		if _, err := os.Stat(from); errors.Is(err, os.ErrNotExist) {
+			entries := cache.FilesUnder(from)
+			for _, candidate := range diff.Added {
+				if !claimed[candidate] && movedTree(entries, from, candidate) {
+					to = candidate
+					break
+				}
+			}
+		}
+		if to == "" {
+			removed = append(removed, from)
+			continue
+		}
+		claimed[to] = true
+		diff.Renamed = append(diff.Renamed, DirRename{From: from, To: to})
+	}
+
+	var added []string
+	for _, dir := range diff.Added {
+		if !claimed[dir] {
+			added = append(added, dir)
+		}
+	}
+	diff.Added, diff.Removed = added, removed
+	return diff
+}
+
+// movedTree reports whether every cached entry beneath from is found,
+// unchanged, at the same relative path beneath to. An empty set of entries
+// has nothing worth migrating and does not count.
+func movedTree(entries map[string]state.FileSignature, from, to string) bool {
+	if len(entries) == 0 {
+		return false
+	}
+	for path, sig := range entries {
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return false
+		}
+		info, err := os.Lstat(filepath.Join(to, rel))
+		if err != nil || info.IsDir() || !sig.SameStat(info) {
+			return false
+		}
+	}
+	return true
+}
+
+// carryOverCache copies the entries of cache beneath dirs into a new cache, so
+// a replacement controller starts from what is already known about the
+// directories that remain watched instead of reporting their files as new.
+// Entries beneath a renamed directory are re-keyed to its new path.
+func carryOverCache(cache *state.Cache, dirs []string, renames []DirRename) *state.Cache {
+	if cache == nil {
+		return nil
+	}
+	entries := make(map[string]state.FileSignature)
+	for _, dir := range dirs {
+		for path, sig := range cache.FilesUnder(dir) {
+			entries[path] = sig
+		}
+	}
+	for _, rename := range renames {
+		for path, sig := range cache.FilesUnder(rename.From) {
+			if rel, err := filepath.Rel(rename.From, path); err == nil {
+				entries[filepath.Join(rename.To, rel)] = sig
+			}
+		}
+	}
+	carried := state.NewCache()
+	carried.ReplaceAll(entries)
+	return carried
+}
+
 func (m *Manager) applyManifest(manifest *config.Manifest, diff ManifestDiff) error {
 	if manifest == nil {
 		return fmt.Errorf("daemon: manifest cannot be nil")
 	}
 
-	ignorePatterns, err := resolveIgnorePatterns(manifest)
+	ignorePatterns, err := m.resolveIgnorePatterns(manifest)
 	if err != nil {
 		return err
 	}
 
-	ctrl, err := watcher.NewController(watcher.ControllerConfig{
-		Directories:  manifest.Directories,
-		IgnoreGlobs:  ignorePatterns,
-		Aggregator:   m.aggregator,
-		Logger:       m.logger,
-		PollInterval: 30 * time.Second,
-		OnChange:     m.handleChange,
-	})
+	cfg := m.controllerConfig(manifest, ignorePatterns)
+	m.mux.Lock()
+	if m.running && m.controller != nil {
+		cfg.Cache = carryOverCache(m.controller.Cache(), manifest.EnabledDirectories(), diff.Renamed)
+	}
+	m.mux.Unlock()
+	ctrl, err := watcher.NewController(cfg)
 	if err != nil {
 		return err
 	}
@@ -121,27 +258,37 @@ func (m *Manager) applyManifest(manifest *config.Manifest, diff ManifestDiff) er
 	oldController := m.controller
 	oldManifest := m.manifest
 	wasRunning := m.running
-	m.controller = ctrl
+	ctx := m.ctx
+	// A paused daemon stays paused with the new controller.
+	m.setController(ctrl)
 	m.manifest = manifest
 	m.mux.Unlock()
+	if m.logger != nil {
+		m.logger.SetDebug(manifest.LogLevel == config.LogLevelDebug)
+	}
 
 	if oldController != nil {
 		oldController.Stop()
 	}
 
 	if wasRunning {
-		if err := ctrl.Start(); err != nil {
+		if err := ctrl.StartContext(ctx); err != nil {
 			m.mux.Lock()
-			m.controller = oldController
+			m.setController(oldController)
 			m.manifest = oldManifest
 			m.mux.Unlock()
 			if oldController != nil {
-				if restartErr := oldController.Start(); restartErr != nil && m.logger != nil {
+				if restartErr := oldController.StartContext(ctx); restartErr != nil && m.logger != nil {
 					m.logger.Errorf("daemon: failed to restart previous controller after reconciliation error: %v", restartErr)
 				}
 			}
 			return err
 		}
+		// Pending runs of the previous command, and queued deliveries of the
+		// previous webhook, finish before the new ones take over.
+		m.exec.Swap(m.newExec(manifest)).Close()
+		m.webhook.Swap(m.newWebhook(manifest)).Close()
+		m.mirror.Swap(m.newMirror(manifest)).Close()
 	}
 
 	if err := m.store.Save(manifest); err != nil {
@@ -149,7 +296,13 @@ func (m *Manager) applyManifest(manifest *config.Manifest, diff ManifestDiff) er
 	}
 
 	if m.logger != nil {
-		m.logger.Infof("daemon reconciled manifest: added=%d removed=%d", len(diff.Added), len(diff.Removed))
+		m.logger.Infof("daemon reconciled manifest: added=%d removed=%d renamed=%d", len(diff.Added), len(diff.Removed), len(diff.Renamed))
+		for _, rename := range diff.Renamed {
+			m.logger.Infof("daemon migrated cache for renamed directory %s -> %s", rename.From, rename.To)
+		}
+		if diff.LogPath != nil {
+			m.logger.Infof("daemon log path changed to %q; it takes effect when the daemon restarts", diff.LogPath.To)
+		}
 	}
 	return nil
 }
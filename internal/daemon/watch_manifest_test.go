@@ -0,0 +1,181 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+func TestDetectRenamesMigratesMovedTree(t *testing.T) {
+	root := t.TempDir()
+	from := filepath.Join(root, "project")
+	to := filepath.Join(root, "renamed")
+	other := filepath.Join(root, "other")
+	for _, dir := range []string{filepath.Join(from, "src"), other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+
+	cache := state.NewCache()
+	for _, rel := range []string{"a.txt", filepath.Join("src", "b.go")} {
+		path := filepath.Join(from, rel)
+		if err := os.WriteFile(path, []byte(rel), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		sig, err := state.ComputeSignature(path, info)
+		if err != nil {
+			t.Fatalf("signature: %v", err)
+		}
+		cache.Set(path, sig)
+	}
+	if err := os.Rename(from, to); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	diff := detectRenames(cache, ManifestDiff{Added: []string{other, to}, Removed: []string{from}})
+	if len(diff.Renamed) != 1 || diff.Renamed[0] != (DirRename{From: from, To: to}) {
+		t.Fatalf("expected %s -> %s to be detected, got %+v", from, to, diff.Renamed)
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != other || len(diff.Removed) != 0 {
+		t.Fatalf("expected only %s to remain added, got %+v", other, diff)
+	}
+
+	carried := carryOverCache(cache, []string{to, other}, diff.Renamed)
+	if _, ok := carried.Get(filepath.Join(to, "src", "b.go")); !ok || carried.Len() != 2 {
+		t.Fatalf("expected both entries re-keyed under %s, got %v", to, carried.Snapshot())
+	}
+
+	// A tree that changed on the way is not a clean rename.
+	if err := os.WriteFile(filepath.Join(to, "a.txt"), []byte("edited"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	diff = detectRenames(cache, ManifestDiff{Added: []string{to}, Removed: []string{from}})
+	if len(diff.Renamed) != 0 || len(diff.Added) != 1 || len(diff.Removed) != 1 {
+		t.Fatalf("expected a modified tree to fall back to add+remove, got %+v", diff)
+	}
+}
+
+func TestDiffManifestsTreatsDisabledDirectoriesAsRemoved(t *testing.T) {
+	current := &config.Manifest{Directories: []string{"/a", "/b"}}
+	desired := &config.Manifest{Directories: []string{"/a", "/b"}, Disabled: []string{"/b"}}
+	if diff := DiffManifests(current, desired); len(diff.Added) != 0 || len(diff.Removed) != 1 || diff.Removed[0] != "/b" {
+		t.Fatalf("expected disabling /b to remove it, got %+v", diff)
+	}
+	if diff := DiffManifests(desired, current); len(diff.Added) != 1 || diff.Added[0] != "/b" || len(diff.Removed) != 0 {
+		t.Fatalf("expected enabling /b to add it, got %+v", diff)
+	}
+}
+
+func TestDiffManifestsComparesLogPathAndIgnoreFile(t *testing.T) {
+	current := &config.Manifest{Directories: []string{"/a"}, IgnoreFile: "/etc/ignore"}
+	desired := &config.Manifest{Directories: []string{"/a"}, LogPath: "/var/log/lowkey.log", IgnoreFile: "/etc/ignore"}
+	diff := DiffManifests(current, desired)
+	if diff.IsEmpty() || diff.LogPath == nil || *diff.LogPath != (SettingChange{To: "/var/log/lowkey.log"}) {
+		t.Fatalf("expected the log path change to be reported, got %+v", diff)
+	}
+	if diff.IgnoreFile != nil || len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Fatalf("expected nothing else to differ, got %+v", diff)
+	}
+	if diff := DiffManifests(current, &config.Manifest{Directories: []string{"/a"}}); diff.IgnoreFile == nil || diff.IgnoreFile.From != "/etc/ignore" {
+		t.Fatalf("expected the removed ignore file to be reported, got %+v", diff)
+	}
+}
+
+func TestReconcileKeepsAPausedDaemonPaused(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	first, second := t.TempDir(), t.TempDir()
+	manager, err := NewManager(store, &config.Manifest{Directories: []string{first}})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+	manager.Pause()
+
+	if err := store.Save(&config.Manifest{Directories: []string{first, second}}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+	if _, err := manager.ReconcileManifest(); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if !manager.Status().Paused {
+		t.Fatalf("expected the daemon to stay paused after reconciling")
+	}
+}
+
+func TestReconcileMirrorsAddedDirectories(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	first, second, dest := t.TempDir(), t.TempDir(), t.TempDir()
+	manager, err := NewManager(store, &config.Manifest{Directories: []string{first}, Mirror: dest})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+	if _, ok := manager.mirror.Load().Target(filepath.Join(second, "a.txt")); ok {
+		t.Fatalf("expected %s to be outside the mirror before reconciling", second)
+	}
+
+	if err := store.Save(&config.Manifest{Directories: []string{first, second}, Mirror: dest}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+	if _, err := manager.ReconcileManifest(); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	target, ok := manager.mirror.Load().Target(filepath.Join(second, "a.txt"))
+	if !ok {
+		t.Fatalf("expected the mirror to cover %s after reconciling", second)
+	}
+	if want := filepath.Join(dest, filepath.Base(second), "a.txt"); target != want {
+		t.Fatalf("target = %q, want %q", target, want)
+	}
+}
+
+func TestReconcileSwitchesTheMirrorDestination(t *testing.T) {
+	store, err := state.NewManifestStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	dir, oldDest, newDest := t.TempDir(), t.TempDir(), t.TempDir()
+	manager, err := NewManager(store, &config.Manifest{Directories: []string{dir}, Mirror: oldDest})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+
+	if err := store.Save(&config.Manifest{Directories: []string{dir}, Mirror: newDest}); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+	diff, err := manager.ReconcileManifest()
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if diff.Mirror == nil || diff.Mirror.To != newDest {
+		t.Fatalf("expected the diff to report the mirror change, got %+v", diff.Mirror)
+	}
+	target, ok := manager.mirror.Load().Target(filepath.Join(dir, "a.txt"))
+	if want := filepath.Join(newDest, "a.txt"); !ok || target != want {
+		t.Fatalf("target = %q, %v, want %q", target, ok, want)
+	}
+}
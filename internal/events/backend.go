@@ -9,10 +9,13 @@
 package events
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -53,45 +56,123 @@ type Backend interface {
 	// Remove stops watching the given path.
 	Remove(path string) error
 
+	// WatchedPaths returns every directory currently being watched, including
+	// subdirectories discovered beneath the added roots, in sorted order.
+	WatchedPaths() []string
+
 	// Close cleans up the watcher and closes its event and error channels.
 	Close() error
 }
 
+// ContextAdder is implemented by backends whose Add does enough work, such as
+// walking the whole tree, to be worth canceling.
+type ContextAdder interface {
+	// AddContext is Add, aborting with ctx.Err() once ctx is canceled.
+	AddContext(ctx context.Context, path string) error
+}
+
+// AddContext starts watching path with backend, using AddContext when the
+// backend supports cancellation and Add otherwise.
+func AddContext(ctx context.Context, backend Backend, path string) error {
+	if adder, ok := backend.(ContextAdder); ok {
+		return adder.AddContext(ctx, path)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return backend.Add(path)
+}
+
+// DefaultEventBuffer is the capacity of a backend's event channel when none is
+// configured.
+const DefaultEventBuffer = 256
+
+// BackendConfig holds the options shared by backend implementations. The zero
+// value selects the defaults.
+type BackendConfig struct {
+	// EventBuffer is the number of events that may be queued for a slow
+	// consumer before further events are dropped; values <= 0 select
+	// DefaultEventBuffer. Each slot holds one Event (a path, type, and
+	// timestamp, roughly 64 bytes plus the path), so a buffer of 65536
+	// reserves a few megabytes up front.
+	EventBuffer int
+	// SkipHidden excludes files and directories whose name starts with `.`
+	// beneath each watched root; hidden directories are not descended into.
+	SkipHidden bool
+	// Concurrency is the number of files whose signatures are computed in
+	// parallel while a directory is snapshotted; values <= 0 select
+	// runtime.GOMAXPROCS(0).
+	Concurrency int
+	// TrackMode emits EventModify for files whose permission bits changed
+	// even though their content did not.
+	TrackMode bool
+	// TrackOwner does the same for files whose owning user or group changed.
+	TrackOwner bool
+	// Signature selects which files are hashed into their signatures.
+	Signature state.SignatureConfig
+}
+
 // NewBackend returns a new file system event backend. It currently defaults to
 // a polling-based implementation, which is universally compatible but less
 // efficient than native OS APIs.
-func NewBackend() (Backend, error) {
-	return NewPollingBackend(1500 * time.Millisecond)
+func NewBackend(cfg BackendConfig) (Backend, error) {
+	return NewPollingBackend(1500*time.Millisecond, cfg)
 }
 
 // pollingBackend implements the Backend interface using periodic directory
 // scans. While less efficient than native event APIs, it provides consistent
 // behavior across all platforms without additional dependencies.
 type pollingBackend struct {
-	interval time.Duration
-	events   chan Event
-	errors   chan error
+	interval    time.Duration
+	skipHidden  bool
+	concurrency int
+	trackMode   bool
+	trackOwner  bool
+	signature   state.SignatureConfig
+	events      chan Event
+	errors      chan error
 
 	mu      sync.RWMutex
 	watched map[string]map[string]state.FileSignature
-	stop    chan struct{}
-	wg      sync.WaitGroup
+	// subdirs records, per root, every directory found by the latest walk so
+	// newly created subdirectories are known as soon as they are scanned.
+	subdirs map[string]map[string]struct{}
+	// ctx is canceled by Close so a walk in progress stops promptly.
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
 }
 
 // NewPollingBackend constructs a polling-based file system watcher with the
 // specified polling interval. It starts a background goroutine to perform the
 // periodic scans.
-func NewPollingBackend(interval time.Duration) (Backend, error) {
+func NewPollingBackend(interval time.Duration, cfg BackendConfig) (Backend, error) {
 	if interval <= 0 {
 		interval = 2 * time.Second
 	}
+	bufferSize := cfg.EventBuffer
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBuffer
+	}
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
 	backend := &pollingBackend{
-		interval: interval,
-		events:   make(chan Event, 256),
-		errors:   make(chan error, 1),
-		watched:  make(map[string]map[string]state.FileSignature),
-		stop:     make(chan struct{}),
+		interval:    interval,
+		skipHidden:  cfg.SkipHidden,
+		concurrency: concurrency,
+		trackMode:   cfg.TrackMode,
+		trackOwner:  cfg.TrackOwner,
+		signature:   cfg.Signature,
+		events:      make(chan Event, bufferSize),
+		errors:      make(chan error, 1),
+		watched:     make(map[string]map[string]state.FileSignature),
+		subdirs:     make(map[string]map[string]struct{}),
+		stop:        make(chan struct{}),
 	}
+	backend.ctx, backend.cancel = context.WithCancel(context.Background())
 	backend.wg.Add(1)
 	go backend.run()
 	return backend, nil
@@ -112,6 +193,12 @@ func (p *pollingBackend) Errors() <-chan error {
 // Add starts watching the specified directory path. The path must be a
 // directory. The backend will begin polling this directory for changes.
 func (p *pollingBackend) Add(path string) error {
+	return p.AddContext(context.Background(), path)
+}
+
+// AddContext is Add, abandoning the initial walk of path once ctx is canceled
+// or the backend is closed.
+func (p *pollingBackend) AddContext(ctx context.Context, path string) error {
 	clean, err := state.NormalizePath(path)
 	if err != nil {
 		return err
@@ -125,7 +212,10 @@ func (p *pollingBackend) Add(path string) error {
 		return errors.New("events: watch target must be a directory")
 	}
 
-	snapshot, err := p.snapshotDirectory(clean)
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	defer context.AfterFunc(p.ctx, stop)()
+	snapshot, dirs, _, err := p.snapshotDirectory(ctx, clean, nil)
 	if err != nil {
 		return err
 	}
@@ -133,6 +223,7 @@ func (p *pollingBackend) Add(path string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.watched[clean] = snapshot
+	p.subdirs[clean] = dirs
 	return nil
 }
 
@@ -146,12 +237,33 @@ func (p *pollingBackend) Remove(path string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	delete(p.watched, clean)
+	delete(p.subdirs, clean)
 	return nil
 }
 
+// WatchedPaths returns the watched roots and all subdirectories discovered
+// beneath them, sorted and deduplicated.
+func (p *pollingBackend) WatchedPaths() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	seen := make(map[string]struct{})
+	for _, dirs := range p.subdirs {
+		for dir := range dirs {
+			seen[dir] = struct{}{}
+		}
+	}
+	paths := make([]string, 0, len(seen))
+	for dir := range seen {
+		paths = append(paths, dir)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // Close stops the polling loop and cleans up all resources associated with the
 // backend. It ensures that the background goroutine is terminated.
 func (p *pollingBackend) Close() error {
+	p.cancel()
 	close(p.stop)
 	p.wg.Wait()
 	close(p.events)
@@ -178,6 +290,9 @@ func (p *pollingBackend) poll() {
 	dirs := p.directories()
 	for _, dir := range dirs {
 		if err := p.pollDirectory(dir); err != nil {
+			if p.ctx.Err() != nil {
+				return
+			}
 			select {
 			case p.errors <- err:
 			default:
@@ -197,43 +312,124 @@ func (p *pollingBackend) directories() []string {
 }
 
 func (p *pollingBackend) pollDirectory(dir string) error {
-	current, err := p.snapshotDirectory(dir)
+	p.mu.RLock()
+	last := p.watched[dir]
+	p.mu.RUnlock()
+	current, dirs, denied, err := p.snapshotDirectory(p.ctx, dir, last)
 	if err != nil {
 		return err
 	}
 
 	p.mu.Lock()
-	previous := p.watched[dir]
+	previous, ok := p.watched[dir]
+	if !ok {
+		// The root was removed while this walk was in flight.
+		p.mu.Unlock()
+		return nil
+	}
+	// Files under unreadable paths keep their previous signatures so a
+	// permission change is not reported as their deletion.
+	for path, sig := range previous {
+		if _, ok := current[path]; !ok && withinAny(path, denied) {
+			current[path] = sig
+		}
+	}
 	p.watched[dir] = current
+	p.subdirs[dir] = dirs
 	p.mu.Unlock()
 
 	p.emitDiff(dir, previous, current)
 	return nil
 }
 
-func (p *pollingBackend) snapshotDirectory(dir string) (map[string]state.FileSignature, error) {
+// snapshotDirectory walks dir and returns the signature of every file along
+// with the set of directories found. Entries that vanish mid-walk, such as a
+// directory created and removed between listing and stat, are skipped instead
+// of failing the whole snapshot; the next poll reconciles them. Entries that
+// cannot be read because of missing permissions are skipped too and returned
+// as denied so callers can tell them apart from deletions. With skipHidden,
+// hidden entries below dir are left out and hidden directories not entered.
+// Once the walk has listed the files, their signatures are computed by up to
+// p.concurrency workers. Files whose size and modification time match their
+// signature in previous keep it without being read again, so a stable tree
+// costs a walk per poll rather than a hash of every small file; content
+// rewritten in place with both preserved is left to the watcher's safety
+// scans. The walk stops with ctx.Err() once ctx is canceled.
+func (p *pollingBackend) snapshotDirectory(ctx context.Context, dir string, previous map[string]state.FileSignature) (map[string]state.FileSignature, map[string]struct{}, []string, error) {
 	snapshot := make(map[string]state.FileSignature)
+	dirs := make(map[string]struct{})
+	var denied []string
+	var files []state.FileEntry
 	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
+			if path != dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if errors.Is(err, fs.ErrPermission) {
+				denied = append(denied, path)
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 			return err
 		}
+		if p.skipHidden && path != dir && state.IsHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if d.IsDir() {
+			dirs[path] = struct{}{}
 			return nil
 		}
 
 		info, err := d.Info()
 		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
 			return err
 		}
 
-		sig, err := state.ComputeSignature(path, info)
-		if err != nil {
-			return err
+		if sig, ok := previous[path]; ok && sig.SameStat(info) {
+			snapshot[path] = sig
+			return nil
 		}
-		snapshot[path] = sig
+		files = append(files, state.FileEntry{Path: path, Info: info})
 		return nil
 	})
-	return snapshot, err
+	if err != nil {
+		return snapshot, dirs, denied, err
+	}
+
+	for i, result := range p.signature.ComputeAll(ctx, files, p.concurrency) {
+		path := files[i].Path
+		switch {
+		case result.Err == nil:
+			snapshot[path] = result.Signature
+		case errors.Is(result.Err, fs.ErrNotExist):
+		case errors.Is(result.Err, fs.ErrPermission):
+			denied = append(denied, path)
+		default:
+			return snapshot, dirs, denied, result.Err
+		}
+	}
+	return snapshot, dirs, denied, nil
+}
+
+// withinAny reports whether path equals or lies beneath any of roots.
+func withinAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if state.PathWithin(path, root) {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *pollingBackend) emitDiff(dir string, previous, current map[string]state.FileSignature) {
@@ -244,7 +440,7 @@ func (p *pollingBackend) emitDiff(dir string, previous, current map[string]state
 			p.enqueue(Event{Path: path, Type: EventCreate, Timestamp: now})
 			continue
 		}
-		if !old.Equal(sig) {
+		if !old.Equal(sig) || p.trackMode && old.ModeChanged(sig) || p.trackOwner && old.OwnerChanged(sig) {
 			p.enqueue(Event{Path: path, Type: EventModify, Timestamp: now})
 		}
 	}
@@ -1,14 +1,20 @@
 package events
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
+
+	"lowkey/internal/state"
 )
 
 func TestPollingBackendDetectsNewFile(t *testing.T) {
-	backend, err := NewPollingBackend(25 * time.Millisecond)
+	backend, err := NewPollingBackend(25*time.Millisecond, BackendConfig{})
 	if err != nil {
 		t.Fatalf("new polling backend: %v", err)
 	}
@@ -36,3 +42,246 @@ func TestPollingBackendDetectsNewFile(t *testing.T) {
 		t.Fatalf("timeout waiting for event")
 	}
 }
+
+func TestPollingBackendTracksDiscoveredSubdirectories(t *testing.T) {
+	backend, err := NewPollingBackend(25*time.Millisecond, BackendConfig{})
+	if err != nil {
+		t.Fatalf("new polling backend: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = backend.Close()
+	})
+
+	dir := t.TempDir()
+	if err := backend.Add(dir); err != nil {
+		t.Fatalf("add watch dir: %v", err)
+	}
+	if paths := backend.WatchedPaths(); len(paths) != 1 || paths[0] != dir {
+		t.Fatalf("expected only the root to be watched, got %v", paths)
+	}
+
+	nested := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(backend.WatchedPaths()) != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	want := []string{dir, filepath.Join(dir, "a"), nested}
+	got := backend.WatchedPaths()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	if err := backend.Remove(dir); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if paths := backend.WatchedPaths(); len(paths) != 0 {
+		t.Fatalf("expected removing the root to drop its subtree, got %v", paths)
+	}
+}
+
+func TestSnapshotSkipsNestedHiddenEntries(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".project")
+	for _, dir := range []string{"src/.cache/deep", ".git/objects", "src/visible"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	for _, file := range []string{"src/main.go", "src/.env", "src/.cache/deep/blob", ".git/objects/pack", "src/visible/a.txt"} {
+		if err := os.WriteFile(filepath.Join(root, file), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	backend := &pollingBackend{skipHidden: true}
+	files, dirs, _, err := backend.snapshotDirectory(context.Background(), root, nil)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected only main.go and a.txt under the hidden root, got %v", files)
+	}
+	for _, hidden := range []string{".git", filepath.Join("src", ".cache")} {
+		if _, ok := dirs[filepath.Join(root, hidden)]; ok {
+			t.Fatalf("expected hidden directory %s to be skipped", hidden)
+		}
+	}
+}
+
+func TestPollingBackendAddContextAbortsWhenCanceled(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	backend, err := NewPollingBackend(time.Hour, BackendConfig{})
+	if err != nil {
+		t.Fatalf("new polling backend: %v", err)
+	}
+	defer backend.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := AddContext(ctx, backend, root); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the canceled walk to fail with context.Canceled, got %v", err)
+	}
+	if paths := backend.WatchedPaths(); len(paths) != 0 {
+		t.Fatalf("expected nothing to be watched after an aborted add, got %v", paths)
+	}
+	if err := AddContext(context.Background(), backend, root); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if paths := backend.WatchedPaths(); len(paths) != 1 {
+		t.Fatalf("expected the root to be watched, got %v", paths)
+	}
+}
+
+func TestSnapshotReusesSignaturesOfUnchangedFiles(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "config.txt")
+	if err := os.WriteFile(path, []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	backend := &pollingBackend{concurrency: 1}
+	first, _, _, err := backend.snapshotDirectory(context.Background(), root, nil)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if first[path].Hash == "" {
+		t.Fatalf("expected the small file to be hashed, got %+v", first[path])
+	}
+
+	// Same size and mtime: the previous signature, hash included, is kept
+	// without reading the file.
+	if err := os.WriteFile(path, []byte("bravo"), 0o644); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	second, _, _, err := backend.snapshotDirectory(context.Background(), root, first)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if !second[path].Equal(first[path]) {
+		t.Fatalf("expected the unchanged signature to be reused, got %+v want %+v", second[path], first[path])
+	}
+
+	// A new mtime gets the file hashed again.
+	if err := os.Chtimes(path, mtime.Add(time.Minute), mtime.Add(time.Minute)); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	third, _, _, err := backend.snapshotDirectory(context.Background(), root, second)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if third[path].Hash == first[path].Hash {
+		t.Fatalf("expected a changed mtime to rehash the file")
+	}
+}
+
+// BenchmarkSnapshotDirectory measures the initial snapshot of a synthetic tree
+// of 100,000 small files spread over 1,000 directories, hashing serially and
+// with one worker per CPU, and a later poll of the unchanged tree.
+func BenchmarkSnapshotDirectory(b *testing.B) {
+	root := b.TempDir()
+	for d := 0; d < 1000; d++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%02d", d/100), fmt.Sprintf("dir%03d", d))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.Fatalf("mkdir: %v", err)
+		}
+		for f := 0; f < 100; f++ {
+			content := []byte(fmt.Sprintf("package dir%03d // file %d\n", d, f))
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%03d.go", f)), content, 0o644); err != nil {
+				b.Fatalf("write: %v", err)
+			}
+		}
+	}
+
+	for _, bench := range []struct {
+		name        string
+		concurrency int
+		unchanged   bool
+	}{
+		{"serial", 1, false},
+		{"parallel", runtime.GOMAXPROCS(0), false},
+		{"unchanged", runtime.GOMAXPROCS(0), true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			backend := &pollingBackend{concurrency: bench.concurrency}
+			var previous map[string]state.FileSignature
+			if bench.unchanged {
+				var err error
+				if previous, _, _, err = backend.snapshotDirectory(context.Background(), root, nil); err != nil {
+					b.Fatalf("snapshot: %v", err)
+				}
+				b.ResetTimer()
+			}
+			for i := 0; i < b.N; i++ {
+				files, _, _, err := backend.snapshotDirectory(context.Background(), root, previous)
+				if err != nil {
+					b.Fatalf("snapshot: %v", err)
+				}
+				if len(files) != 100000 {
+					b.Fatalf("expected 100000 files, got %d", len(files))
+				}
+			}
+		})
+	}
+}
+
+func TestPollingBackendEventBufferBoundsQueuedEvents(t *testing.T) {
+	for _, tc := range []struct {
+		buffer int
+		want   int
+	}{
+		{buffer: 2, want: 2},
+		{buffer: 0, want: 5},
+	} {
+		t.Run(fmt.Sprintf("buffer %d", tc.buffer), func(t *testing.T) {
+			backend, err := NewPollingBackend(20*time.Millisecond, BackendConfig{EventBuffer: tc.buffer})
+			if err != nil {
+				t.Fatalf("new polling backend: %v", err)
+			}
+			t.Cleanup(func() {
+				_ = backend.Close()
+			})
+			if got := cap(backend.Events()); tc.buffer == 0 && got != DefaultEventBuffer {
+				t.Fatalf("default capacity = %d, want %d", got, DefaultEventBuffer)
+			}
+
+			dir := t.TempDir()
+			if err := backend.Add(dir); err != nil {
+				t.Fatalf("add watch dir: %v", err)
+			}
+			for i := 0; i < 5; i++ {
+				if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file-%d.txt", i)), []byte("x"), 0o644); err != nil {
+					t.Fatalf("write file: %v", err)
+				}
+			}
+
+			// Nothing reads the channel, so events beyond the buffer are
+			// dropped rather than blocking the poll loop.
+			deadline := time.Now().Add(2 * time.Second)
+			for len(backend.Events()) < tc.want && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(100 * time.Millisecond)
+			if got := len(backend.Events()); got != tc.want {
+				t.Fatalf("queued events = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
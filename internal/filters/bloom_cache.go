@@ -0,0 +1,97 @@
+package filters
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ignoreBloomFalsePositives is the false positive rate of the ignore filter.
+const ignoreBloomFalsePositives = 0.01
+
+// BuildIgnoreBloom builds the Bloom filter holding the tokens of patterns, or
+// returns nil when there are none.
+func BuildIgnoreBloom(patterns []string) *BloomFilter {
+	if len(patterns) == 0 {
+		return nil
+	}
+	var tokens []string
+	for _, pattern := range patterns {
+		tokens = append(tokens, ExtractPatternTokens(pattern)...)
+	}
+	bloom := NewBloomFilter(len(patterns)*8, ignoreBloomFalsePositives)
+	bloom.AddAll(tokens...)
+	return bloom
+}
+
+// patternSetKey identifies a pattern set, and the way its filter is built, in
+// a Bloom cache file. Patterns are compared in order, since the order is part
+// of the set's identity for the callers that pass it.
+func patternSetKey(patterns []string) [sha256.Size]byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "ignore-bloom/%d/%g\x00", bloomFormat, ignoreBloomFalsePositives)
+	for _, pattern := range patterns {
+		h.Write([]byte(pattern))
+		h.Write([]byte{0})
+	}
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// LoadIgnoreBloom reads the filter for patterns from the cache file at path.
+// It reports false when the file is missing, unreadable, or was written for a
+// different pattern set, in which case the caller builds the filter instead.
+func LoadIgnoreBloom(path string, patterns []string) (*BloomFilter, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	key := patternSetKey(patterns)
+	if len(data) < len(key) || !bytes.Equal(data[:len(key)], key[:]) {
+		return nil, false
+	}
+	bloom := &BloomFilter{}
+	if err := bloom.UnmarshalBinary(data[len(key):]); err != nil {
+		return nil, false
+	}
+	return bloom, true
+}
+
+// SaveIgnoreBloom atomically writes bloom, built from patterns, to the cache
+// file at path for LoadIgnoreBloom.
+func SaveIgnoreBloom(path string, patterns []string, bloom *BloomFilter) error {
+	if bloom == nil {
+		return errors.New("filters: bloom filter is nil")
+	}
+	encoded, err := bloom.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	key := patternSetKey(patterns)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("filters: create bloom cache directory %q: %w", dir, err)
+	}
+	tempFile, err := os.CreateTemp(dir, "bloom-*.tmp")
+	if err != nil {
+		return fmt.Errorf("filters: create temp bloom cache: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+	if _, err := tempFile.Write(append(key[:], encoded...)); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("filters: write bloom cache: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("filters: close temp bloom cache: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), path); err != nil {
+		return fmt.Errorf("filters: replace bloom cache %q: %w", path, err)
+	}
+	return nil
+}
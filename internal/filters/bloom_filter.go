@@ -11,7 +11,11 @@ package filters
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math"
+	"sync"
+	"sync/atomic"
 )
 
 // bloom_filter.go builds the ignore Bloom filter discussed in algorithm_design.
@@ -21,7 +25,19 @@ import (
 // presence of an element. It offers a space-efficient way to check if a path
 // token is likely to be part of an ignore pattern, with a configurable false
 // positive rate.
+//
+// It is safe for concurrent use. Lookups read an immutable bit array without
+// locking; Add and AddAll build a new array and swap it in atomically, so a
+// lookup never observes a half-applied update. Writes are therefore costly,
+// which suits the read-heavy ignore path: add tokens in bulk with AddAll.
 type BloomFilter struct {
+	// mu serializes writers; readers only load state.
+	mu    sync.Mutex
+	state atomic.Pointer[bloomState]
+}
+
+// bloomState is one immutable version of a filter's contents.
+type bloomState struct {
 	bits []uint64
 	m    uint64
 	k    uint64
@@ -47,55 +63,131 @@ func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
 		slots = 1
 	}
 
-	return &BloomFilter{
+	bf := &BloomFilter{}
+	bf.state.Store(&bloomState{
 		bits: make([]uint64, slots),
 		m:    m,
 		k:    k,
-	}
+	})
+	return bf
 }
 
 // Add inserts a token into the Bloom filter. It computes multiple hash values
 // for the token and sets the corresponding bits in the filter's bit array.
 func (bf *BloomFilter) Add(token string) {
-	if bf == nil || bf.m == 0 {
+	bf.AddAll(token)
+}
+
+// AddAll inserts every token with a single copy of the bit array, which makes
+// it much cheaper than calling Add for each of them.
+func (bf *BloomFilter) AddAll(tokens ...string) {
+	if bf == nil || len(tokens) == 0 {
 		return
 	}
-	h1, h2 := hashPair(token)
-	bf.lockBits(h1, h2)
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	current := bf.state.Load()
+	if current == nil || current.m == 0 {
+		return
+	}
+	next := &bloomState{bits: append([]uint64(nil), current.bits...), m: current.m, k: current.k}
+	for _, token := range tokens {
+		h1, h2 := hashPair(token)
+		next.setBits(h1, h2)
+	}
+	bf.state.Store(next)
 }
 
 // Contains reports whether the token may be present in the filter. A return
 // value of false means the token is definitely not present, while a value of
 // true means it is probably present.
 func (bf *BloomFilter) Contains(token string) bool {
-	if bf == nil || bf.m == 0 {
+	if bf == nil {
+		return false
+	}
+	current := bf.state.Load()
+	if current == nil || current.m == 0 {
 		return false
 	}
 	h1, h2 := hashPair(token)
-	return bf.checkBits(h1, h2)
+	return current.checkBits(h1, h2)
 }
 
-func (bf *BloomFilter) lockBits(h1, h2 uint64) {
-	if bf.m == 0 {
-		return
+// bloomMagic and bloomFormat identify the MarshalBinary encoding: the magic,
+// a format byte, m and k as little-endian uint64s, then the bit words.
+const (
+	bloomMagic  = "LKBF"
+	bloomFormat = 1
+)
+
+// bloomHeaderSize is the length of the encoding before the bit words.
+const bloomHeaderSize = len(bloomMagic) + 1 + 8 + 8
+
+// MarshalBinary encodes the filter's bit array and parameters so it can be
+// stored and restored with UnmarshalBinary instead of being rebuilt.
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	if bf == nil {
+		return nil, errors.New("filters: bloom filter is nil")
+	}
+	current := bf.state.Load()
+	if current == nil {
+		return nil, errors.New("filters: bloom filter is empty")
+	}
+	data := make([]byte, 0, bloomHeaderSize+8*len(current.bits))
+	data = append(data, bloomMagic...)
+	data = append(data, bloomFormat)
+	data = binary.LittleEndian.AppendUint64(data, current.m)
+	data = binary.LittleEndian.AppendUint64(data, current.k)
+	for _, word := range current.bits {
+		data = binary.LittleEndian.AppendUint64(data, word)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary replaces the filter with one encoded by MarshalBinary. It
+// rejects data with an unknown format or inconsistent parameters, leaving the
+// filter unchanged.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < bloomHeaderSize || string(data[:len(bloomMagic)]) != bloomMagic {
+		return errors.New("filters: not an encoded bloom filter")
 	}
-	for i := uint64(0); i < bf.k; i++ {
-		combined := (h1 + i*h2) % bf.m
+	if format := data[len(bloomMagic)]; format != bloomFormat {
+		return fmt.Errorf("filters: unsupported bloom filter format %d", format)
+	}
+	header := data[len(bloomMagic)+1:]
+	m := binary.LittleEndian.Uint64(header[0:8])
+	k := binary.LittleEndian.Uint64(header[8:16])
+	words := data[bloomHeaderSize:]
+	if m == 0 || k == 0 || len(words)%8 != 0 || uint64(len(words)/8) != (m+63)/64 {
+		return errors.New("filters: corrupt bloom filter")
+	}
+	bits := make([]uint64, len(words)/8)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(words[8*i:])
+	}
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	bf.state.Store(&bloomState{bits: bits, m: m, k: k})
+	return nil
+}
+
+// setBits sets the token bits for h1 and h2. It is only called on a state
+// that has not been published yet.
+func (s *bloomState) setBits(h1, h2 uint64) {
+	for i := uint64(0); i < s.k; i++ {
+		combined := (h1 + i*h2) % s.m
 		index := combined / 64
 		mask := uint64(1) << (combined % 64)
-		bf.bits[index] |= mask
+		s.bits[index] |= mask
 	}
 }
 
-func (bf *BloomFilter) checkBits(h1, h2 uint64) bool {
-	if bf.m == 0 {
-		return false
-	}
-	for i := uint64(0); i < bf.k; i++ {
-		combined := (h1 + i*h2) % bf.m
+func (s *bloomState) checkBits(h1, h2 uint64) bool {
+	for i := uint64(0); i < s.k; i++ {
+		combined := (h1 + i*h2) % s.m
 		index := combined / 64
 		mask := uint64(1) << (combined % 64)
-		if bf.bits[index]&mask == 0 {
+		if s.bits[index]&mask == 0 {
 			return false
 		}
 	}
@@ -1,6 +1,11 @@
 package filters
 
-import "testing"
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
 
 func TestBloomFilterAddContains(t *testing.T) {
 	bf := NewBloomFilter(10, 0.01)
@@ -20,3 +25,90 @@ func TestBloomFilterAddContains(t *testing.T) {
 		t.Fatalf("expected bloom filter to report missing token")
 	}
 }
+
+func TestBloomFilterBinaryRoundTrip(t *testing.T) {
+	bf := NewBloomFilter(64, 0.01)
+	for _, token := range []string{"build", "node_modules", ".log", "vendor"} {
+		bf.Add(token)
+	}
+	data, err := bf.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	restored := &BloomFilter{}
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for _, token := range []string{"build", "node_modules", ".log", "vendor", "src", "main.go", "dist", "tmp"} {
+		if got, want := restored.Contains(token), bf.Contains(token); got != want {
+			t.Fatalf("Contains(%q) = %t after the round trip, want %t", token, got, want)
+		}
+	}
+
+	if err := restored.UnmarshalBinary(data[:len(data)-8]); err == nil {
+		t.Fatalf("expected truncated data to be rejected")
+	}
+	if err := restored.UnmarshalBinary([]byte("not a filter at all")); err == nil {
+		t.Fatalf("expected foreign data to be rejected")
+	}
+	if !restored.Contains("vendor") {
+		t.Fatalf("expected a rejected decode to leave the filter unchanged")
+	}
+}
+
+func TestIgnoreBloomCacheIsKeyedByPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ignore.bloom")
+	patterns := []string{"*.log", "node_modules", "build/"}
+	if _, ok := LoadIgnoreBloom(path, patterns); ok {
+		t.Fatalf("expected a missing cache file to miss")
+	}
+	if err := SaveIgnoreBloom(path, patterns, BuildIgnoreBloom(patterns)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	bloom, ok := LoadIgnoreBloom(path, patterns)
+	if !ok {
+		t.Fatalf("expected the cached filter for the same patterns to load")
+	}
+	for _, token := range ExtractPatternTokens("node_modules") {
+		if !bloom.Contains(token) {
+			t.Fatalf("expected the cached filter to contain %q", token)
+		}
+	}
+	if _, ok := LoadIgnoreBloom(path, append(patterns, "*.tmp")); ok {
+		t.Fatalf("expected a changed pattern set to miss the cache")
+	}
+}
+
+func TestBloomFilterConcurrentAddAndContains(t *testing.T) {
+	bf := NewBloomFilter(1024, 0.01)
+	bf.Add("seed")
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				bf.Add(fmt.Sprintf("token-%d-%d", w, i))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				if !bf.Contains("seed") {
+					t.Errorf("expected a token added before the writers to stay present")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for w := 0; w < 4; w++ {
+		for i := 0; i < 200; i++ {
+			if token := fmt.Sprintf("token-%d-%d", w, i); !bf.Contains(token) {
+				t.Fatalf("expected concurrently added %q to be present", token)
+			}
+		}
+	}
+}
@@ -11,8 +11,29 @@ package filters
 import (
 	"path/filepath"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// TrimPattern removes surrounding whitespace from a glob pattern, keeping a
+// trailing whitespace character that is escaped with a backslash (`foo\ `) as
+// gitignore does. The escape itself is kept so glob matching treats the
+// character literally.
+func TrimPattern(pattern string) string {
+	pattern = strings.TrimLeftFunc(pattern, unicode.IsSpace)
+	trimmed := strings.TrimRightFunc(pattern, unicode.IsSpace)
+	if len(trimmed) == len(pattern) {
+		return trimmed
+	}
+	backslashes := len(trimmed) - len(strings.TrimRight(trimmed, `\`))
+	if backslashes%2 == 1 {
+		// Keep the escaped whitespace character that follows.
+		_, size := utf8.DecodeRuneInString(pattern[len(trimmed):])
+		return pattern[:len(trimmed)+size]
+	}
+	return trimmed
+}
+
 // ExtractPatternTokens normalizes a glob pattern and extracts a set of
 // representative tokens from it. These tokens are used to populate the Bloom
 // filter, allowing for fast, probabilistic checks against file paths.
@@ -102,7 +123,7 @@ func stripGlobs(input string) string {
 	var builder strings.Builder
 	for _, r := range input {
 		switch r {
-		case '*', '?', '[', ']', '{', '}', '!':
+		case '*', '?', '[', ']', '{', '}', '!', '\\':
 			continue
 		default:
 			builder.WriteRune(r)
@@ -41,3 +41,18 @@ func contains(tokens []string, target string) bool {
 	}
 	return false
 }
+
+func TestTrimPatternKeepsEscapedTrailingSpace(t *testing.T) {
+	cases := map[string]string{
+		"  *.log  ":    "*.log",
+		`keep\ `:       `keep\ `,
+		`keep\    `:    `keep\ `,
+		`slash\\ `:     `slash\\`,
+		"\tbuild/\r\n": "build/",
+	}
+	for input, want := range cases {
+		if got := TrimPattern(input); got != want {
+			t.Fatalf("TrimPattern(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
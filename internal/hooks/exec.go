@@ -0,0 +1,350 @@
+// Package hooks runs user-supplied commands in response to file changes,
+// turning the watcher into a lightweight file-triggered task runner, posts
+// changes to webhooks for chat and alerting integrations, and mirrors changed
+// files to another directory.
+//
+// Changes are debounced so a burst of writes spawns one command per changed
+// path, or a single batch command for the whole burst, instead of one per
+// event, and commands run one at a time so a slow command never overlaps
+// itself. In restart mode a new burst interrupts the running command instead
+// of waiting for it, like `watchexec --restart`.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"lowkey/internal/logging"
+	"lowkey/internal/reporting"
+)
+
+// DefaultDebounce is how long Exec waits for changes to stop arriving before
+// running the command when ExecConfig.Debounce is zero.
+const DefaultDebounce = 250 * time.Millisecond
+
+// interruptGrace is how long an interrupted command may take to exit after it
+// is asked to terminate before it is killed.
+const interruptGrace = 2 * time.Second
+
+// outputTailSize is how much of a command's most recent output is kept for
+// the log when it fails.
+const outputTailSize = 2048
+
+// ExecConfig configures an Exec hook.
+type ExecConfig struct {
+	// Command is run through the system shell for each change. The
+	// placeholders {path}, {type}, and {dir} are replaced with the changed
+	// path, the change type, and the directory containing the path. The
+	// values are shell-quoted, so placeholders must not be quoted again.
+	// The path and type are also set in LOWKEY_PATH and LOWKEY_TYPE.
+	Command string
+	// BatchCommand is run through the system shell once per debounced burst
+	// of changes, after any per-change Command runs. The changed paths are
+	// written to its stdin one per line and set in LOWKEY_PATHS, also one
+	// per line, and the {paths} placeholder is replaced with all of them,
+	// shell-quoted and separated by spaces.
+	BatchCommand string
+	// Restart interrupts a running command when the next burst of changes is
+	// ready: the command, and on Unix its whole process group, is asked to
+	// terminate and killed if it has not exited within two seconds. The
+	// interrupted burst is merged into the new one and run again.
+	Restart bool
+	// Debounce is the quiet period after the last change before the pending
+	// changes are run. Zero uses DefaultDebounce.
+	Debounce time.Duration
+	// Logger receives the exit status of every run. When the output is not
+	// streamed to Stdout or Stderr, the end of a failed command's output is
+	// logged as well. Nil disables logging.
+	Logger *logging.Logger
+	// Stdout and Stderr receive the command's output as it is produced. Nil
+	// does not stream it.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Exec runs commands for changes passed to Notify. Changes are collected
+// until none has arrived for the debounce period and are then run in arrival
+// order, one at a time, followed by the batch command for all of them.
+// Repeated changes to the same path while pending are coalesced into one run
+// with the latest change type. A failing command is logged and never stops
+// the hook. It is safe for concurrent use.
+type Exec struct {
+	cfg ExecConfig
+
+	mu      sync.Mutex
+	pending map[string]reporting.Change
+	order   []string
+	timer   *time.Timer
+	closed  bool
+
+	ready chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewExec validates cfg and starts the worker that runs the commands. At least
+// one of Command and BatchCommand must be set.
+func NewExec(cfg ExecConfig) (*Exec, error) {
+	if strings.TrimSpace(cfg.Command) == "" && strings.TrimSpace(cfg.BatchCommand) == "" {
+		return nil, errors.New("hooks: exec command is empty")
+	}
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = DefaultDebounce
+	}
+	e := &Exec{
+		cfg:     cfg,
+		pending: make(map[string]reporting.Change),
+		ready:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.loop()
+	return e, nil
+}
+
+// Notify queues change and restarts the debounce period. Changes received
+// after Close are dropped.
+func (e *Exec) Notify(change reporting.Change) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	if _, ok := e.pending[change.Path]; !ok {
+		e.order = append(e.order, change.Path)
+	}
+	e.pending[change.Path] = change
+	if e.timer == nil {
+		e.timer = time.AfterFunc(e.cfg.Debounce, e.signal)
+	} else {
+		e.timer.Reset(e.cfg.Debounce)
+	}
+}
+
+// Close runs the changes still pending, waits for the command to finish, and
+// stops the worker. It is safe to call more than once and on a nil Exec.
+func (e *Exec) Close() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		<-e.done
+		return
+	}
+	e.closed = true
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	e.mu.Unlock()
+	close(e.stop)
+	<-e.done
+}
+
+func (e *Exec) signal() {
+	select {
+	case e.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (e *Exec) loop() {
+	defer close(e.done)
+	for {
+		select {
+		case <-e.ready:
+			e.runPending()
+		case <-e.stop:
+			e.runPending()
+			return
+		}
+	}
+}
+
+// runPending takes the pending changes and runs the per-change command for each
+// of them, then the batch command once. Changes that arrive meanwhile wait for
+// the next debounce period, unless restart mode interrupts the run, in which
+// case the batch is queued again ahead of them.
+func (e *Exec) runPending() {
+	e.mu.Lock()
+	batch := make([]reporting.Change, 0, len(e.order))
+	for _, path := range e.order {
+		batch = append(batch, e.pending[path])
+	}
+	e.pending = make(map[string]reporting.Change)
+	e.order = nil
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	if e.cfg.Command != "" {
+		for _, change := range batch {
+			env := []string{"LOWKEY_PATH=" + change.Path, "LOWKEY_TYPE=" + change.Type}
+			if !e.run(Expand(e.cfg.Command, change), nil, env) {
+				e.requeue(batch)
+				return
+			}
+		}
+	}
+	if e.cfg.BatchCommand != "" {
+		var paths strings.Builder
+		for _, change := range batch {
+			paths.WriteString(change.Path)
+			paths.WriteByte('\n')
+		}
+		env := []string{"LOWKEY_PATHS=" + strings.TrimSuffix(paths.String(), "\n")}
+		if !e.run(ExpandBatch(e.cfg.BatchCommand, batch), strings.NewReader(paths.String()), env) {
+			e.requeue(batch)
+		}
+	}
+}
+
+// requeue puts the changes of an interrupted batch back ahead of those that
+// arrived since, keeping the newer change for a path present in both, and
+// runs them right away: the debounce period that triggered the interruption
+// has already passed.
+func (e *Exec) requeue(batch []reporting.Change) {
+	e.mu.Lock()
+	order := make([]string, 0, len(batch)+len(e.order))
+	for _, change := range batch {
+		if _, ok := e.pending[change.Path]; !ok {
+			e.pending[change.Path] = change
+			order = append(order, change.Path)
+		}
+	}
+	e.order = append(order, e.order...)
+	e.mu.Unlock()
+	e.signal()
+}
+
+// run executes command with stdin and env added to the environment, and logs
+// its exit status. It reports false when restart mode interrupted the command
+// because the next burst of changes was ready.
+func (e *Exec) run(command string, stdin io.Reader, env []string) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(), env...)
+	output := &tailBuffer{limit: outputTailSize}
+	cmd.Stdout = teeOutput(e.cfg.Stdout, output)
+	cmd.Stderr = teeOutput(e.cfg.Stderr, output)
+
+	interrupted := false
+	finished := make(chan struct{})
+	watched := make(chan struct{})
+	if e.cfg.Restart {
+		interruptible(cmd, interruptGrace)
+		go func() {
+			defer close(watched)
+			select {
+			case <-e.ready:
+				interrupted = true
+				cancel()
+			case <-finished:
+			}
+		}()
+	} else {
+		close(watched)
+	}
+
+	started := time.Now()
+	err := cmd.Run()
+	close(finished)
+	<-watched
+	elapsed := time.Since(started).Round(time.Millisecond)
+	if e.cfg.Logger == nil {
+		return !interrupted
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case interrupted:
+		e.cfg.Logger.Infof("exec %q interrupted by new changes after %s", command, elapsed)
+		return false
+	case err == nil:
+		e.cfg.Logger.Infof("exec %q exited with status 0 after %s", command, elapsed)
+		return true
+	case errors.As(err, &exitErr):
+		e.cfg.Logger.Errorf("exec %q exited with status %d after %s", command, exitErr.ExitCode(), elapsed)
+	default:
+		e.cfg.Logger.Errorf("exec %q: %v", command, err)
+	}
+	if e.cfg.Stdout == nil && e.cfg.Stderr == nil && output.Len() > 0 {
+		e.cfg.Logger.Errorf("exec %q output:\n%s", command, strings.TrimRight(output.String(), "\n"))
+	}
+	return true
+}
+
+// teeOutput returns the writer a command's output stream is sent to: the
+// stream itself, if any, and the failure log buffer.
+func teeOutput(stream io.Writer, buffer *tailBuffer) io.Writer {
+	if stream == nil {
+		return buffer
+	}
+	return io.MultiWriter(stream, buffer)
+}
+
+// tailBuffer keeps the last limit bytes written to it. It is safe for
+// concurrent use, since a command's stdout and stderr are copied to it by
+// separate goroutines.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+// Len returns the number of bytes kept.
+func (b *tailBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.data)
+}
+
+// String returns the kept bytes.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// Expand substitutes the {path}, {type}, and {dir} placeholders in command
+// with shell-quoted values taken from change.
+func Expand(command string, change reporting.Change) string {
+	return strings.NewReplacer(
+		"{path}", shellQuote(change.Path),
+		"{type}", shellQuote(change.Type),
+		"{dir}", shellQuote(filepath.Dir(change.Path)),
+	).Replace(command)
+}
+
+// ExpandBatch substitutes the {paths} placeholder in command with the
+// shell-quoted paths of changes, separated by spaces.
+func ExpandBatch(command string, changes []reporting.Change) string {
+	quoted := make([]string, len(changes))
+	for i, change := range changes {
+		quoted[i] = shellQuote(change.Path)
+	}
+	return strings.ReplaceAll(command, "{paths}", strings.Join(quoted, " "))
+}
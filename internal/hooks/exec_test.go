@@ -0,0 +1,190 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"lowkey/internal/logging"
+	"lowkey/internal/reporting"
+)
+
+func TestExpandQuotesPlaceholders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh quoting")
+	}
+	got := Expand("echo {type} {path} {dir}", reporting.Change{Path: "/tmp/it's here.txt", Type: "MODIFY"})
+	want := `echo 'MODIFY' '/tmp/it'\''s here.txt' '/tmp'`
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestExecDebouncesAndLogsExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	out := filepath.Join(t.TempDir(), "runs")
+	var logs bytes.Buffer
+	hook, err := NewExec(ExecConfig{
+		Command:  "echo {type} {path} >> " + shellQuote(out) + "; exit 3",
+		Debounce: 20 * time.Millisecond,
+		Logger:   logging.NewWriter(&logs),
+	})
+	if err != nil {
+		t.Fatalf("NewExec: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		hook.Notify(reporting.Change{Path: "/w/a.txt", Type: "MODIFY"})
+	}
+	hook.Notify(reporting.Change{Path: "/w/b.txt", Type: "CREATE"})
+	hook.Notify(reporting.Change{Path: "/w/a.txt", Type: "DELETE"})
+	hook.Close()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if got, want := string(data), "DELETE /w/a.txt\nCREATE /w/b.txt\n"; got != want {
+		t.Fatalf("expected one run per path in arrival order %q, got %q", want, got)
+	}
+	if n := strings.Count(logs.String(), "exited with status 3"); n != 2 {
+		t.Fatalf("expected two logged exit statuses, got %d:\n%s", n, logs.String())
+	}
+
+	hook.Notify(reporting.Change{Path: "/w/c.txt", Type: "CREATE"})
+	hook.Close()
+	if data, _ := os.ReadFile(out); strings.Contains(string(data), "c.txt") {
+		t.Fatalf("expected changes after Close to be dropped")
+	}
+}
+
+func TestExecRunsSerially(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "lock")
+	overlap := filepath.Join(dir, "overlap")
+	// Each run holds a lock file briefly; a run that finds it already held
+	// records an overlap.
+	command := "if [ -e " + shellQuote(lock) + " ]; then touch " + shellQuote(overlap) + "; fi; " +
+		"touch " + shellQuote(lock) + "; sleep 0.05; rm -f " + shellQuote(lock)
+	hook, err := NewExec(ExecConfig{Command: command, Debounce: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewExec: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		hook.Notify(reporting.Change{Path: filepath.Join(dir, string(rune('a'+i))), Type: "CREATE"})
+		time.Sleep(10 * time.Millisecond)
+	}
+	hook.Close()
+	if _, err := os.Stat(overlap); err == nil {
+		t.Fatalf("expected runs not to overlap")
+	}
+}
+
+func TestExecBatchRunsOncePerBurstAndFlushesOnClose(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "batches")
+	hook, err := NewExec(ExecConfig{
+		BatchCommand: "{ echo \"args: $#\"; cat; echo --; } >> " + shellQuote(out),
+		Debounce:     time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewExec: %v", err)
+	}
+	hook.Notify(reporting.Change{Path: "/w/a b.txt", Type: "CREATE"})
+	hook.Notify(reporting.Change{Path: "/w/c.txt", Type: "MODIFY"})
+	hook.Notify(reporting.Change{Path: "/w/a b.txt", Type: "MODIFY"})
+	// The debounce period never elapses, so only Close runs the batch.
+	hook.Close()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("expected the pending batch to run on Close: %v", err)
+	}
+	if got, want := string(data), "args: 0\n/w/a b.txt\n/w/c.txt\n--\n"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestExpandBatchQuotesPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh quoting")
+	}
+	got := ExpandBatch("touch {paths}", []reporting.Change{{Path: "/w/a b"}, {Path: "/w/c"}})
+	if want := "touch '/w/a b' '/w/c'"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestExecRestartInterruptsRunningCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	out := filepath.Join(t.TempDir(), "runs")
+	var logs bytes.Buffer
+	hook, err := NewExec(ExecConfig{
+		BatchCommand: "echo \"start $LOWKEY_PATHS\" >> " + shellQuote(out) + "; sleep 1; echo done >> " + shellQuote(out),
+		Debounce:     20 * time.Millisecond,
+		Restart:      true,
+		Logger:       logging.NewWriter(&logs),
+	})
+	if err != nil {
+		t.Fatalf("NewExec: %v", err)
+	}
+
+	hook.Notify(reporting.Change{Path: "/w/a.txt", Type: "MODIFY"})
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, _ := os.ReadFile(out); len(data) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	hook.Notify(reporting.Change{Path: "/w/b.txt", Type: "CREATE"})
+	time.Sleep(200 * time.Millisecond)
+	hook.Close()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	// The first run never finishes; the second sees both bursts.
+	if got, want := string(data), "start /w/a.txt\nstart /w/a.txt\n/w/b.txt\ndone\n"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if !strings.Contains(logs.String(), "interrupted by new changes") {
+		t.Fatalf("expected the interruption to be logged:\n%s", logs.String())
+	}
+}
+
+func TestExecLogsOutputOfFailedRunWhenNotStreamed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	var logs bytes.Buffer
+	hook, err := NewExec(ExecConfig{
+		Command:  "echo \"checking $LOWKEY_TYPE $LOWKEY_PATH\"; echo broken >&2; exit 1",
+		Debounce: time.Hour,
+		Logger:   logging.NewWriter(&logs),
+	})
+	if err != nil {
+		t.Fatalf("NewExec: %v", err)
+	}
+	hook.Notify(reporting.Change{Path: "/w/a.txt", Type: "MODIFY"})
+	hook.Close()
+
+	if !strings.Contains(logs.String(), "checking MODIFY /w/a.txt\nbroken") {
+		t.Fatalf("expected the failed run's output to be logged:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,260 @@
+package hooks
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"lowkey/internal/logging"
+	"lowkey/internal/reporting"
+)
+
+// MirrorConfig configures a Mirror hook.
+type MirrorConfig struct {
+	// Dest is the directory the watched directories are mirrored to. With
+	// a single root its contents mirror the root; with several, each root
+	// is mirrored to the subdirectory of Dest named after it.
+	Dest string
+	// Roots are the watched directories. Changes outside them are ignored.
+	Roots []string
+	// Debounce is the quiet period after the last change before the pending
+	// changes are copied. Zero uses DefaultDebounce.
+	Debounce time.Duration
+	// Logger receives the changes that could not be mirrored. Nil disables
+	// logging.
+	Logger *logging.Logger
+}
+
+// Mirror copies changed files to a second directory, a one-way sync on
+// change. A created or modified file is copied with its mode and
+// modification time, and a deleted one is removed from the mirror. Like
+// Exec, changes are debounced and coalesced per path, so a file written many
+// times in a burst is copied once. Only changes passed to Notify are
+// mirrored: files ignored by the watcher are never copied, and files changed
+// while lowkey was not watching are not caught up. It is safe for concurrent
+// use.
+type Mirror struct {
+	cfg MirrorConfig
+
+	mu      sync.Mutex
+	pending map[string]reporting.Change
+	order   []string
+	timer   *time.Timer
+	closed  bool
+
+	ready chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewMirror validates cfg and starts the worker that copies the changes.
+func NewMirror(cfg MirrorConfig) (*Mirror, error) {
+	if strings.TrimSpace(cfg.Dest) == "" {
+		return nil, errors.New("hooks: mirror destination is empty")
+	}
+	if len(cfg.Roots) == 0 {
+		return nil, errors.New("hooks: mirror has no directories")
+	}
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = DefaultDebounce
+	}
+	m := &Mirror{
+		cfg:     cfg,
+		pending: make(map[string]reporting.Change),
+		ready:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go m.loop()
+	return m, nil
+}
+
+// Notify queues change and restarts the debounce period. Changes received
+// after Close are dropped.
+func (m *Mirror) Notify(change reporting.Change) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	if _, ok := m.pending[change.Path]; !ok {
+		m.order = append(m.order, change.Path)
+	}
+	m.pending[change.Path] = change
+	if m.timer == nil {
+		m.timer = time.AfterFunc(m.cfg.Debounce, m.signal)
+	} else {
+		m.timer.Reset(m.cfg.Debounce)
+	}
+}
+
+// Close mirrors the changes still pending and stops the worker. It is safe to
+// call more than once and on a nil Mirror.
+func (m *Mirror) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		<-m.done
+		return
+	}
+	m.closed = true
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	m.mu.Unlock()
+	close(m.stop)
+	<-m.done
+}
+
+func (m *Mirror) signal() {
+	select {
+	case m.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Mirror) loop() {
+	defer close(m.done)
+	for {
+		select {
+		case <-m.ready:
+			m.mirrorPending()
+		case <-m.stop:
+			m.mirrorPending()
+			return
+		}
+	}
+}
+
+func (m *Mirror) mirrorPending() {
+	m.mu.Lock()
+	batch := make([]reporting.Change, 0, len(m.order))
+	for _, path := range m.order {
+		batch = append(batch, m.pending[path])
+	}
+	m.pending = make(map[string]reporting.Change)
+	m.order = nil
+	m.mu.Unlock()
+
+	for _, change := range batch {
+		if err := m.apply(change); err != nil && m.cfg.Logger != nil {
+			m.cfg.Logger.Errorf("mirror %s: %v", change.Path, err)
+		}
+	}
+}
+
+// apply brings the mirror of change.Path in line with the file as it is now,
+// which may differ from the change when the file changed again since.
+func (m *Mirror) apply(change reporting.Change) error {
+	target, ok := m.Target(change.Path)
+	if !ok {
+		return nil
+	}
+	info, err := os.Lstat(change.Path)
+	if change.Type == "DELETE" || errors.Is(err, fs.ErrNotExist) {
+		return os.RemoveAll(target)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(change.Path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	case info.Mode().IsRegular():
+		return copyFile(change.Path, target, info)
+	default:
+		// Sockets, pipes, and devices have no content to copy.
+		return nil
+	}
+}
+
+// Target returns the path in the mirror corresponding to path, and false
+// when path is not inside one of the roots.
+func (m *Mirror) Target(path string) (string, bool) {
+	root := ""
+	for _, candidate := range m.cfg.Roots {
+		if within(path, candidate) && len(candidate) > len(root) {
+			root = candidate
+		}
+	}
+	if root == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", false
+	}
+	if len(m.cfg.Roots) > 1 {
+		rel = filepath.Join(filepath.Base(root), rel)
+	}
+	return filepath.Join(m.cfg.Dest, rel), true
+}
+
+func within(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// copyFile copies src to dst through a temporary file renamed into place, so
+// a reader of the mirror never sees a partial copy, and gives it the mode and
+// modification time of info.
+func copyFile(src, dst string, info fs.FileInfo) (err error) {
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".lowkey-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lowkey/internal/reporting"
+)
+
+func TestMirrorCopiesAndRemovesChangedFiles(t *testing.T) {
+	root, dest := t.TempDir(), t.TempDir()
+	src := filepath.Join(root, "sub", "a.txt")
+	if err := os.MkdirAll(filepath.Dir(src), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("hello"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(src, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	mirror, err := NewMirror(MirrorConfig{Dest: dest, Roots: []string{root}, Debounce: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewMirror: %v", err)
+	}
+	mirror.Notify(reporting.Change{Path: src, Type: "CREATE"})
+	mirror.Notify(reporting.Change{Path: src, Type: "MODIFY"})
+	mirror.Notify(reporting.Change{Path: filepath.Join(t.TempDir(), "outside.txt"), Type: "CREATE"})
+	mirror.Close()
+
+	target := filepath.Join(dest, "sub", "a.txt")
+	data, err := os.ReadFile(target)
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("expected the file to be copied, got %q (%v)", data, err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 || !info.ModTime().Equal(mtime) {
+		t.Fatalf("expected mode 0640 and mtime %s, got %s and %s", mtime, info.Mode().Perm(), info.ModTime())
+	}
+	if entries, _ := os.ReadDir(dest); len(entries) != 1 {
+		t.Fatalf("expected only the copied tree in the mirror, got %d entries", len(entries))
+	}
+
+	if err := os.Remove(src); err != nil {
+		t.Fatal(err)
+	}
+	mirror, err = NewMirror(MirrorConfig{Dest: dest, Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("NewMirror: %v", err)
+	}
+	mirror.Notify(reporting.Change{Path: src, Type: "DELETE"})
+	mirror.Close()
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("expected the mirrored file to be removed, got %v", err)
+	}
+}
+
+func TestMirrorTargetNamesEachRootWhenThereAreSeveral(t *testing.T) {
+	mirror := &Mirror{cfg: MirrorConfig{Dest: "/backup", Roots: []string{"/src/api", "/src/web"}}}
+	if got, ok := mirror.Target("/src/web/index.html"); !ok || got != filepath.FromSlash("/backup/web/index.html") {
+		t.Fatalf("unexpected target %q (%t)", got, ok)
+	}
+	if _, ok := mirror.Target("/src/webhooks/x"); ok {
+		t.Fatalf("expected a sibling with a common prefix to be outside the roots")
+	}
+}
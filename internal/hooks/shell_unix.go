@@ -0,0 +1,33 @@
+//go:build !windows
+
+package hooks
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// shellCommand runs command through /bin/sh. Canceling ctx kills the shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// interruptible starts cmd in its own process group so canceling its context
+// sends SIGTERM to the shell and everything it spawned, escalating to SIGKILL
+// on the shell after grace.
+func interruptible(cmd *exec.Cmd, grace time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = grace
+}
+
+// shellQuote wraps value in single quotes for /bin/sh, escaping any single
+// quotes it contains.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
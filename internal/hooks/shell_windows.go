@@ -0,0 +1,28 @@
+//go:build windows
+
+package hooks
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// shellCommand runs command through cmd.exe. Canceling ctx kills it.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
+
+// interruptible bounds how long cmd may keep its output pipes open after its
+// context is canceled. Windows has no process groups to signal, so only
+// cmd.exe itself is killed.
+func interruptible(cmd *exec.Cmd, grace time.Duration) {
+	cmd.WaitDelay = grace
+}
+
+// shellQuote wraps value in double quotes for cmd.exe. Double quotes cannot
+// appear in Windows file names, so they are dropped.
+func shellQuote(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+}
@@ -0,0 +1,330 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"lowkey/internal/logging"
+	"lowkey/internal/reporting"
+)
+
+// Defaults applied by NewWebhook to zero-valued WebhookConfig fields.
+const (
+	DefaultWebhookQueue    = 256
+	DefaultWebhookAttempts = 5
+	DefaultWebhookBackoff  = 500 * time.Millisecond
+	DefaultWebhookTimeout  = 5 * time.Second
+	// DefaultWebhookBatchInterval is how long a batch collects changes when
+	// batching is enabled without an interval.
+	DefaultWebhookBatchInterval = time.Second
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the request body when
+// WebhookConfig.Secret is set, as "sha256=" followed by the hex digest.
+const WebhookSignatureHeader = "X-Lowkey-Signature"
+
+// maxWebhookBackoff caps the doubling delay between delivery attempts.
+const maxWebhookBackoff = 30 * time.Second
+
+// WebhookConfig configures a Webhook hook.
+type WebhookConfig struct {
+	// URL is the http(s) endpoint each change is POSTed to.
+	URL string
+	// Token, when set, is sent as a bearer credential in the Authorization
+	// header.
+	Token string
+	// QueueSize bounds how many changes may wait for delivery. Changes
+	// arriving while the queue is full are dropped. Zero uses
+	// DefaultWebhookQueue.
+	QueueSize int
+	// MaxAttempts is how many times a change is sent before it is dropped.
+	// Zero uses DefaultWebhookAttempts.
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles on every
+	// further retry, up to 30s. Zero uses DefaultWebhookBackoff.
+	Backoff time.Duration
+	// Timeout bounds each request, and how long Close waits for the queue to
+	// drain. Zero uses DefaultWebhookTimeout.
+	Timeout time.Duration
+	// BatchSize, when positive, sends changes as a JSON array of up to
+	// BatchSize documents per request instead of one document per change. A
+	// batch is sent once it is full or BatchInterval after its first change.
+	BatchSize int
+	// BatchInterval is how long a batch waits for more changes. Zero uses
+	// DefaultWebhookBatchInterval. It is ignored without BatchSize.
+	BatchInterval time.Duration
+	// Headers are added to every request, for example to route through a
+	// relay. They cannot replace Content-Type, the signature header, or the
+	// Authorization header when Token is set.
+	Headers map[string]string
+	// Secret, when set, signs every request body with HMAC-SHA256 so the
+	// receiver can verify it came from lowkey; see WebhookSignatureHeader.
+	Secret string
+	// Logger receives delivery failures. Nil disables logging.
+	Logger *logging.Logger
+	// OnFailure is called once for every change that could not be delivered,
+	// either because the queue was full or every attempt failed.
+	OnFailure func(error)
+}
+
+// webhookPayload is the JSON document POSTed for each change.
+type webhookPayload struct {
+	Path      string    `json:"path"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size,omitempty"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// errWebhookQueueFull is reported through OnFailure for dropped changes.
+var errWebhookQueueFull = errors.New("hooks: webhook queue is full")
+
+// Webhook POSTs a JSON document for every change passed to Notify, or a JSON
+// array per batch of changes. Deliveries run on a background worker fed by a
+// bounded queue, so a slow or unreachable endpoint never stalls the watcher;
+// failed requests are retried with exponential backoff. It is safe for
+// concurrent use.
+type Webhook struct {
+	cfg    WebhookConfig
+	client *http.Client
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan reporting.Change
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWebhook validates cfg and starts the delivery worker.
+func NewWebhook(cfg WebhookConfig) (*Webhook, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("hooks: webhook URL %q must be an http(s) URL", cfg.URL)
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultWebhookQueue
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultWebhookAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultWebhookBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultWebhookTimeout
+	}
+	if cfg.BatchInterval <= 0 {
+		cfg.BatchInterval = DefaultWebhookBatchInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Webhook{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan reporting.Change, cfg.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Notify queues change for delivery without blocking. When the queue is full
+// the change is dropped and counted as a failure. Changes received after Close
+// are ignored.
+func (w *Webhook) Notify(change reporting.Change) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- change:
+	default:
+		w.fail([]reporting.Change{change}, errWebhookQueueFull)
+	}
+}
+
+// Close stops accepting changes and waits up to the configured timeout for the
+// queued ones to be delivered; whatever is left after that is abandoned. It is
+// safe to call more than once and on a nil Webhook.
+func (w *Webhook) Close() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	timer := time.AfterFunc(w.cfg.Timeout, w.cancel)
+	<-w.done
+	timer.Stop()
+	w.cancel()
+}
+
+func (w *Webhook) loop() {
+	defer close(w.done)
+	if w.cfg.BatchSize <= 0 {
+		for change := range w.queue {
+			w.send([]reporting.Change{change})
+		}
+		return
+	}
+
+	var batch []reporting.Change
+	var timer *time.Timer
+	var expired <-chan time.Time
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, expired = nil, nil
+		}
+		w.send(batch)
+		batch = nil
+	}
+	for {
+		select {
+		case change, ok := <-w.queue:
+			if !ok {
+				if len(batch) > 0 {
+					flush()
+				}
+				return
+			}
+			batch = append(batch, change)
+			if len(batch) >= w.cfg.BatchSize {
+				flush()
+			} else if timer == nil {
+				timer = time.NewTimer(w.cfg.BatchInterval)
+				expired = timer.C
+			}
+		case <-expired:
+			flush()
+		}
+	}
+}
+
+// send delivers changes, as a single document without batching and as an
+// array with it, and reports them as failed if that does not succeed.
+func (w *Webhook) send(changes []reporting.Change) {
+	if err := w.ctx.Err(); err != nil {
+		w.fail(changes, err)
+		return
+	}
+	payloads := make([]webhookPayload, len(changes))
+	for i, change := range changes {
+		payloads[i] = webhookPayload{
+			Path:      change.Path,
+			Type:      change.Type,
+			Timestamp: change.Timestamp,
+			Size:      change.Size,
+			Source:    change.Source,
+		}
+	}
+	var body []byte
+	var err error
+	if w.cfg.BatchSize > 0 {
+		body, err = json.Marshal(payloads)
+	} else {
+		body, err = json.Marshal(payloads[0])
+	}
+	if err == nil {
+		err = w.deliver(body)
+	}
+	if err != nil {
+		w.fail(changes, err)
+	}
+}
+
+// deliver sends body, retrying with exponential backoff until it succeeds,
+// the endpoint rejects it outright, or the attempts run out.
+func (w *Webhook) deliver(body []byte) error {
+	delay := w.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil || !retry || attempt == w.cfg.MaxAttempts {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-w.ctx.Done():
+			return err
+		}
+		delay = min(delay*2, maxWebhookBackoff)
+	}
+}
+
+// post sends one request and reports whether a failure is worth retrying:
+// network errors, 429, and 5xx responses are; other statuses are not.
+func (w *Webhook) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for name, value := range w.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.cfg.Token)
+	}
+	if w.cfg.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(w.cfg.Secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("hooks: webhook returned %s", resp.Status)
+}
+
+// SignWebhookBody returns the value of WebhookSignatureHeader for body signed
+// with secret. Receivers recompute it over the raw body and compare the two
+// with hmac.Equal.
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// fail logs the changes that could not be delivered and calls OnFailure once
+// for each of them.
+func (w *Webhook) fail(changes []reporting.Change, err error) {
+	if w.cfg.Logger != nil {
+		if len(changes) == 1 {
+			w.cfg.Logger.Errorf("webhook %s %s: %v", changes[0].Type, changes[0].Path, err)
+		} else {
+			w.cfg.Logger.Errorf("webhook batch of %d changes: %v", len(changes), err)
+		}
+	}
+	if w.cfg.OnFailure != nil {
+		for range changes {
+			w.cfg.OnFailure(err)
+		}
+	}
+}
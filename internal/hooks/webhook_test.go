@@ -0,0 +1,179 @@
+package hooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lowkey/internal/reporting"
+)
+
+func TestWebhookRetriesAndSendsToken(t *testing.T) {
+	var attempts atomic.Int32
+	var mu sync.Mutex
+	var got []webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if attempts.Add(1) <= 2 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		mu.Lock()
+		got = append(got, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var failures atomic.Int32
+	hook, err := NewWebhook(WebhookConfig{
+		URL:       server.URL,
+		Token:     "secret",
+		Backoff:   time.Millisecond,
+		OnFailure: func(error) { failures.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	hook.Notify(reporting.Change{Path: "/w/a.txt", Type: "MODIFY", Size: 4})
+	hook.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0].Path != "/w/a.txt" || got[0].Type != "MODIFY" || got[0].Size != 4 {
+		t.Fatalf("expected one delivered change after retries, got %+v", got)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	if n := failures.Load(); n != 0 {
+		t.Fatalf("expected no failures, got %d", n)
+	}
+}
+
+func TestWebhookCountsRejectedAndDroppedChanges(t *testing.T) {
+	release := make(chan struct{})
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-release
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	var failures atomic.Int32
+	hook, err := NewWebhook(WebhookConfig{
+		URL:       server.URL,
+		QueueSize: 1,
+		Backoff:   time.Millisecond,
+		OnFailure: func(error) { failures.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+
+	// The first change is in flight, the second fills the queue, and the
+	// third is dropped without blocking.
+	hook.Notify(reporting.Change{Path: "/w/a.txt", Type: "CREATE"})
+	deadline := time.Now().Add(time.Second)
+	for attempts.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	hook.Notify(reporting.Change{Path: "/w/b.txt", Type: "CREATE"})
+	hook.Notify(reporting.Change{Path: "/w/c.txt", Type: "CREATE"})
+	if n := failures.Load(); n != 1 {
+		t.Fatalf("expected the overflowing change to be dropped, got %d failures", n)
+	}
+
+	close(release)
+	hook.Close()
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("expected client errors not to be retried, got %d attempts", n)
+	}
+	if n := failures.Load(); n != 3 {
+		t.Fatalf("expected 3 failures, got %d", n)
+	}
+}
+
+func TestWebhookBatchesSignedChanges(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		if got := r.Header.Get(WebhookSignatureHeader); got != SignWebhookBody("s3cret", body) {
+			t.Errorf("expected the body to be signed, got signature %q", got)
+		}
+		if got := r.Header.Get("X-Relay"); got != "ci" {
+			t.Errorf("expected the configured header, got %q", got)
+		}
+		var batch []webhookPayload
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Errorf("decode batch: %v", err)
+		}
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	hook, err := NewWebhook(WebhookConfig{
+		URL:           server.URL,
+		Secret:        "s3cret",
+		Headers:       map[string]string{"X-Relay": "ci"},
+		BatchSize:     2,
+		BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		hook.Notify(reporting.Change{Path: "/w/" + name, Type: "CREATE"})
+	}
+	// The full batch of two goes out at once; the third change waits for
+	// the interval and is flushed by Close.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(batches)
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	hook.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 || batches[1][0].Path != "/w/c" {
+		t.Fatalf("expected batches of 2 and 1 changes, got %+v", batches)
+	}
+}
+
+func TestSignWebhookBodyMatchesHMAC(t *testing.T) {
+	// Reference value from `printf '[]' | openssl dgst -sha256 -hmac key`.
+	want := "sha256=8cf3d584bac42070e44f249a550910bd0077d18a9cc62a54c59c6758458bf01a"
+	if got := SignWebhookBody("key", []byte("[]")); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestNewWebhookRejectsInvalidURL(t *testing.T) {
+	if _, err := NewWebhook(WebhookConfig{URL: "ftp://example.com"}); err == nil {
+		t.Fatalf("expected non-http URL to be rejected")
+	}
+}
@@ -11,10 +11,16 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"lowkey/internal/clock"
 )
 
+// archiveTimeLayout is the timestamp suffix appended to rotated archives.
+const archiveTimeLayout = "20060102-150405"
+
 // Rotator handles log file rotation based on size and the number of backup
 // files. It ensures that log files do not grow indefinitely and that a
 // configurable amount of log history is preserved. It is safe for concurrent
@@ -25,8 +31,9 @@ type Rotator struct {
 	maxSize    int64
 	maxBackups int
 
-	file *os.File
-	mux  sync.Mutex
+	file  *os.File
+	clock clock.Clock
+	mux   sync.Mutex
 }
 
 // NewRotator creates a new log rotator. It takes the directory and base name
@@ -50,7 +57,7 @@ func NewRotator(dir, baseName string, maxSize int64, maxBackups int) (*Rotator,
 		return nil, fmt.Errorf("logging: create dir: %w", err)
 	}
 
-	rotator := &Rotator{dir: dir, baseName: baseName, maxSize: maxSize, maxBackups: maxBackups}
+	rotator := &Rotator{dir: dir, baseName: baseName, maxSize: maxSize, maxBackups: maxBackups, clock: clock.Real{}}
 	if err := rotator.openFile(); err != nil {
 		return nil, err
 	}
@@ -96,7 +103,7 @@ func (r *Rotator) rotate() error {
 		r.file = nil
 	}
 
-	timestamp := time.Now().Format("20060102-150405")
+	timestamp := r.clock.Now().Format(archiveTimeLayout)
 	archivedName := fmt.Sprintf("%s.%s", r.baseName, timestamp)
 	oldPath := filepath.Join(r.dir, r.baseName)
 	newPath := filepath.Join(r.dir, archivedName)
@@ -116,6 +123,28 @@ func (r *Rotator) rotate() error {
 	return r.openFile()
 }
 
+// ArchiveTime extracts the rotation time from the name of an archive produced
+// for baseName (for example `lowkey.log.20240102-150405`). It reports false
+// for the active log and for unrelated files.
+func ArchiveTime(baseName, path string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(filepath.Base(path), baseName+".")
+	if !ok {
+		return time.Time{}, false
+	}
+	ts, err := time.ParseInLocation(archiveTimeLayout, suffix, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// SetClock replaces the clock used to timestamp rotated archives.
+func (r *Rotator) SetClock(c clock.Clock) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.clock = clock.OrReal(c)
+}
+
 // Path returns the full path to the active log file.
 func (r *Rotator) Path() string {
 	return filepath.Join(r.dir, r.baseName)
@@ -0,0 +1,22 @@
+package logging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveTimeParsesRotatedNames(t *testing.T) {
+	ts, ok := ArchiveTime("lowkey.log", "/var/state/lowkey.log.20240102-150405")
+	if !ok {
+		t.Fatalf("expected archive name to parse")
+	}
+	if want := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local); !ts.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, ts)
+	}
+
+	for _, name := range []string{"lowkey.log", "lowkey.log.bak", "other.log.20240102-150405"} {
+		if _, ok := ArchiveTime("lowkey.log", name); ok {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+}
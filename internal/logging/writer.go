@@ -8,14 +8,22 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// lineTimeLayout matches the timestamp prefix written by log.LstdFlags.
+const lineTimeLayout = "2006/01/02 15:04:05"
+
 // Logger provides a simple, structured logging interface. It wraps the standard
 // `log.Logger` to offer leveled logging methods (e.g., Info, Error) with a
 // consistent format.
 type Logger struct {
-	base *log.Logger
+	base  *log.Logger
+	debug atomic.Bool
 }
 
 // New constructs a new Logger that writes to the provided rotator. It sets up a
@@ -24,6 +32,32 @@ func New(rotator *Rotator) *Logger {
 	return &Logger{base: NewLogger(rotator)}
 }
 
+// NewWriter constructs a Logger that writes lines in the same format to w,
+// such as os.Stderr for foreground diagnostics.
+func NewWriter(w io.Writer) *Logger {
+	return &Logger{base: log.New(w, "", log.LstdFlags|log.LUTC)}
+}
+
+// SetDebug enables or disables DEBUG messages, which are dropped by default.
+func (l *Logger) SetDebug(enabled bool) {
+	l.debug.Store(enabled)
+}
+
+// DebugEnabled reports whether DEBUG messages are written. Callers can check
+// it to skip building expensive diagnostics.
+func (l *Logger) DebugEnabled() bool {
+	return l != nil && l.debug.Load()
+}
+
+// Debugf logs a formatted diagnostic message prefixed with "DEBUG" when debug
+// logging is enabled.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !l.DebugEnabled() {
+		return
+	}
+	l.base.Println("DEBUG", fmt.Sprintf(format, args...))
+}
+
 // Info logs an informational message. The message is prefixed with "INFO".
 func (l *Logger) Info(msg string) {
 	l.base.Println("INFO", msg)
@@ -44,3 +78,31 @@ func (l *Logger) Error(err error, msg string) {
 func (l *Logger) Errorf(format string, args ...interface{}) {
 	l.base.Println("ERROR", fmt.Sprintf(format, args...))
 }
+
+// Entry is a single line written by a Logger, split into its timestamp, level,
+// and message.
+type Entry struct {
+	Time    time.Time `json:"ts"`
+	Level   string    `json:"level"`
+	Message string    `json:"msg"`
+}
+
+// ParseLine splits a line produced by a Logger (standard `log` flags in UTC
+// followed by a level keyword) into an Entry. It reports false when the line
+// does not follow that format.
+func ParseLine(line string) (Entry, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) < len(lineTimeLayout)+2 {
+		return Entry{}, false
+	}
+	ts, err := time.Parse(lineTimeLayout, line[:len(lineTimeLayout)])
+	if err != nil {
+		return Entry{}, false
+	}
+	rest := strings.TrimPrefix(line[len(lineTimeLayout):], " ")
+	level, msg, _ := strings.Cut(rest, " ")
+	if level == "" || level[0] < 'A' || level[0] > 'Z' || strings.ToUpper(level) != level {
+		return Entry{}, false
+	}
+	return Entry{Time: ts.UTC(), Level: level, Message: msg}, true
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	entry, ok := ParseLine("2025/10/15 08:30:00 ERROR safety scan error: boom")
+	if !ok {
+		t.Fatalf("expected line to parse")
+	}
+	want := time.Date(2025, 10, 15, 8, 30, 0, 0, time.UTC)
+	if !entry.Time.Equal(want) {
+		t.Fatalf("unexpected timestamp: %s", entry.Time)
+	}
+	if entry.Level != "ERROR" || entry.Message != "safety scan error: boom" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestParseLineRejectsUnstructuredText(t *testing.T) {
+	for _, line := range []string{"", "panic: runtime error", `{"level":"info"}`, "2025/10/15 08:30:00 lowercase message"} {
+		if _, ok := ParseLine(line); ok {
+			t.Fatalf("expected %q not to parse", line)
+		}
+	}
+}
+
+func TestDebugfIsOffByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWriter(&buf)
+	logger.Debugf("hidden %d", 1)
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output before SetDebug, got %q", buf.String())
+	}
+
+	logger.SetDebug(true)
+	logger.Debugf("shown %d", 2)
+	entry, ok := ParseLine(buf.String())
+	if !ok || entry.Level != "DEBUG" || entry.Message != "shown 2" {
+		t.Fatalf("unexpected debug line %q", buf.String())
+	}
+}
@@ -5,27 +5,60 @@ package logs
 
 import (
 	"bufio"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// LogEntry represents a parsed log entry from a .lowlog file
+// LogEntry represents a parsed log entry from a .lowlog file. Its JSON form
+// omits the raw line.
 type LogEntry struct {
-	Timestamp time.Time
-	Type      string // NEW, MODIFIED, DELETED
-	Path      string
-	Details   string // Size information or other details
-	RawLine   string
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"` // NEW, MODIFIED, DELETED
+	Path      string    `json:"path"`
+	Details   string    `json:"details,omitempty"` // Size information or other details
+	RawLine   string    `json:"-"`
+	// Size is the size of a NEW file and SizeDelta the size change of a
+	// MODIFIED one, in bytes, parsed from Details. Binary and Repeated
+	// reflect its "[binary]" and "(repeated N times)" annotations.
+	Size      *int64 `json:"size,omitempty"`
+	SizeDelta *int64 `json:"size_delta,omitempty"`
+	Binary    bool   `json:"binary,omitempty"`
+	Repeated  int    `json:"repeated,omitempty"`
+	// Directory is the watched directory whose log holds the entry. The
+	// reader leaves it empty; commands reading several logs set it.
+	Directory string `json:"directory,omitempty"`
 }
 
+// BootType is the entry type of the marker the watch logger writes each time
+// watching starts.
+const BootType = "BOOT"
+
+// RootLostType and RootRestoredType are the entry types of the markers written
+// when a watched directory disappears and reappears.
+const (
+	RootLostType     = "ROOT_LOST"
+	RootRestoredType = "ROOT_RESTORED"
+)
+
+// DefaultChangeBucket is the window within which repeated changes to one
+// file count as a single distinct change in Stats.
+const DefaultChangeBucket = time.Minute
+
 // Reader provides methods for reading and analyzing .lowlog files
 type Reader struct {
-	logDir string
+	logDir    string
+	sinceBoot bool
+	bucket    time.Duration
+	depth     int
 }
 
 // NewReader creates a new log reader for the specified .lowlog directory
@@ -33,102 +66,317 @@ func NewReader(logDir string) *Reader {
 	return &Reader{logDir: logDir}
 }
 
+// SetSinceBoot restricts every read to the entries written after the most
+// recent BOOT marker, i.e. the activity since watching last (re)started. Logs
+// without a BOOT marker are read in full.
+func (r *Reader) SetSinceBoot(enabled bool) {
+	r.sinceBoot = enabled
+}
+
+// SetChangeBucket sets the window GetStats uses to count distinct changes:
+// all changes to one file within the same window count once. Zero or less
+// restores DefaultChangeBucket.
+func (r *Reader) SetChangeBucket(d time.Duration) {
+	r.bucket = d
+}
+
+// SetDirectoryDepth sets how GetStats rolls changes up in ByDirectory: zero
+// or less counts each change under its file's parent directory, and a
+// positive depth under that directory's ancestor at most depth levels below
+// the watched root.
+func (r *Reader) SetDirectoryDepth(depth int) {
+	r.depth = depth
+}
+
 // ReadAll reads all log entries from all .log files in the directory,
 // optionally filtering by a grep pattern. Empty lines are excluded.
 func (r *Reader) ReadAll(grepPattern string) ([]LogEntry, error) {
-	logFiles, err := r.listLogFiles()
+	lines, err := r.ReadLines(grepPattern)
 	if err != nil {
 		return nil, err
 	}
 
-	var pattern *regexp.Regexp
-	if grepPattern != "" {
-		pattern, err = regexp.Compile("(?i)" + grepPattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid grep pattern: %w", err)
-		}
-	}
-
-	entries := make([]LogEntry, 0)
-	for _, logFile := range logFiles {
-		fileEntries, err := r.readFile(logFile, pattern)
-		if err != nil {
-			return nil, err
+	entries := make([]LogEntry, 0, len(lines))
+	for _, line := range lines {
+		if entry := parseLogLine(line); entry != nil {
+			entries = append(entries, *entry)
 		}
-		entries = append(entries, fileEntries...)
 	}
-
 	return entries, nil
 }
 
 // ReadLines reads all log lines (including raw formatting) from all files,
 // optionally filtering by a grep pattern. This preserves the original format.
 func (r *Reader) ReadLines(grepPattern string) ([]string, error) {
-	logFiles, err := r.listLogFiles()
+	pattern, err := compileGrepPattern(grepPattern)
 	if err != nil {
 		return nil, err
 	}
 
-	var pattern *regexp.Regexp
-	if grepPattern != "" {
-		pattern, err = regexp.Compile("(?i)" + grepPattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid grep pattern: %w", err)
+	all, err := r.readLines()
+	if err != nil {
+		return nil, err
+	}
+	if r.sinceBoot {
+		if index := lastBootIndex(all); index >= 0 {
+			all = all[index+1:]
 		}
 	}
+	if pattern == nil {
+		return all, nil
+	}
 
-	lines := make([]string, 0)
-	for _, logFile := range logFiles {
-		file, err := os.Open(logFile)
+	lines := make([]string, 0, len(all))
+	for _, line := range all {
+		if pattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// HeadLines returns the first n lines ReadLines would return. Without
+// SetSinceBoot it stops reading at the nth match, so the rest of the logs is
+// never loaded.
+func (r *Reader) HeadLines(grepPattern string, n int) ([]string, error) {
+	if r.sinceBoot {
+		lines, err := r.ReadLines(grepPattern)
+		if len(lines) > n {
+			lines = lines[:n]
+		}
+		return lines, err
+	}
+	pattern, err := compileGrepPattern(grepPattern)
+	if err != nil {
+		return nil, err
+	}
+	files, err := r.listLogFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, min(n, 1024))
+	for _, file := range files {
+		if len(lines) >= n {
+			break
+		}
+		err := scanFileLines(file, func(line string) bool {
+			if pattern == nil || pattern.MatchString(line) {
+				lines = append(lines, line)
+			}
+			return len(lines) < n
+		})
 		if err != nil {
 			return nil, err
 		}
-		defer file.Close()
+	}
+	return lines, nil
+}
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Skip empty lines
-			if strings.TrimSpace(line) == "" {
-				continue
+// TailLines returns the last n lines ReadLines would return. It reads the log
+// files newest first and stops at the file holding the nth match from the
+// end, or at the last BOOT marker with SetSinceBoot.
+func (r *Reader) TailLines(grepPattern string, n int) ([]string, error) {
+	pattern, err := compileGrepPattern(grepPattern)
+	if err != nil {
+		return nil, err
+	}
+	files, err := r.listLogFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, min(n, 1024))
+scan:
+	for i := len(files) - 1; i >= 0 && len(lines) < n; i-- {
+		fileLines, err := readFileLines(files[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := len(fileLines) - 1; j >= 0 && len(lines) < n; j-- {
+			line := fileLines[j]
+			if r.sinceBoot {
+				if entry := parseLogLine(line); entry != nil && entry.Type == BootType {
+					break scan
+				}
 			}
-			// Apply pattern filter if specified
-			if pattern != nil && !pattern.MatchString(line) {
-				continue
+			if pattern == nil || pattern.MatchString(line) {
+				lines = append(lines, line)
 			}
-			lines = append(lines, line)
 		}
+	}
+	slices.Reverse(lines)
+	return lines, nil
+}
 
-		if err := scanner.Err(); err != nil {
-			return nil, err
+// compileGrepPattern compiles a case-insensitive grep pattern; an empty
+// pattern yields nil, which matches every line.
+func compileGrepPattern(grepPattern string) (*regexp.Regexp, error) {
+	if grepPattern == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile("(?i)" + grepPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern: %w", err)
+	}
+	return pattern, nil
+}
+
+// ContextLine is a line returned by ReadContext. Match reports whether it
+// matched the pattern or was included as surrounding context.
+type ContextLine struct {
+	Text  string
+	Match bool
+}
+
+// ReadContext returns the lines matching grepPattern together with up to
+// before and after lines of surrounding context, like grep -B/-A. Context
+// never crosses from one log file into another. Matches whose windows overlap
+// or touch are merged into one block; separate blocks are returned in order.
+func (r *Reader) ReadContext(grepPattern string, before, after int) ([][]ContextLine, error) {
+	pattern, err := compileGrepPattern(grepPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	all, files, err := r.readLinesByFile()
+	if err != nil {
+		return nil, err
+	}
+	if r.sinceBoot {
+		if index := lastBootIndex(all); index >= 0 {
+			all, files = all[index+1:], files[index+1:]
 		}
 	}
 
-	return lines, nil
+	var blocks [][]ContextLine
+	var block []ContextLine
+	end := -1 // index one past the last line in block
+	for i, line := range all {
+		if pattern != nil && !pattern.MatchString(line) {
+			continue
+		}
+		start := i
+		for start > 0 && i-start < before && files[start-1] == files[i] {
+			start--
+		}
+		if block != nil && (start > end || files[end-1] != files[i]) {
+			blocks = append(blocks, block)
+			block = nil
+		}
+		if block != nil {
+			start = end
+		}
+		for j := start; j < i; j++ {
+			block = append(block, ContextLine{Text: all[j]})
+		}
+		if i < end {
+			// Already added as trailing context of the previous match.
+			block[len(block)-(end-i)].Match = true
+		} else {
+			block = append(block, ContextLine{Text: line, Match: true})
+			end = i + 1
+		}
+		for end < len(all) && end-i <= after && files[end] == files[i] {
+			block = append(block, ContextLine{Text: all[end]})
+			end++
+		}
+	}
+	if block != nil {
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// LastBoot returns the time of the most recent BOOT marker and reports false
+// when the logs contain none.
+func (r *Reader) LastBoot() (time.Time, bool, error) {
+	lines, err := r.readLines()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	index := lastBootIndex(lines)
+	if index < 0 {
+		return time.Time{}, false, nil
+	}
+	return parseLogLine(lines[index]).Timestamp, true, nil
+}
+
+// lastBootIndex returns the index of the last BOOT marker in lines, or -1.
+func lastBootIndex(lines []string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if entry := parseLogLine(lines[i]); entry != nil && entry.Type == BootType {
+			return i
+		}
+	}
+	return -1
 }
 
 // Stats provides statistics about the logged events
 type Stats struct {
-	TotalEvents     int
-	NewCount        int
-	ModifiedCount   int
-	DeletedCount    int
-	MostActiveFiles []FileActivity
-	ActivityByHour  []HourActivity
-	FirstEvent      *time.Time
-	LastEvent       *time.Time
+	TotalEvents     int            `json:"total_events"`
+	NewCount        int            `json:"new_count"`
+	ModifiedCount   int            `json:"modified_count"`
+	DeletedCount    int            `json:"deleted_count"`
+	MostActiveFiles []FileActivity `json:"most_active_files"`
+	ActivityByHour  []HourActivity `json:"activity_by_hour"`
+	FirstEvent      *time.Time     `json:"first_event"`
+	LastEvent       *time.Time     `json:"last_event"`
+	// FileCount is the number of files with at least one event.
+	FileCount int `json:"file_count"`
+	// DistinctChanges counts the (file, bucket) pairs with at least one
+	// event, so a file saved many times within one bucket counts once. See
+	// Reader.SetChangeBucket.
+	DistinctChanges int `json:"distinct_changes"`
+	// ByExtension counts events per lower-cased file extension, such as
+	// ".go"; files without one count under NoExtension.
+	ByExtension map[string]int `json:"by_extension"`
+	// ByDirectory counts events per directory holding the changed file,
+	// relative to the watched root ("." for the root itself). See
+	// Reader.SetDirectoryDepth.
+	ByDirectory map[string]int `json:"by_directory"`
+}
+
+// NoExtension is the ByExtension key of files without an extension.
+const NoExtension = "(none)"
+
+// directoryOf returns the directory path counts under in ByDirectory: its
+// parent, cut to at most depth components when depth is positive.
+func directoryOf(path string, depth int) string {
+	dir := filepath.Dir(path)
+	if depth <= 0 || dir == "." {
+		return dir
+	}
+	parts := strings.Split(dir, string(filepath.Separator))
+	if len(parts) <= depth {
+		return dir
+	}
+	return filepath.Join(parts[:depth]...)
+}
+
+// extensionOf returns the lower-cased extension of path, or NoExtension.
+// A leading dot, as in ".gitignore", does not start an extension.
+func extensionOf(path string) string {
+	base := filepath.Base(path)
+	ext := filepath.Ext(strings.TrimLeft(base, "."))
+	if ext == "" {
+		return NoExtension
+	}
+	return strings.ToLower(ext)
 }
 
 // FileActivity represents change activity for a single file
 type FileActivity struct {
-	Path  string
-	Count int
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+	// Distinct is the number of buckets in which the file changed.
+	Distinct int `json:"distinct"`
 }
 
 // HourActivity represents the number of events in a specific hour
 type HourActivity struct {
-	Hour  string // Format: "2006-01-02 15"
-	Count int
+	Hour  string `json:"hour"` // Format: "2006-01-02 15"
+	Count int    `json:"count"`
 }
 
 // GetStats analyzes log entries and returns statistics
@@ -137,6 +385,10 @@ func (r *Reader) GetStats() (*Stats, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Markers are not file activity.
+	entries = slices.DeleteFunc(entries, func(entry LogEntry) bool {
+		return entry.Type == BootType || entry.Type == RootLostType || entry.Type == RootRestoredType
+	})
 
 	if len(entries) == 0 {
 		return &Stats{}, nil
@@ -144,11 +396,24 @@ func (r *Reader) GetStats() (*Stats, error) {
 
 	stats := &Stats{
 		TotalEvents: len(entries),
+		ByExtension: make(map[string]int),
+		ByDirectory: make(map[string]int),
+	}
+
+	bucket := r.bucket
+	if bucket <= 0 {
+		bucket = DefaultChangeBucket
 	}
 
 	// Count by type
 	fileCounts := make(map[string]int)
 	hourCounts := make(map[string]int)
+	type changeBucket struct {
+		path  string
+		start time.Time
+	}
+	buckets := make(map[changeBucket]struct{})
+	fileBuckets := make(map[string]int)
 
 	for _, entry := range entries {
 		switch entry.Type {
@@ -162,6 +427,13 @@ func (r *Reader) GetStats() (*Stats, error) {
 
 		// Track file activity
 		fileCounts[entry.Path]++
+		stats.ByExtension[extensionOf(entry.Path)]++
+		stats.ByDirectory[directoryOf(entry.Path, r.depth)]++
+		key := changeBucket{entry.Path, entry.Timestamp.Truncate(bucket)}
+		if _, seen := buckets[key]; !seen {
+			buckets[key] = struct{}{}
+			fileBuckets[entry.Path]++
+		}
 
 		// Track hourly activity
 		hour := entry.Timestamp.Format("2006-01-02 15")
@@ -178,13 +450,23 @@ func (r *Reader) GetStats() (*Stats, error) {
 		}
 	}
 
+	stats.DistinctChanges = len(buckets)
+	stats.FileCount = len(fileCounts)
+
 	// Build most active files (top 5)
 	stats.MostActiveFiles = make([]FileActivity, 0, len(fileCounts))
 	for path, count := range fileCounts {
-		stats.MostActiveFiles = append(stats.MostActiveFiles, FileActivity{Path: path, Count: count})
+		stats.MostActiveFiles = append(stats.MostActiveFiles, FileActivity{Path: path, Count: count, Distinct: fileBuckets[path]})
 	}
 	sort.Slice(stats.MostActiveFiles, func(i, j int) bool {
-		return stats.MostActiveFiles[i].Count > stats.MostActiveFiles[j].Count
+		a, b := stats.MostActiveFiles[i], stats.MostActiveFiles[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Distinct != b.Distinct {
+			return a.Distinct > b.Distinct
+		}
+		return a.Path < b.Path
 	})
 	if len(stats.MostActiveFiles) > 5 {
 		stats.MostActiveFiles = stats.MostActiveFiles[:5]
@@ -205,78 +487,231 @@ func (r *Reader) GetStats() (*Stats, error) {
 	return stats, nil
 }
 
-// listLogFiles returns all .log files in the directory, sorted by name (date)
+// dateLogPattern matches the `YYYY-MM-DD.log` names written by the watch
+// logger, and the `YYYY-MM-DD.log.gz` names of their compressed archives.
+var dateLogPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.log(\.gz)?$`)
+
+// gzipSuffix marks a log file compressed with gzip.
+const gzipSuffix = ".gz"
+
+// DatedLogFile describes a daily change log discovered in a .lowlog
+// directory.
+type DatedLogFile struct {
+	Path string
+	Date time.Time
+	Size int64
+}
+
+// ParseLogDate extracts the date from a `YYYY-MM-DD.log` or
+// `YYYY-MM-DD.log.gz` file name. It reports false for any other name so that
+// unrelated files are never mistaken for change logs.
+func ParseLogDate(name string) (time.Time, bool) {
+	base := filepath.Base(name)
+	if !dateLogPattern.MatchString(base) {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(strings.TrimSuffix(base, gzipSuffix), ".log"), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// DatedLogFiles returns the daily change logs in the directory, oldest first.
+// Only files named strictly `YYYY-MM-DD.log`, or `YYYY-MM-DD.log.gz` when
+// compressed, are included.
+func (r *Reader) DatedLogFiles() ([]DatedLogFile, error) {
+	files, err := r.listLogFiles()
+	if err != nil {
+		return nil, err
+	}
+	dated := make([]DatedLogFile, 0, len(files))
+	for _, path := range files {
+		date, ok := ParseLogDate(path)
+		if !ok {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		dated = append(dated, DatedLogFile{Path: path, Date: date, Size: info.Size()})
+	}
+	return dated, nil
+}
+
+// LogFiles returns the uncompressed .log files in the directory, the ones
+// the watch logger may still append to, sorted by name so the most recent
+// dated file is last.
+func (r *Reader) LogFiles() ([]string, error) {
+	files, err := r.listLogFiles()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(files, func(path string) bool {
+		return strings.HasSuffix(path, gzipSuffix)
+	}), nil
+}
+
+// listLogFiles returns all .log files in the directory and their .log.gz
+// archives, sorted by name (date). An archive next to the plain file it was
+// compressed from is skipped, since it may still be being written.
 func (r *Reader) listLogFiles() ([]string, error) {
-	pattern := filepath.Join(r.logDir, "*.log")
-	files, err := filepath.Glob(pattern)
+	files, err := filepath.Glob(filepath.Join(r.logDir, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	archives, err := filepath.Glob(filepath.Join(r.logDir, "*.log"+gzipSuffix))
 	if err != nil {
 		return nil, err
 	}
+	for _, archive := range archives {
+		if !slices.Contains(files, strings.TrimSuffix(archive, gzipSuffix)) {
+			files = append(files, archive)
+		}
+	}
 	sort.Strings(files)
 	return files, nil
 }
 
-// readFile reads and parses a single log file
-func (r *Reader) readFile(path string, pattern *regexp.Regexp) ([]LogEntry, error) {
-	file, err := os.Open(path)
+// readLines returns the non-empty lines of every log file, oldest file first.
+func (r *Reader) readLines() ([]string, error) {
+	lines, _, err := r.readLinesByFile()
+	return lines, err
+}
+
+// readLinesByFile is readLines that also reports, for each line, the index of
+// the log file it was read from.
+func (r *Reader) readLinesByFile() ([]string, []int, error) {
+	logFiles, err := r.listLogFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := make([]string, 0)
+	files := make([]int, 0)
+	for index, logFile := range logFiles {
+		fileLines, err := readFileLines(logFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		lines = append(lines, fileLines...)
+		for range fileLines {
+			files = append(files, index)
+		}
+	}
+	return lines, files, nil
+}
+
+// readFileLines reads the non-empty lines of a single log file, decompressing
+// it when its name ends in .gz.
+func readFileLines(path string) ([]string, error) {
+	lines := make([]string, 0)
+	err := scanFileLines(path, func(line string) bool {
+		lines = append(lines, line)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
+	return lines, nil
+}
+
+// scanFileLines passes the non-empty lines of a single log file to fn, in
+// order, until fn returns false. A file whose name ends in .gz is
+// decompressed.
+func scanFileLines(path string, fn func(line string) bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
-	entries := make([]LogEntry, 0)
-	scanner := bufio.NewScanner(file)
+	var content io.Reader = file
+	if strings.HasSuffix(path, gzipSuffix) {
+		decompressed, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("logs: read %s: %w", path, err)
+		}
+		defer decompressed.Close()
+		content = decompressed
+	}
 
+	scanner := bufio.NewScanner(content)
 	for scanner.Scan() {
 		line := scanner.Text()
-
 		// Skip empty lines
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
-
-		// Apply pattern filter if specified
-		if pattern != nil && !pattern.MatchString(line) {
-			continue
-		}
-
-		entry := parseLogLine(line)
-		if entry != nil {
-			entries = append(entries, *entry)
+		if !fn(line) {
+			return nil
 		}
 	}
+	return scanner.Err()
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// ParseEntry parses a single log line as written by the watch logger. It
+// reports false for lines that do not follow the format, which ReadAll skips.
+func ParseEntry(line string) (LogEntry, bool) {
+	if entry := parseLogLine(line); entry != nil {
+		return *entry, true
 	}
-
-	return entries, nil
+	return LogEntry{}, false
 }
 
 // parseLogLine parses a log line into a LogEntry
 // Expected format: [2006-01-02 15:04:05] [TYPE] path details
+// The watch logger writes local time, so timestamps are read in time.Local.
 func parseLogLine(line string) *LogEntry {
-	// Regular expression to parse the log format
-	// [timestamp] [TYPE] path details
-	re := regexp.MustCompile(`^\[([^\]]+)\]\s+\[([^\]]+)\]\s+(\S+)\s*(.*)$`)
-	matches := re.FindStringSubmatch(line)
+	matches := logLinePattern.FindStringSubmatch(line)
 
 	if len(matches) < 4 {
 		// Line doesn't match expected format, skip it
 		return nil
 	}
 
-	timestamp, err := time.Parse("2006-01-02 15:04:05", matches[1])
+	timestamp, err := time.ParseInLocation("2006-01-02 15:04:05", matches[1], time.Local)
 	if err != nil {
 		// Invalid timestamp, skip
 		return nil
 	}
 
-	return &LogEntry{
+	entry := &LogEntry{
 		Timestamp: timestamp,
 		Type:      matches[2],
 		Path:      matches[3],
 		Details:   matches[4],
 		RawLine:   line,
 	}
+	parseDetails(entry)
+	return entry
+}
+
+var (
+	// logLinePattern matches the log format: [timestamp] [TYPE] path details
+	logLinePattern = regexp.MustCompile(`^\[([^\]]+)\]\s+\[([^\]]+)\]\s+(\S+)\s*(.*)$`)
+	// sizeDetailPattern and repeatDetailPattern match the annotations the
+	// watch logger appends: "(12 bytes)" or "(+3 bytes)", "[binary]", and
+	// "(repeated 4 times)".
+	sizeDetailPattern   = regexp.MustCompile(`^\(([+-]?\d+) bytes\)`)
+	repeatDetailPattern = regexp.MustCompile(`\(repeated (\d+) times?\)`)
+)
+
+// parseDetails fills the structured fields of entry from its Details.
+func parseDetails(entry *LogEntry) {
+	if match := sizeDetailPattern.FindStringSubmatch(entry.Details); match != nil {
+		if n, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			switch entry.Type {
+			case "NEW":
+				entry.Size = &n
+			case "MODIFIED":
+				entry.SizeDelta = &n
+			}
+		}
+	}
+	entry.Binary = strings.Contains(entry.Details, "[binary]")
+	if match := repeatDetailPattern.FindStringSubmatch(entry.Details); match != nil {
+		entry.Repeated, _ = strconv.Atoi(match[1])
+	}
 }
@@ -0,0 +1,365 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatedLogFilesMatchesOnlyDailyLogs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2024-01-02.log", "2024-01-01.log", "notes.log", "2024-1-3.log", "2024-01-04.log.bak"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	files, err := NewReader(dir).DatedLogFiles()
+	if err != nil {
+		t.Fatalf("DatedLogFiles: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 dated logs, got %d: %+v", len(files), files)
+	}
+	if filepath.Base(files[0].Path) != "2024-01-01.log" || filepath.Base(files[1].Path) != "2024-01-02.log" {
+		t.Fatalf("unexpected order: %s, %s", files[0].Path, files[1].Path)
+	}
+	if files[0].Date.Day() != 1 || files[0].Size != 2 {
+		t.Fatalf("unexpected metadata: %+v", files[0])
+	}
+}
+
+func TestReaderReadsGzippedArchivesAlongsidePlainLogs(t *testing.T) {
+	dir := t.TempDir()
+	writeGzip := func(name, data string) {
+		t.Helper()
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write([]byte(data)); err != nil {
+			t.Fatalf("compress %s: %v", name, err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("compress %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	writeGzip("2024-01-01.log.gz", "[2024-01-01 09:00:00] [NEW] old.txt (1 bytes)\n")
+	writeGzip("2024-01-02.log.gz", "[2024-01-02 09:00:00] [NEW] partial.txt (1 bytes)\n")
+	for name, data := range map[string]string{
+		"2024-01-02.log": "[2024-01-02 09:00:00] [NEW] mid.txt (1 bytes)\n",
+		"2024-01-03.log": "[2024-01-03 09:00:00] [MODIFIED] old.txt (+1 bytes)\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	reader := NewReader(dir)
+	entries, err := reader.ReadAll("")
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	if want := []string{"old.txt", "mid.txt", "old.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected %v in date order, skipping the archive shadowed by its plain log, got %v", want, paths)
+	}
+
+	dated, err := reader.DatedLogFiles()
+	if err != nil || len(dated) != 3 || dated[0].Date.Day() != 1 {
+		t.Fatalf("expected the archive among 3 dated logs, got %+v (err %v)", dated, err)
+	}
+	plain, err := reader.LogFiles()
+	if err != nil || len(plain) != 2 || strings.HasSuffix(plain[0], ".gz") {
+		t.Fatalf("expected LogFiles to list only the 2 plain logs, got %v (err %v)", plain, err)
+	}
+}
+
+func TestHeadAndTailLinesMatchReadLines(t *testing.T) {
+	dir := t.TempDir()
+	day1 := "[2024-01-01 09:00:00] [NEW] a.txt (1 bytes)\n" +
+		"[2024-01-01 09:01:00] [NEW] b.txt (1 bytes)\n" +
+		"[2024-01-01 09:02:00] [MODIFIED] a.txt (+1 bytes)\n"
+	day2 := "[2024-01-02 09:00:00] [BOOT] .\n" +
+		"[2024-01-02 09:01:00] [MODIFIED] a.txt (+2 bytes)\n" +
+		"[2024-01-02 09:02:00] [DELETED] b.txt\n"
+	for name, data := range map[string]string{"2024-01-01.log": day1, "2024-01-02.log": day2} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	reader := NewReader(dir)
+	for _, tc := range []struct {
+		name      string
+		sinceBoot bool
+		pattern   string
+		head      bool
+		n         int
+	}{
+		{name: "head across files", head: true, n: 4},
+		{name: "head filtered", pattern: "a.txt", head: true, n: 2},
+		{name: "head beyond end", head: true, n: 100},
+		{name: "tail across files", n: 4},
+		{name: "tail filtered", pattern: "a.txt", n: 2},
+		{name: "tail since boot stops at boot", sinceBoot: true, n: 10},
+		{name: "head since boot", sinceBoot: true, head: true, n: 1},
+	} {
+		reader.SetSinceBoot(tc.sinceBoot)
+		all, err := reader.ReadLines(tc.pattern)
+		if err != nil {
+			t.Fatalf("%s: ReadLines: %v", tc.name, err)
+		}
+		want := all[max(0, len(all)-tc.n):]
+		read := reader.TailLines
+		if tc.head {
+			want, read = all[:min(tc.n, len(all))], reader.HeadLines
+		}
+		got, err := read(tc.pattern, tc.n)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: expected %q, got %q", tc.name, want, got)
+		}
+	}
+}
+
+func TestParseLogDateRejectsInvalidDates(t *testing.T) {
+	if _, ok := ParseLogDate("2024-13-40.log"); ok {
+		t.Fatalf("expected invalid calendar date to be rejected")
+	}
+}
+
+func TestSinceBootScopesStatsToLastBoot(t *testing.T) {
+	dir := t.TempDir()
+	day1 := "[2024-01-01 09:00:00] [BOOT] .\n" +
+		"[2024-01-01 09:05:00] [NEW] old.txt (3 bytes)\n"
+	day2 := "[2024-01-02 10:00:00] [MODIFIED] old.txt (+1 bytes)\n" +
+		"[2024-01-02 11:00:00] [BOOT] .\n" +
+		"[2024-01-02 11:01:00] [NEW] fresh.txt (2 bytes)\n" +
+		"[2024-01-02 11:02:00] [DELETED] old.txt\n"
+	for name, data := range map[string]string{"2024-01-01.log": day1, "2024-01-02.log": day2} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	reader := NewReader(dir)
+	all, err := reader.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if all.TotalEvents != 4 {
+		t.Fatalf("expected BOOT markers to be excluded from 4 events, got %d", all.TotalEvents)
+	}
+
+	boot, ok, err := reader.LastBoot()
+	if err != nil || !ok || boot.Hour() != 11 || boot.Day() != 2 {
+		t.Fatalf("unexpected last boot %v ok=%v err=%v", boot, ok, err)
+	}
+
+	reader.SetSinceBoot(true)
+	stats, err := reader.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats since boot: %v", err)
+	}
+	if stats.TotalEvents != 2 || stats.NewCount != 1 || stats.DeletedCount != 1 || stats.ModifiedCount != 0 {
+		t.Fatalf("unexpected stats since boot: %+v", stats)
+	}
+	lines, err := reader.ReadLines("fresh")
+	if err != nil || len(lines) != 1 {
+		t.Fatalf("expected one matching line since boot, got %v (err %v)", lines, err)
+	}
+}
+
+func TestStatsCountDistinctChangesPerBucket(t *testing.T) {
+	dir := t.TempDir()
+	data := "[2024-01-01 09:00:05] [MODIFIED] notes.md (+1 bytes)\n" +
+		"[2024-01-01 09:00:20] [MODIFIED] notes.md (+1 bytes)\n" +
+		"[2024-01-01 09:00:50] [MODIFIED] notes.md (+1 bytes)\n" +
+		"[2024-01-01 09:01:10] [MODIFIED] notes.md (+1 bytes)\n" +
+		"[2024-01-01 09:00:30] [NEW] main.go (10 bytes)\n" +
+		"[2024-01-01 09:30:00] [MODIFIED] main.go (+2 bytes)\n"
+	if err := os.WriteFile(filepath.Join(dir, "2024-01-01.log"), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	reader := NewReader(dir)
+	stats, err := reader.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalEvents != 6 || stats.FileCount != 2 || stats.DistinctChanges != 4 {
+		t.Fatalf("expected 6 events across 2 files in 4 change-minutes, got %+v", stats)
+	}
+	if top := stats.MostActiveFiles[0]; top.Path != "notes.md" || top.Count != 4 || top.Distinct != 2 {
+		t.Fatalf("unexpected most active file %+v", top)
+	}
+
+	reader.SetChangeBucket(time.Hour)
+	if stats, err = reader.GetStats(); err != nil || stats.DistinctChanges != 2 || stats.TotalEvents != 6 {
+		t.Fatalf("expected 2 distinct change-hours, got %+v (err %v)", stats, err)
+	}
+}
+
+func TestStatsGroupChangesByExtension(t *testing.T) {
+	dir := t.TempDir()
+	data := "[2024-01-01 09:00:00] [BOOT] .\n" +
+		"[2024-01-01 09:00:01] [NEW] cmd/main.go (10 bytes)\n" +
+		"[2024-01-01 09:00:02] [MODIFIED] cmd/main.go (+1 bytes)\n" +
+		"[2024-01-01 09:00:03] [NEW] assets/Logo.PNG (2 KB)\n" +
+		"[2024-01-01 09:00:04] [NEW] Makefile (1 bytes)\n" +
+		"[2024-01-01 09:00:05] [NEW] .gitignore (1 bytes)\n" +
+		"[2024-01-01 09:00:06] [NEW] .env.local (1 bytes)\n"
+	if err := os.WriteFile(filepath.Join(dir, "2024-01-01.log"), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	stats, err := NewReader(dir).GetStats()
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	want := map[string]int{".go": 2, ".png": 1, ".local": 1, NoExtension: 2}
+	if !reflect.DeepEqual(stats.ByExtension, want) {
+		t.Fatalf("expected %v, got %v", want, stats.ByExtension)
+	}
+}
+
+func TestStatsRollChangesUpToDirectories(t *testing.T) {
+	dir := t.TempDir()
+	data := "[2024-01-01 09:00:01] [NEW] README.md (1 bytes)\n" +
+		"[2024-01-01 09:00:02] [NEW] internal/logs/reader.go (1 bytes)\n" +
+		"[2024-01-01 09:00:03] [NEW] internal/logs/reader_test.go (1 bytes)\n" +
+		"[2024-01-01 09:00:04] [NEW] internal/watcher/sink.go (1 bytes)\n" +
+		"[2024-01-01 09:00:05] [NEW] cmd/main.go (1 bytes)\n"
+	if err := os.WriteFile(filepath.Join(dir, "2024-01-01.log"), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	reader := NewReader(dir)
+	stats, err := reader.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	want := map[string]int{
+		".":                                  1,
+		"cmd":                                1,
+		filepath.Join("internal", "logs"):    2,
+		filepath.Join("internal", "watcher"): 1,
+	}
+	if !reflect.DeepEqual(stats.ByDirectory, want) {
+		t.Fatalf("expected %v, got %v", want, stats.ByDirectory)
+	}
+
+	reader.SetDirectoryDepth(1)
+	if stats, err = reader.GetStats(); err != nil {
+		t.Fatalf("GetStats at depth 1: %v", err)
+	}
+	want = map[string]int{".": 1, "cmd": 1, "internal": 3}
+	if !reflect.DeepEqual(stats.ByDirectory, want) {
+		t.Fatalf("expected %v at depth 1, got %v", want, stats.ByDirectory)
+	}
+}
+
+func TestReadContextMergesWindowsWithinAFile(t *testing.T) {
+	dir := t.TempDir()
+	day1 := "[2024-01-01 09:00:00] [NEW] a.txt (1 bytes)\n" +
+		"[2024-01-01 09:01:00] [NEW] b.txt (1 bytes)\n" +
+		"[2024-01-01 09:02:00] [DELETED] secret.key\n" +
+		"[2024-01-01 09:03:00] [NEW] c.txt (1 bytes)\n" +
+		"[2024-01-01 09:04:00] [MODIFIED] secret.key (+1 bytes)\n" +
+		"[2024-01-01 09:05:00] [NEW] d.txt (1 bytes)\n" +
+		"[2024-01-01 09:06:00] [NEW] e.txt (1 bytes)\n" +
+		"[2024-01-01 09:07:00] [NEW] f.txt (1 bytes)\n" +
+		"[2024-01-01 09:08:00] [NEW] g.txt (1 bytes)\n" +
+		"[2024-01-01 09:09:00] [NEW] secret.key (1 bytes)\n"
+	day2 := "[2024-01-02 10:00:00] [NEW] h.txt (1 bytes)\n"
+	for name, data := range map[string]string{"2024-01-01.log": day1, "2024-01-02.log": day2} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	blocks, err := NewReader(dir).ReadContext("secret", 1, 1)
+	if err != nil {
+		t.Fatalf("ReadContext: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d: %+v", len(blocks), blocks)
+	}
+
+	var first []string
+	for _, line := range blocks[0] {
+		mark := " "
+		if line.Match {
+			mark = "*"
+		}
+		first = append(first, mark+line.Text[len("[2024-01-01 09:0"):len("[2024-01-01 09:00")])
+	}
+	if got, want := strings.Join(first, ","), " 1,*2, 3,*4, 5"; got != want {
+		t.Fatalf("first block = %q, want %q", got, want)
+	}
+
+	// The last match has no trailing context: the next line is in another file.
+	if len(blocks[1]) != 2 || blocks[1][0].Match || !blocks[1][1].Match {
+		t.Fatalf("unexpected second block: %+v", blocks[1])
+	}
+}
+
+func TestLogEntryJSONUsesRFC3339LocalTime(t *testing.T) {
+	entry, ok := ParseEntry("[2024-01-02 15:04:05] [NEW] a.txt (12 B)")
+	if !ok {
+		t.Fatalf("expected the line to parse")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local).Format(time.RFC3339)
+	if got := string(data); got != `{"timestamp":"`+want+`","type":"NEW","path":"a.txt","details":"(12 B)"}` {
+		t.Fatalf("unexpected JSON %s", got)
+	}
+	if _, ok := ParseEntry("not a log line"); ok {
+		t.Fatalf("expected a malformed line to be rejected")
+	}
+}
+
+func TestParseEntryReadsSizeAnnotations(t *testing.T) {
+	cases := []struct {
+		line     string
+		size     *int64
+		delta    *int64
+		binary   bool
+		repeated int
+	}{
+		{line: "[2024-01-02 15:04:05] [NEW] a.txt (12 bytes)", size: ptr(int64(12))},
+		{line: "[2024-01-02 15:04:05] [MODIFIED] a.txt (-3 bytes) [binary]", delta: ptr(int64(-3)), binary: true},
+		{line: "[2024-01-02 15:04:05] [MODIFIED] a.txt (+0 bytes) (repeated 4 times)", delta: ptr(int64(0)), repeated: 4},
+		{line: "[2024-01-02 15:04:05] [DELETED] a.txt"},
+	}
+	for _, tc := range cases {
+		entry, ok := ParseEntry(tc.line)
+		if !ok {
+			t.Fatalf("%s: expected the line to parse", tc.line)
+		}
+		if !reflect.DeepEqual(entry.Size, tc.size) || !reflect.DeepEqual(entry.SizeDelta, tc.delta) {
+			t.Fatalf("%s: unexpected size %v and delta %v", tc.line, entry.Size, entry.SizeDelta)
+		}
+		if entry.Binary != tc.binary || entry.Repeated != tc.repeated {
+			t.Fatalf("%s: unexpected binary %v and repeated %d", tc.line, entry.Binary, entry.Repeated)
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
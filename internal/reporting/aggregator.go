@@ -23,8 +23,22 @@ type Change struct {
 	Size      int64 // Size for new files, or new size for modified files
 	OldSize   int64 // Previous size for modified files (used to calculate delta)
 	SizeDelta int64 // Size change for modified files (positive for growth, negative for shrink)
+	IsBinary  bool  // Set for binary files when binary detection is enabled
+	// Source tells how the change was detected: SourceRealtime or SourceScan.
+	// It is empty for markers.
+	Source string `json:",omitempty"`
 }
 
+// Values of Change.Source.
+const (
+	// SourceRealtime marks a change reported by the filesystem event backend.
+	SourceRealtime = "realtime"
+	// SourceScan marks a change found by a safety scan, typically because
+	// its event was missed. Its timestamp is the file's modification time
+	// when that is plausible, and the time of the scan otherwise.
+	SourceScan = "scan"
+)
+
 // Snapshot provides a detailed summary of recent watcher activity. It includes
 // the total number of changes, details of the last change, and a breakdown of
 // changes per directory.
@@ -32,6 +46,11 @@ type Snapshot struct {
 	Count        int
 	LastChange   *Change
 	PerDirectory map[string]int
+	// Markers counts the synthetic events, such as watcher startups, recorded
+	// with RecordMarker, and LastMarker is the latest of them. They are kept
+	// out of the fields above, which only describe changes to files.
+	Markers    int     `json:",omitempty"`
+	LastMarker *Change `json:",omitempty"`
 }
 
 // Aggregator collects and summarizes file system change events. It maintains a
@@ -62,6 +81,19 @@ func (a *Aggregator) Record(change Change) {
 	a.snapshot.PerDirectory[dir]++
 }
 
+// RecordMarker records a synthetic event that describes the watcher rather
+// than a file, such as a startup or a watched directory disappearing. It only
+// updates Markers and LastMarker, so restarts do not inflate the change
+// counts.
+func (a *Aggregator) RecordMarker(change Change) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.snapshot.Markers++
+	copyChange := change
+	a.snapshot.LastMarker = &copyChange
+}
+
 // Snapshot returns a thread-safe copy of the current aggregate state. This
 // allows other parts of the application to access the summary data without
 // needing to worry about race conditions.
@@ -81,5 +113,9 @@ func (a *Aggregator) Snapshot() Snapshot {
 		changeCopy := *snapshot.LastChange
 		snapshot.LastChange = &changeCopy
 	}
+	if snapshot.LastMarker != nil {
+		markerCopy := *snapshot.LastMarker
+		snapshot.LastMarker = &markerCopy
+	}
 	return snapshot
 }
@@ -0,0 +1,62 @@
+package reporting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SaveSnapshot atomically writes snapshot as JSON to path using a temporary
+// file and rename, so readers in other processes never observe a partial
+// write.
+func SaveSnapshot(path string, snapshot Snapshot) error {
+	if path == "" {
+		return errors.New("reporting: snapshot path is empty")
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("reporting: create snapshot directory %q: %w", dir, err)
+	}
+
+	tempFile, err := os.CreateTemp(dir, "snapshot-*.json")
+	if err != nil {
+		return fmt.Errorf("reporting: create temp snapshot: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+
+	if err := json.NewEncoder(tempFile).Encode(snapshot); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("reporting: encode snapshot: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("reporting: close temp snapshot: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), path); err != nil {
+		return fmt.Errorf("reporting: replace snapshot %q: %w", path, err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot. A missing file yields
+// an empty snapshot and no error.
+func LoadSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Snapshot{PerDirectory: make(map[string]int)}, nil
+		}
+		return Snapshot{}, fmt.Errorf("reporting: read snapshot %q: %w", path, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("reporting: decode snapshot %q: %w", path, err)
+	}
+	if snapshot.PerDirectory == nil {
+		snapshot.PerDirectory = make(map[string]int)
+	}
+	return snapshot, nil
+}
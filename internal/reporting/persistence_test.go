@@ -0,0 +1,32 @@
+package reporting
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	aggregator := NewAggregator()
+	aggregator.Record(Change{Path: "/watch/a.txt", Type: "CREATE", Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)})
+
+	if err := SaveSnapshot(path, aggregator.Snapshot()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Count != 1 || loaded.LastChange == nil || loaded.LastChange.Path != "/watch/a.txt" {
+		t.Fatalf("unexpected snapshot: %+v", loaded)
+	}
+	if loaded.PerDirectory["/watch"] != 1 {
+		t.Fatalf("expected per-directory count to round-trip, got %+v", loaded.PerDirectory)
+	}
+
+	missing, err := LoadSnapshot(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || missing.Count != 0 {
+		t.Fatalf("expected empty snapshot for missing file (err=%v)", err)
+	}
+}
@@ -8,6 +8,8 @@
 package state
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -22,6 +24,10 @@ import (
 
 const smallFileThreshold = 4096 // 4KB threshold for hashing small files
 
+// binarySniffLimit bounds how much of a file's head is inspected when deciding
+// whether it is binary.
+const binarySniffLimit = 8192
+
 // FileSignature captures the metadata of a file at a specific point in time.
 // It is used to detect changes to files without having to re-hash their
 // contents on every scan.
@@ -29,6 +35,22 @@ type FileSignature struct {
 	Size    int64     `json:"size"`
 	ModTime time.Time `json:"mod_time"`
 	Hash    string    `json:"hash,omitempty"`
+	// Binary is set when a hashed small file contains a NUL byte. It is
+	// informational only and does not take part in Equal.
+	Binary bool `json:"binary,omitempty"`
+	// Mode holds the permission bits, including setuid, setgid and sticky,
+	// and HasMode tells whether they were recorded: signatures from caches
+	// older than CacheVersion 2 have none. The mode does not take part in
+	// Equal; watchers that track it compare it with ModeChanged.
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	HasMode bool        `json:"has_mode,omitempty"`
+	// UID and GID hold the owning user and group, and HasOwner tells whether
+	// they were recorded: never on Windows, and not in caches older than
+	// CacheVersion 3. Like the mode, ownership does not take part in Equal;
+	// watchers that track it compare it with OwnerChanged.
+	UID      uint32 `json:"uid,omitempty"`
+	GID      uint32 `json:"gid,omitempty"`
+	HasOwner bool   `json:"has_owner,omitempty"`
 }
 
 // Equal reports whether two file signatures are identical. This is the core
@@ -37,6 +59,40 @@ func (s FileSignature) Equal(other FileSignature) bool {
 	return s.Size == other.Size && s.ModTime.Equal(other.ModTime) && s.Hash == other.Hash
 }
 
+// ModeChanged reports whether both signatures recorded a mode and the modes
+// differ. A signature without a mode matches any, so a cache written before
+// modes were recorded does not report every file on the first scan.
+func (s FileSignature) ModeChanged(other FileSignature) bool {
+	return s.HasMode && other.HasMode && s.Mode != other.Mode
+}
+
+// OwnerChanged reports whether both signatures recorded an owner and the
+// user or group differs. Like ModeChanged, a signature without an owner
+// matches any.
+func (s FileSignature) OwnerChanged(other FileSignature) bool {
+	return s.HasOwner && other.HasOwner && (s.UID != other.UID || s.GID != other.GID)
+}
+
+// SameStat reports whether info has the size, modification time, mode and
+// owner recorded in s, in which case the file is assumed unchanged without
+// rehashing it.
+func (s FileSignature) SameStat(info fs.FileInfo) bool {
+	if s.HasMode && s.Mode != permissionBits(info) {
+		return false
+	}
+	if s.HasOwner {
+		if uid, gid, ok := fileOwner(info); ok && (uid != s.UID || gid != s.GID) {
+			return false
+		}
+	}
+	return s.Size == info.Size() && s.ModTime.Equal(info.ModTime())
+}
+
+// permissionBits returns the mode bits of info recorded in a FileSignature.
+func permissionBits(info fs.FileInfo) fs.FileMode {
+	return info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+}
+
 // Cache stores file signatures in memory, keyed by their absolute paths. It
 // provides thread-safe access to the signatures and is used by the watcher to
 // maintain a consistent view of the file system state.
@@ -113,52 +169,334 @@ func (c *Cache) Len() int {
 	return len(c.files)
 }
 
+// TotalSize returns the sum of the sizes recorded in every cached signature.
+// It is computed on demand rather than maintained as a running total.
+func (c *Cache) TotalSize() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var total int64
+	for _, sig := range c.files {
+		total += sig.Size
+	}
+	return total
+}
+
+// CacheEntryOverhead approximates the memory, in bytes, an entry costs beyond
+// its path and hash strings: the signature struct, the string headers, and
+// the map's bucket share.
+const CacheEntryOverhead = 160
+
+// EstimatedBytes approximates the memory held by the cache as the sum, over
+// its entries, of the path length, the hash length, and CacheEntryOverhead.
+// It is meant to track growth rather than to match the heap exactly.
+func (c *Cache) EstimatedBytes() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var total int64
+	for path, sig := range c.files {
+		total += int64(len(path)+len(sig.Hash)) + CacheEntryOverhead
+	}
+	return total
+}
+
 // FilesUnder returns a copy of all cache entries whose paths are within the
 // given directory.
 func (c *Cache) FilesUnder(dir string) map[string]FileSignature {
-	cleanDir := filepath.Clean(dir)
-	prefix := cleanDir
-	if prefix != string(os.PathSeparator) {
-		prefix += string(os.PathSeparator)
-	}
-
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	result := make(map[string]FileSignature)
 	for path, sig := range c.files {
-		if path == cleanDir || strings.HasPrefix(path, prefix) {
+		if PathWithin(path, dir) {
 			result[path] = sig
 		}
 	}
 	return result
 }
 
+// PruneOutside removes every entry whose path is not within one of dirs and
+// returns how many were dropped. It keeps the cache consistent with the set of
+// watched directories after some are removed from the manifest.
+func (c *Cache) PruneOutside(dirs []string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for path := range c.files {
+		within := false
+		for _, dir := range dirs {
+			if PathWithin(path, dir) {
+				within = true
+				break
+			}
+		}
+		if !within {
+			delete(c.files, path)
+			removed++
+		}
+	}
+	return removed
+}
+
+// PathWithin reports whether path is dir itself or nested beneath it. Both
+// arguments are expected to be absolute, cleaned paths.
+func PathWithin(path, dir string) bool {
+	cleanDir := filepath.Clean(dir)
+	if path == cleanDir {
+		return true
+	}
+	prefix := cleanDir
+	if !strings.HasSuffix(prefix, string(os.PathSeparator)) {
+		prefix += string(os.PathSeparator)
+	}
+	return strings.HasPrefix(path, prefix)
+}
+
+// IsHidden reports whether a file or directory name marks a hidden entry,
+// i.e. it starts with `.` and is not `.` or `..` themselves.
+func IsHidden(name string) bool {
+	return len(name) > 1 && name[0] == '.' && name != ".."
+}
+
+// HiddenBelow reports whether any component of path beneath root is hidden.
+// root itself may be hidden without making its contents hidden.
+func HiddenBelow(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		if IsHidden(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// SignatureConfig selects which files a signature includes a content hash
+// for, and how much of them is hashed. The zero value hashes files of up to
+// 4KB in full, which is what ComputeSignature does.
+type SignatureConfig struct {
+	// HashThreshold is the largest file size, in bytes, that is hashed;
+	// larger files are compared by size and modification time alone. Zero
+	// selects 4KB.
+	HashThreshold int64
+	// HashReadLimit is how many bytes of a hashed file are read into the
+	// hash. Zero, or a value above HashThreshold, hashes the whole file.
+	// Below the threshold, only the first HashReadLimit bytes are hashed:
+	// two versions of a file larger than the limit with the same size and
+	// modification time that differ only past it get the same signature,
+	// so such an edit goes unnoticed.
+	HashReadLimit int64
+}
+
+func (c SignatureConfig) limits() (threshold, readLimit int64) {
+	threshold = c.HashThreshold
+	if threshold <= 0 {
+		threshold = smallFileThreshold
+	}
+	readLimit = c.HashReadLimit
+	if readLimit <= 0 || readLimit > threshold {
+		readLimit = threshold
+	}
+	return threshold, readLimit
+}
+
 // ComputeSignature calculates the signature for a file based on its size,
-// modification time, and, for small files, its content hash. It returns an
-// error if the path is a directory.
+// modification time, and, for small files, its content hash, and records its
+// permission bits and, outside Windows, its owner. It returns an error if the
+// path is a directory.
 func ComputeSignature(path string, info fs.FileInfo) (FileSignature, error) {
+	return SignatureConfig{}.Compute(path, info)
+}
+
+// Compute is ComputeSignature with the hashing limits of c.
+func (c SignatureConfig) Compute(path string, info fs.FileInfo) (FileSignature, error) {
 	if info.IsDir() {
 		return FileSignature{}, errors.New("state: compute signature called for directory")
 	}
 
-	sig := FileSignature{Size: info.Size(), ModTime: info.ModTime().UTC()}
-	if info.Size() > 0 && info.Size() <= smallFileThreshold {
+	threshold, readLimit := c.limits()
+	sig := FileSignature{Size: info.Size(), ModTime: info.ModTime().UTC(), Mode: permissionBits(info), HasMode: true}
+	sig.UID, sig.GID, sig.HasOwner = fileOwner(info)
+	if info.Size() > 0 && info.Size() <= threshold {
 		file, err := os.Open(path)
 		if err != nil {
 			return FileSignature{}, err
 		}
 		defer file.Close()
 
-		digest := sha256.New()
-		if _, err := io.Copy(digest, io.LimitReader(file, smallFileThreshold)); err != nil {
+		// Binary detection reuses the bytes already read for hashing.
+		data, err := io.ReadAll(io.LimitReader(file, readLimit))
+		if err != nil {
 			return FileSignature{}, err
 		}
-		sig.Hash = hex.EncodeToString(digest.Sum(nil))
+		digest := sha256.Sum256(data)
+		sig.Hash = hex.EncodeToString(digest[:])
+		sig.Binary = IsBinaryContent(data)
 	}
 
 	return sig, nil
 }
 
+// hashBufferSize is the read buffer HashFile streams files through.
+const hashBufferSize = 256 << 10
+
+// hashProgressStep is how many bytes HashFile reads between two progress
+// reports. It is a variable so tests can lower it.
+var hashProgressStep int64 = 64 << 20
+
+// ComputeFullSignature is ComputeSignature with every non-empty file hashed
+// in full, whatever its size, for integrity checks such as exports and
+// verification rather than change detection. Small files get the same hash
+// as from ComputeSignature; larger ones are streamed through HashFile, which
+// receives ctx and progress.
+func ComputeFullSignature(ctx context.Context, path string, info fs.FileInfo, progress func(done, total int64)) (FileSignature, error) {
+	sig, err := ComputeSignature(path, info)
+	if err != nil || sig.Size <= smallFileThreshold {
+		return sig, err
+	}
+	if sig.Hash, err = HashFile(ctx, path, progress); err != nil {
+		return FileSignature{}, err
+	}
+	return sig, nil
+}
+
+// HashFile returns the hex SHA-256 of the whole file at path. The file is
+// streamed through the hash with a fixed buffer, without a size limit and
+// without being mapped or read into memory. When progress is non-nil and the
+// file is larger than 64MB, it is called with the bytes hashed so far and the
+// size of the file when opened every 64MB and once at the end. Canceling ctx
+// abandons the read with ctx.Err().
+func HashFile(ctx context.Context, path string, progress func(done, total int64)) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	total := info.Size()
+	if total <= hashProgressStep {
+		progress = nil
+	}
+
+	hasher := sha256.New()
+	buf := make([]byte, hashBufferSize)
+	var done, reported int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := file.Read(buf)
+		if n > 0 {
+			hasher.Write(buf[:n])
+			done += int64(n)
+			if progress != nil && done-reported >= hashProgressStep {
+				reported = done
+				progress(done, total)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if progress != nil && reported != done {
+		progress(done, total)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// FileEntry is a file found by a walk whose signature is to be computed.
+type FileEntry struct {
+	Path string
+	Info fs.FileInfo
+}
+
+// SignatureResult is the outcome of computing one file's signature.
+type SignatureResult struct {
+	Signature FileSignature
+	Err       error
+}
+
+// parallelSignatureMin is the number of files below which ComputeSignatures
+// works serially, since starting workers would cost more than it saves.
+const parallelSignatureMin = 64
+
+// ComputeSignatures computes the signature of every file using up to workers
+// goroutines; hashing small files is I/O bound and dominates the cost of
+// scanning a tree. Results are returned in the order of files. Once ctx is
+// canceled the remaining files fail with ctx.Err().
+func ComputeSignatures(ctx context.Context, files []FileEntry, workers int) []SignatureResult {
+	return SignatureConfig{}.ComputeAll(ctx, files, workers)
+}
+
+// ComputeAll is ComputeSignatures with the hashing limits of c.
+func (c SignatureConfig) ComputeAll(ctx context.Context, files []FileEntry, workers int) []SignatureResult {
+	results := make([]SignatureResult, len(files))
+	sign := func(i int) {
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			return
+		}
+		results[i].Signature, results[i].Err = c.Compute(files[i].Path, files[i].Info)
+	}
+	if workers <= 1 || len(files) < parallelSignatureMin {
+		for i := range files {
+			sign(i)
+		}
+		return results
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sign(i)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// IsBinaryContent applies the usual heuristic of treating data as binary when
+// a NUL byte appears within the first 8KB.
+func IsBinaryContent(data []byte) bool {
+	if len(data) > binarySniffLimit {
+		data = data[:binarySniffLimit]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// SniffBinary reads the head of the file at path and reports whether it looks
+// binary. It is intended for files too large to have been hashed by
+// ComputeSignature.
+func SniffBinary(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	head, err := io.ReadAll(io.LimitReader(file, binarySniffLimit))
+	if err != nil {
+		return false, err
+	}
+	return IsBinaryContent(head), nil
+}
+
 // DetectChange compares a cached file signature with the current state of the
 // file on disk. It returns the new signature and a boolean indicating whether a
 // change was detected.
@@ -1,6 +1,12 @@
 package state
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -44,6 +50,30 @@ func TestCacheFilesUnder(t *testing.T) {
 	}
 }
 
+func TestCacheTotalSize(t *testing.T) {
+	cache := NewCache()
+	cache.Set("/tmp/a.txt", FileSignature{Size: 10})
+	cache.Set("/tmp/b.txt", FileSignature{Size: 32})
+
+	if total := cache.TotalSize(); total != 42 {
+		t.Fatalf("expected total size 42, got %d", total)
+	}
+}
+
+func TestCacheEstimatedBytes(t *testing.T) {
+	cache := NewCache()
+	if got := cache.EstimatedBytes(); got != 0 {
+		t.Fatalf("expected an empty cache to cost nothing, got %d", got)
+	}
+	cache.Set("/tmp/a.txt", FileSignature{Size: 10, Hash: strings.Repeat("f", 64)})
+	cache.Set("/tmp/large.bin", FileSignature{Size: 1 << 30})
+
+	want := int64(len("/tmp/a.txt")+64+len("/tmp/large.bin")) + 2*CacheEntryOverhead
+	if got := cache.EstimatedBytes(); got != want {
+		t.Fatalf("expected %d estimated bytes, got %d", want, got)
+	}
+}
+
 func TestComputeSignatureSmallFile(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "sample.txt")
@@ -116,3 +146,260 @@ func TestNormalizePath(t *testing.T) {
 		t.Fatalf("expected error for empty path")
 	}
 }
+
+func TestSignatureConfigSeparatesThresholdFromReadLimit(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("a"), 64<<10)
+	path := filepath.Join(dir, "medium.txt")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat temp file: %v", err)
+	}
+	hashOf := func(data []byte) string {
+		digest := sha256.Sum256(data)
+		return hex.EncodeToString(digest[:])
+	}
+
+	cases := []struct {
+		name string
+		cfg  SignatureConfig
+		want string
+	}{
+		{name: "default skips files over 4KB", cfg: SignatureConfig{}, want: ""},
+		{name: "threshold alone hashes the whole file", cfg: SignatureConfig{HashThreshold: 1 << 20}, want: hashOf(data)},
+		{name: "read limit hashes the head", cfg: SignatureConfig{HashThreshold: 1 << 20, HashReadLimit: 4096}, want: hashOf(data[:4096])},
+		{name: "read limit above the threshold is capped", cfg: SignatureConfig{HashThreshold: 64 << 10, HashReadLimit: 1 << 20}, want: hashOf(data)},
+		{name: "threshold below the size skips the file", cfg: SignatureConfig{HashThreshold: 32 << 10, HashReadLimit: 4096}, want: ""},
+	}
+	for _, tc := range cases {
+		sig, err := tc.cfg.Compute(path, info)
+		if err != nil {
+			t.Fatalf("%s: compute signature: %v", tc.name, err)
+		}
+		if sig.Hash != tc.want {
+			t.Fatalf("%s: expected hash %q, got %q", tc.name, tc.want, sig.Hash)
+		}
+	}
+
+	// Files that differ only past the read limit collide, as documented.
+	other := append(bytes.Repeat([]byte("a"), 4096), bytes.Repeat([]byte("b"), len(data)-4096)...)
+	if err := os.WriteFile(path, other, 0o644); err != nil {
+		t.Fatalf("rewrite temp file: %v", err)
+	}
+	cfg := SignatureConfig{HashThreshold: 1 << 20, HashReadLimit: 4096}
+	sig, err := cfg.Compute(path, info)
+	if err != nil {
+		t.Fatalf("compute signature: %v", err)
+	}
+	if sig.Hash != hashOf(data[:4096]) {
+		t.Fatalf("expected only the first 4096 bytes to be hashed")
+	}
+}
+
+func TestComputeSignatureFlagsBinarySmallFiles(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "notes.txt")
+	binary := filepath.Join(dir, "blob.bin")
+	if err := os.WriteFile(text, []byte("plain text\n"), 0o644); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	if err := os.WriteFile(binary, []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}, 0o644); err != nil {
+		t.Fatalf("write binary: %v", err)
+	}
+
+	for path, want := range map[string]bool{text: false, binary: true} {
+		info, _ := os.Stat(path)
+		sig, err := ComputeSignature(path, info)
+		if err != nil {
+			t.Fatalf("compute signature for %s: %v", path, err)
+		}
+		if sig.Binary != want {
+			t.Fatalf("expected Binary=%v for %s", want, path)
+		}
+	}
+
+	large := filepath.Join(dir, "large.bin")
+	data := make([]byte, smallFileThreshold*2)
+	for i := range data {
+		data[i] = 'a'
+	}
+	data[100] = 0
+	if err := os.WriteFile(large, data, 0o644); err != nil {
+		t.Fatalf("write large: %v", err)
+	}
+	if ok, err := SniffBinary(large); err != nil || !ok {
+		t.Fatalf("expected large file to sniff as binary (ok=%v, err=%v)", ok, err)
+	}
+}
+
+func TestCachePruneOutside(t *testing.T) {
+	cache := NewCache()
+	cache.Set("/watch/a/file.txt", FileSignature{Size: 1})
+	cache.Set("/watch/a/nested/file.txt", FileSignature{Size: 1})
+	cache.Set("/watch/ab/file.txt", FileSignature{Size: 1})
+	cache.Set("/removed/file.txt", FileSignature{Size: 1})
+
+	if removed := cache.PruneOutside([]string{"/watch/a"}); removed != 2 {
+		t.Fatalf("expected 2 orphaned entries removed, got %d", removed)
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries to remain, got %d", cache.Len())
+	}
+	if _, ok := cache.Get("/watch/ab/file.txt"); ok {
+		t.Fatalf("sibling directory with shared prefix should be pruned")
+	}
+}
+
+func TestComputeSignaturesKeepsInputOrder(t *testing.T) {
+	dir := t.TempDir()
+	var files []FileEntry
+	for i := 0; i < 2*parallelSignatureMin; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file-%03d.txt", i))
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", i)), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		files = append(files, FileEntry{Path: path, Info: info})
+	}
+	if err := os.Remove(files[7].Path); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	results := ComputeSignatures(context.Background(), files, 8)
+	if len(results) != len(files) {
+		t.Fatalf("expected %d results, got %d", len(files), len(results))
+	}
+	for i, result := range results {
+		if i == 7 {
+			if !errors.Is(result.Err, os.ErrNotExist) {
+				t.Fatalf("expected ErrNotExist for the removed file, got %v", result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("signature %d: %v", i, result.Err)
+		}
+		if result.Signature.Size != int64(i) {
+			t.Fatalf("result %d has size %d; results are out of order", i, result.Signature.Size)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range ComputeSignatures(ctx, files, 8) {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Fatalf("expected context.Canceled after cancellation, got %v", result.Err)
+		}
+	}
+}
+
+func TestSignatureModeComparison(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(path, []byte("echo hi\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	before, err := ComputeSignature(path, info)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	if !before.HasMode || before.Mode != 0o644 {
+		t.Fatalf("expected mode 0644 to be recorded, got %v (recorded %v)", before.Mode, before.HasMode)
+	}
+
+	if err := os.Chmod(path, 0o755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if info, err = os.Stat(path); err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	after, err := ComputeSignature(path, info)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	if !before.Equal(after) {
+		t.Fatalf("expected a mode change not to affect Equal")
+	}
+	if !before.ModeChanged(after) {
+		t.Fatalf("expected ModeChanged to report 0644 -> 0755")
+	}
+	if before.SameStat(info) {
+		t.Fatalf("expected SameStat to notice the new mode")
+	}
+
+	legacy := before
+	legacy.Mode, legacy.HasMode = 0, false
+	if legacy.ModeChanged(after) || !legacy.SameStat(info) {
+		t.Fatalf("expected a signature without a mode to match any mode")
+	}
+}
+
+func TestComputeFullSignatureHashesLargeFiles(t *testing.T) {
+	defer func(step int64) { hashProgressStep = step }(hashProgressStep)
+	hashProgressStep = 100 << 10
+
+	dir := t.TempDir()
+	large := filepath.Join(dir, "large.bin")
+	data := bytes.Repeat([]byte("0123456789abcdef"), 32<<10) // 512KB
+	if err := os.WriteFile(large, data, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	info, err := os.Stat(large)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+
+	fast, err := ComputeSignature(large, info)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	if fast.Hash != "" {
+		t.Fatalf("expected change detection to leave large files unhashed")
+	}
+
+	var reports [][2]int64
+	full, err := ComputeFullSignature(context.Background(), large, info, func(done, total int64) {
+		reports = append(reports, [2]int64{done, total})
+	})
+	if err != nil {
+		t.Fatalf("full signature: %v", err)
+	}
+	digest := sha256.Sum256(data)
+	if full.Hash != hex.EncodeToString(digest[:]) {
+		t.Fatalf("expected the hash of the whole file, got %s", full.Hash)
+	}
+	if full.Size != fast.Size || !full.ModTime.Equal(fast.ModTime) {
+		t.Fatalf("expected size and mtime to match the fast signature, got %+v", full)
+	}
+	if len(reports) < 2 || reports[len(reports)-1] != [2]int64{int64(len(data)), int64(len(data))} {
+		t.Fatalf("expected progress ending at the file size, got %v", reports)
+	}
+
+	small := filepath.Join(dir, "small.txt")
+	if err := os.WriteFile(small, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if info, err = os.Stat(small); err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	want, _ := ComputeSignature(small, info)
+	got, err := ComputeFullSignature(context.Background(), small, info, nil)
+	if err != nil || !got.Equal(want) {
+		t.Fatalf("expected small files to keep their usual signature, got %+v, %v", got, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := HashFile(ctx, large, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
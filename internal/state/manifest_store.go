@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 
 	"lowkey/pkg/config"
@@ -39,9 +40,79 @@ func NewManifestStore(dir string) (*ManifestStore, error) {
 	return &ManifestStore{dir: cleanDir, path: path}, nil
 }
 
+// DefaultProfile names the profile used when none is selected. Its state lives
+// directly in the state directory root so existing installs keep working.
+const DefaultProfile = "default"
+
+// profileNamePattern restricts profile names to a single safe path segment.
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// activeProfile is the profile selected with SetProfile.
+var activeProfile = DefaultProfile
+
+// SetProfile selects the profile whose state directory DefaultStateDir
+// returns. Each non-default profile gets its own manifest, PID file, cache,
+// and logs under `profiles/<name>` so several daemons can coexist. An empty
+// name selects the default profile.
+func SetProfile(name string) error {
+	if name == "" {
+		activeProfile = DefaultProfile
+		return nil
+	}
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("state: invalid profile name %q", name)
+	}
+	activeProfile = name
+	return nil
+}
+
+// stateDirOverride replaces the platform state directory when set with
+// SetStateDir.
+var stateDirOverride string
+
+// SetStateDir makes DefaultStateDir resolve beneath dir instead of the
+// platform location, for the rest of the process. Profiles are still
+// namespaced beneath it. Relative paths are made absolute so the daemon, which
+// may run from another working directory, sees the same location. An empty
+// dir restores the platform default.
+func SetStateDir(dir string) error {
+	if dir == "" {
+		stateDirOverride = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("state: resolve state directory %q: %w", dir, err)
+	}
+	stateDirOverride = abs
+	return nil
+}
+
+// Profile returns the currently selected profile name.
+func Profile() string {
+	return activeProfile
+}
+
 // DefaultStateDir determines the appropriate platform-specific directory for
 // storing the daemon's state, following the XDG Base Directory Specification.
+// Non-default profiles are namespaced beneath `profiles/<name>`.
 func DefaultStateDir() (string, error) {
+	base, err := baseStateDir()
+	if err != nil {
+		return "", err
+	}
+	if activeProfile == DefaultProfile {
+		return base, nil
+	}
+	return filepath.Join(base, "profiles", activeProfile), nil
+}
+
+// baseStateDir returns the state directory shared by all profiles: the
+// SetStateDir override when present, otherwise the platform location.
+func baseStateDir() (string, error) {
+	if stateDirOverride != "" {
+		return stateDirOverride, nil
+	}
 	if custom := os.Getenv("XDG_STATE_HOME"); custom != "" {
 		return filepath.Join(custom, "lowkey"), nil
 	}
@@ -64,6 +135,21 @@ func DefaultStateDir() (string, error) {
 	}
 }
 
+// CheckWritable reports whether files can be created in dir, creating it
+// first when missing. It probes with a temporary file that is removed again,
+// so a read-only location is detected before anything is persisted there.
+func CheckWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("state: state directory %q is not writable: %w", dir, err)
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("state: state directory %q is not writable: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
 // Path returns the full path to the manifest file.
 func (s *ManifestStore) Path() string {
 	return s.path
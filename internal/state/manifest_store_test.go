@@ -0,0 +1,70 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultStateDirNamespacesProfiles(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", base)
+	t.Cleanup(func() { _ = SetProfile("") })
+
+	dir, err := DefaultStateDir()
+	if err != nil {
+		t.Fatalf("default state dir: %v", err)
+	}
+	if want := filepath.Join(base, "lowkey"); dir != want {
+		t.Fatalf("expected %s for default profile, got %s", want, dir)
+	}
+
+	if err := SetProfile("work"); err != nil {
+		t.Fatalf("set profile: %v", err)
+	}
+	dir, err = DefaultStateDir()
+	if err != nil {
+		t.Fatalf("profile state dir: %v", err)
+	}
+	if want := filepath.Join(base, "lowkey", "profiles", "work"); dir != want {
+		t.Fatalf("expected %s for work profile, got %s", want, dir)
+	}
+
+	if err := SetStateDir(filepath.Join(base, "custom")); err != nil {
+		t.Fatalf("set state dir: %v", err)
+	}
+	t.Cleanup(func() { _ = SetStateDir("") })
+	dir, err = DefaultStateDir()
+	if err != nil {
+		t.Fatalf("override state dir: %v", err)
+	}
+	if want := filepath.Join(base, "custom", "profiles", "work"); dir != want {
+		t.Fatalf("expected %s with --state-dir and work profile, got %s", want, dir)
+	}
+
+	for _, name := range []string{"..", "a/b", "-x"} {
+		if err := SetProfile(name); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestCheckWritableRejectsUnusableStateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := CheckWritable(dir); err != nil {
+		t.Fatalf("expected a missing directory to be created, got %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Fatalf("expected the probe to be removed, got %v (%v)", entries, err)
+	}
+
+	// A regular file in the way fails even for root, unlike permission bits.
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := CheckWritable(filepath.Join(blocker, "state")); err == nil || !strings.Contains(err.Error(), "not writable") {
+		t.Fatalf("expected a not writable error, got %v", err)
+	}
+}
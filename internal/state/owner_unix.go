@@ -0,0 +1,17 @@
+//go:build !windows
+
+package state
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileOwner returns the user and group IDs owning the file described by info.
+func fileOwner(info fs.FileInfo) (uid, gid uint32, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return stat.Uid, stat.Gid, true
+}
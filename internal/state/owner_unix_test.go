@@ -0,0 +1,51 @@
+//go:build !windows
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignatureRecordsOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owned.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	sig, err := ComputeSignature(path, info)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	if !sig.HasOwner || sig.UID != uint32(os.Getuid()) {
+		t.Fatalf("expected the file to be owned by user %d, got %+v", os.Getuid(), sig)
+	}
+	if !sig.SameStat(info) {
+		t.Fatalf("expected the recorded owner to match the file")
+	}
+
+	// Changing ownership needs root, so the comparison is checked on
+	// signatures directly.
+	chowned := sig
+	chowned.UID++
+	if !sig.OwnerChanged(chowned) || !sig.Equal(chowned) {
+		t.Fatalf("expected a new user to be an owner change, not a content change")
+	}
+	if chowned.SameStat(info) {
+		t.Fatalf("expected SameStat to notice a different owner")
+	}
+	regrouped := sig
+	regrouped.GID++
+	if !sig.OwnerChanged(regrouped) {
+		t.Fatalf("expected a new group to be an owner change")
+	}
+	legacy := chowned
+	legacy.UID, legacy.GID, legacy.HasOwner = 0, 0, false
+	if legacy.OwnerChanged(sig) || sig.OwnerChanged(legacy) || !legacy.SameStat(info) {
+		t.Fatalf("expected a signature without an owner to match any owner")
+	}
+}
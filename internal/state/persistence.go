@@ -11,13 +11,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 // persistence.go handles durable storage for the cache (e.g., boltDB or JSON).
 // Ensure writes are atomic so crash recovery honors the PRD.
 
+// CacheVersion is the format version written by Save. Version 2 records each
+// file's mode (FileSignature.Mode) and version 3 its owner (UID and GID).
+// Older caches still load, without the newer fields recorded, so the first
+// scan after upgrading does not report every file's permissions or owner as
+// changed.
+const CacheVersion = 3
+
 type persistedCache struct {
 	Version int                      `json:"version"`
 	Files   map[string]FileSignature `json:"files"`
@@ -39,8 +48,7 @@ func Save(cache *Cache, path string) error {
 		return fmt.Errorf("state: create cache directory %q: %w", dir, err)
 	}
 
-	snapshot := cache.Snapshot()
-	payload := persistedCache{Version: 1, Files: snapshot}
+	payload := persistedCache{Version: CacheVersion, Files: cache.Snapshot()}
 
 	tempFile, err := os.CreateTemp(dir, "cache-*.json")
 	if err != nil {
@@ -50,9 +58,7 @@ func Save(cache *Cache, path string) error {
 		_ = os.Remove(tempFile.Name())
 	}()
 
-	encoder := json.NewEncoder(tempFile)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(payload); err != nil {
+	if err := writePersisted(tempFile, payload); err != nil {
 		tempFile.Close()
 		return fmt.Errorf("state: encode cache: %w", err)
 	}
@@ -68,6 +74,60 @@ func Save(cache *Cache, path string) error {
 	return nil
 }
 
+// WriteSnapshot writes files to w as an indented JSON document in the format
+// Save uses, so a snapshot written to a file can be read back with Load.
+func WriteSnapshot(w io.Writer, files map[string]FileSignature) error {
+	if files == nil {
+		files = make(map[string]FileSignature)
+	}
+	return writePersisted(w, persistedCache{Version: CacheVersion, Files: files})
+}
+
+func writePersisted(w io.Writer, payload persistedCache) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(payload)
+}
+
+// SnapshotDiff lists the paths that differ between two snapshots, each list
+// sorted.
+type SnapshotDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Changed lists the files in both snapshots whose signatures are not
+	// Equal, or whose mode or owner changed where both recorded it.
+	Changed []string `json:"changed"`
+}
+
+// IsEmpty reports whether the snapshots were identical.
+func (d SnapshotDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// CompareSnapshots reports how the files in newer differ from those in older.
+// The lists are never nil, so they encode as empty JSON arrays.
+func CompareSnapshots(older, newer map[string]FileSignature) SnapshotDiff {
+	diff := SnapshotDiff{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for path, sig := range newer {
+		previous, ok := older[path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, path)
+		case !previous.Equal(sig) || previous.ModeChanged(sig) || previous.OwnerChanged(sig):
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	for path := range older {
+		if _, ok := newer[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Removed)
+	slices.Sort(diff.Changed)
+	return diff
+}
+
 // Load reads a cache from the specified path. If the file does not exist, it
 // returns a new, empty cache. This allows the application to gracefully handle
 // the initial run when no cache file is present.
@@ -1,7 +1,9 @@
 package state
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -53,3 +55,41 @@ func TestSaveRejectsNilCache(t *testing.T) {
 		t.Fatalf("expected error when saving nil cache")
 	}
 }
+
+func TestWrittenSnapshotsLoadAndCompare(t *testing.T) {
+	now := time.Now().UTC()
+	older := map[string]FileSignature{
+		"/w/kept":    {Size: 1, ModTime: now, Hash: "a"},
+		"/w/edited":  {Size: 1, ModTime: now, Hash: "a"},
+		"/w/chmod":   {Size: 1, ModTime: now, Mode: 0o644, HasMode: true},
+		"/w/removed": {Size: 1, ModTime: now},
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSnapshot(file, older); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+	file.Close()
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	newer := map[string]FileSignature{
+		"/w/kept":   {Size: 1, ModTime: now, Hash: "a"},
+		"/w/edited": {Size: 1, ModTime: now, Hash: "b"},
+		"/w/chmod":  {Size: 1, ModTime: now, Mode: 0o600, HasMode: true},
+		"/w/added":  {Size: 1, ModTime: now},
+	}
+	diff := CompareSnapshots(loaded.Snapshot(), newer)
+	want := SnapshotDiff{Added: []string{"/w/added"}, Removed: []string{"/w/removed"}, Changed: []string{"/w/chmod", "/w/edited"}}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("expected %+v, got %+v", want, diff)
+	}
+	if !CompareSnapshots(newer, newer).IsEmpty() {
+		t.Fatalf("expected a snapshot to equal itself")
+	}
+}
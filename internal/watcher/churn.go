@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultChurnThreshold is the number of changes within one second beneath a
+// single directory above which the monitor warns about churn.
+const DefaultChurnThreshold = 1000
+
+// churnWarnInterval is how long a churning directory stays listed by
+// HybridMonitor.Churning after its last burst, and the minimum time between
+// two warnings about the same directory.
+const churnWarnInterval = time.Minute
+
+// ChurnReport describes a directory whose files changed faster than the churn
+// threshold, usually a sign that a build output, cache, or log directory is
+// watched by mistake.
+type ChurnReport struct {
+	// Directory is the directory holding the changed files.
+	Directory string `json:"directory"`
+	// Rate is the number of changes counted in the busiest second.
+	Rate int `json:"rate"`
+	// TopPath is the file that changed most often during that second.
+	TopPath string `json:"top_path"`
+	// At is when the threshold was last exceeded.
+	At time.Time `json:"at"`
+	// Suggestion is an ignore pattern that would exclude the churn.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// churnGuard counts changes per directory in one-second windows and reports
+// each directory once per window when it crosses the threshold.
+type churnGuard struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Time
+	counts    map[string]int
+	paths     map[string]int
+	reports   map[string]ChurnReport
+	warned    map[string]time.Time
+}
+
+func newChurnGuard(threshold int) *churnGuard {
+	if threshold == 0 {
+		threshold = DefaultChurnThreshold
+	}
+	if threshold < 0 {
+		return nil
+	}
+	return &churnGuard{
+		threshold: threshold,
+		counts:    make(map[string]int),
+		paths:     make(map[string]int),
+		reports:   make(map[string]ChurnReport),
+		warned:    make(map[string]time.Time),
+	}
+}
+
+// observe counts a change to path at now. It returns a report when this
+// change pushes its directory over the threshold, and warn when the caller
+// should log it, at most once per churnWarnInterval per directory. It does
+// nothing on a nil guard.
+func (g *churnGuard) observe(path string, now time.Time) (report ChurnReport, warn bool) {
+	if g == nil {
+		return ChurnReport{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if second := now.Truncate(time.Second); !second.Equal(g.window) {
+		g.window = second
+		clear(g.counts)
+		clear(g.paths)
+	}
+	dir := filepath.Dir(path)
+	g.counts[dir]++
+	g.paths[path]++
+	count := g.counts[dir]
+	if count <= g.threshold {
+		return ChurnReport{}, false
+	}
+
+	report = g.reports[dir]
+	if now.Sub(report.At) >= churnWarnInterval {
+		report = ChurnReport{Directory: dir}
+	}
+	report.Rate = max(report.Rate, count)
+	report.At = now
+	crossed := count == g.threshold+1
+	if crossed {
+		report.TopPath = g.topPath(dir)
+	}
+	g.reports[dir] = report
+	if !crossed || now.Sub(g.warned[dir]) < churnWarnInterval {
+		return report, false
+	}
+	g.warned[dir] = now
+	return report, true
+}
+
+// topPath returns the path beneath dir changed most often in the current
+// window. The caller must hold g.mu.
+func (g *churnGuard) topPath(dir string) string {
+	top, most := "", 0
+	for path, n := range g.paths {
+		if filepath.Dir(path) == dir && (n > most || n == most && path < top) {
+			top, most = path, n
+		}
+	}
+	return top
+}
+
+// churning returns the directories that exceeded the threshold within the
+// last churnWarnInterval, busiest first.
+func (g *churnGuard) churning(now time.Time) []ChurnReport {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var reports []ChurnReport
+	for dir, report := range g.reports {
+		if now.Sub(report.At) >= churnWarnInterval {
+			delete(g.reports, dir)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Rate != reports[j].Rate {
+			return reports[i].Rate > reports[j].Rate
+		}
+		return reports[i].Directory < reports[j].Directory
+	})
+	return reports
+}
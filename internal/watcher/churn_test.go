@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lowkey/internal/clock"
+	"lowkey/internal/events"
+)
+
+func TestChurnGuardWarnsOncePerDirectoryAndExpires(t *testing.T) {
+	guard := newChurnGuard(3)
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	var warnings int
+	for i := 0; i < 10; i++ {
+		path := filepath.Join("/w/logs", fmt.Sprintf("%d.log", i%2))
+		if _, warn := guard.observe(path, start.Add(time.Duration(i)*time.Millisecond)); warn {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("expected a single warning for the burst, got %d", warnings)
+	}
+
+	// Another burst within the minute updates the report without warning.
+	for i := 0; i < 5; i++ {
+		if _, warn := guard.observe("/w/logs/0.log", start.Add(10*time.Second)); warn {
+			t.Fatalf("expected warnings about the same directory to be throttled")
+		}
+	}
+	reports := guard.churning(start.Add(20 * time.Second))
+	if len(reports) != 1 || reports[0].Directory != "/w/logs" || reports[0].Rate != 10 || reports[0].TopPath != "/w/logs/0.log" {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	if reports := guard.churning(start.Add(2 * time.Minute)); len(reports) != 0 {
+		t.Fatalf("expected the report to expire, got %+v", reports)
+	}
+
+	if newChurnGuard(-1) != nil {
+		t.Fatalf("expected a negative threshold to disable the guard")
+	}
+}
+
+func TestMonitorReportsChurnWithIgnoreSuggestion(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+
+	var reports []ChurnReport
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:        backend,
+		Directories:    []string{dir},
+		Clock:          clock.NewFake(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)),
+		ChurnThreshold: 2,
+		OnChurn:        func(report ChurnReport) { reports = append(reports, report) },
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+
+	busy := filepath.Join(dir, "build", "out.o")
+	for i := 0; i < 4; i++ {
+		monitor.recordChange(busy, events.EventModify, time.Now())
+	}
+	monitor.recordChange(dir, ChangeBoot, time.Now())
+	if len(reports) != 1 || reports[0].Suggestion != "build/" || reports[0].TopPath != busy {
+		t.Fatalf("expected one churn report suggesting build/, got %+v", reports)
+	}
+	if churning := monitor.Churning(); len(churning) != 1 || churning[0].Rate != 4 {
+		t.Fatalf("expected the churning directory with rate 4, got %+v", churning)
+	}
+
+	if got := suggestIgnorePattern(dir, ChurnReport{Directory: dir, TopPath: filepath.Join(dir, "app.log")}); got != "**/*.log" {
+		t.Fatalf("expected an extension pattern for churn in the root, got %q", got)
+	}
+}
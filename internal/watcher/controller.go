@@ -11,9 +11,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"lowkey/internal/clock"
 	"lowkey/internal/events"
 	"lowkey/internal/logging"
 	"lowkey/internal/reporting"
@@ -30,17 +33,112 @@ type Controller struct {
 	cancel  context.CancelFunc
 	backend events.Backend
 	monitor *HybridMonitor
+	cache   *state.Cache
+	pauseMu sync.Mutex
+	paused  atomic.Bool
+	// running is true while the monitor goroutine is alive; fatal holds the
+	// error that stopped it before Stop was called.
+	running atomic.Bool
+	fatal   atomic.Pointer[error]
+	// stopAfter releases the link from the StartContext context to cancel.
+	stopAfter func() bool
 }
 
 // ControllerConfig contains the dependencies and configuration required to run
 // a watcher controller.
 type ControllerConfig struct {
-	Directories  []string
-	IgnoreGlobs  []string
+	Directories []string
+	IgnoreGlobs []string
+	// IncludeGlobs, when non-empty, limits tracking to files matching at
+	// least one glob. It is the first gate: ignore globs only apply to files
+	// that pass it.
+	IncludeGlobs []string
 	Aggregator   *reporting.Aggregator
 	Logger       *logging.Logger
 	PollInterval time.Duration
 	OnChange     func(reporting.Change)
+	// Filter is invoked for every change that survives the ignore patterns,
+	// before it reaches the aggregator, logger, or OnChange. It returns the
+	// change to record, possibly rewritten, and false to drop it. It runs on
+	// the monitor goroutine, so it must be fast and must not block. Nil
+	// records every change unchanged.
+	Filter func(reporting.Change) (reporting.Change, bool)
+	// Clock supplies timestamps for scan-detected changes and the startup
+	// marker. Nil uses the system clock.
+	Clock clock.Clock
+	// DetectBinary flags binary files on the changes reported for them.
+	DetectBinary bool
+	// IgnoreFiles and ReloadIgnore enable hot reloading of IgnoreGlobs: when
+	// one of the files changes, ReloadIgnore supplies the new patterns. See
+	// HybridMonitorConfig.IgnoreFiles.
+	IgnoreFiles  []string
+	ReloadIgnore func() ([]string, error)
+	// EventBuffer is the number of backend events queued before new ones are
+	// dropped. Raise it for very large or bursty trees at the cost of memory.
+	// Zero uses events.DefaultEventBuffer.
+	EventBuffer int
+	// MaxTrackedFiles caps the number of tracked files. Zero uses
+	// DefaultMaxTrackedFiles and a negative value disables the cap.
+	MaxTrackedFiles int
+	// SkipHidden stops watching dot-files and dot-directories beneath the
+	// watched directories. See HybridMonitorConfig.SkipHidden.
+	SkipHidden bool
+	// WarmUp is the settling period after Start during which the cache
+	// baseline is built without reporting changes. Zero or a negative value
+	// disables it. See HybridMonitorConfig.WarmUp.
+	WarmUp time.Duration
+	// MinPollInterval and MaxPollInterval, when both are positive, bound the
+	// adaptive safety-scan interval, which starts at PollInterval. Otherwise
+	// the interval stays fixed at PollInterval. See
+	// HybridMonitorConfig.MinPollInterval.
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+	// IgnoreOlderThan and IgnoreNewerThan drop changes to files modified
+	// outside the given age window. See HybridMonitorConfig.IgnoreOlderThan.
+	IgnoreOlderThan time.Duration
+	IgnoreNewerThan time.Duration
+	// BloomCache is a file the ignore Bloom filter is cached in across
+	// starts. See HybridMonitorConfig.BloomCache.
+	BloomCache string
+	// ScanConcurrency bounds the file signatures computed in parallel by the
+	// backend's initial snapshot and the monitor's scans. See
+	// HybridMonitorConfig.ScanConcurrency.
+	ScanConcurrency int
+	// OnScanProgress receives the progress of the first safety scan. See
+	// HybridMonitorConfig.OnScanProgress.
+	OnScanProgress func(ScanProgress)
+	// TrackMode reports permission changes as ChangePerm. See
+	// HybridMonitorConfig.TrackMode.
+	TrackMode bool
+	// TrackOwner reports ownership changes as ChangeOwner. See
+	// HybridMonitorConfig.TrackOwner.
+	TrackOwner bool
+	// DisableSafetyScan and ScanSchedule turn periodic safety scans off or
+	// time them by a cron schedule. See HybridMonitorConfig.DisableSafetyScan.
+	DisableSafetyScan bool
+	ScanSchedule      *clock.Schedule
+	// ChurnThreshold is the per-directory change rate that triggers a churn
+	// warning, which is also passed to OnChurn. See
+	// HybridMonitorConfig.ChurnThreshold.
+	ChurnThreshold int
+	OnChurn        func(ChurnReport)
+	// Sinks and OnSinkError set where changes are logged. The controller
+	// never closes the sinks, so they can be shared by the controllers that
+	// replace it. See HybridMonitorConfig.Sinks.
+	Sinks       []LogSink
+	OnSinkError func(error)
+	// Cache, when non-nil, is the signature cache the monitor starts from,
+	// typically carried over from a controller being replaced, so files it
+	// already knows are not reported again. Entries outside Directories are
+	// pruned. The warm-up is skipped: directories without cached files are
+	// instead baselined silently before the monitor starts.
+	Cache *state.Cache
+	// NewBackend creates the event backend each time the controller starts.
+	// Nil uses events.NewBackend.
+	NewBackend func(events.BackendConfig) (events.Backend, error)
+	// Signature selects which files are hashed into their signatures. See
+	// HybridMonitorConfig.Signature.
+	Signature state.SignatureConfig
 }
 
 // NewController validates the provided configuration and returns a new,
@@ -55,45 +153,115 @@ func NewController(config ControllerConfig) (*Controller, error) {
 
 // Start launches the goroutines required to watch directories using the
 // configured hybrid monitor. It initializes the event backend and the monitor,
-// and starts the monitoring process.
+// and starts the monitoring process. It is StartContext with
+// context.Background().
 func (c *Controller) Start() error {
+	return c.StartContext(context.Background())
+}
+
+// StartContext is Start with a context bounding the watcher's lifetime. The
+// initial walk of each directory happens in the background and can take
+// seconds on a large tree; canceling ctx aborts it, and any later scan,
+// promptly and stops the watcher as if by Stop, which must still be called to
+// release the backend.
+func (c *Controller) StartContext(ctx context.Context) error {
 	if c.ctx.Err() != nil {
 		return fmt.Errorf("watcher: controller closed")
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if len(c.config.IgnoreGlobs) > 0 && c.config.Logger != nil {
 		c.config.Logger.Infof("watcher ignoring %d patterns", len(c.config.IgnoreGlobs))
 	}
-	backend, err := events.NewBackend()
+	newBackend := c.config.NewBackend
+	if newBackend == nil {
+		newBackend = events.NewBackend
+	}
+	backend, err := newBackend(events.BackendConfig{EventBuffer: c.config.EventBuffer, SkipHidden: c.config.SkipHidden, Concurrency: c.config.ScanConcurrency, TrackMode: c.config.TrackMode, TrackOwner: c.config.TrackOwner, Signature: c.config.Signature})
 	if err != nil {
 		return err
 	}
-	cache := state.NewCache()
+	cache := c.config.Cache
+	warmUp := c.config.WarmUp
+	var baseline []string
+	if cache == nil {
+		cache = state.NewCache()
+	} else {
+		cache.PruneOutside(c.config.Directories)
+		for _, dir := range c.config.Directories {
+			if len(cache.FilesUnder(dir)) == 0 {
+				baseline = append(baseline, dir)
+			}
+		}
+		warmUp = -1
+	}
 	monitor, err := NewHybridMonitor(HybridMonitorConfig{
-		Backend:        backend,
-		Cache:          cache,
-		Aggregator:     c.config.Aggregator,
-		Logger:         c.config.Logger,
-		Directories:    c.config.Directories,
-		PollInterval:   c.config.PollInterval,
-		IgnorePatterns: c.config.IgnoreGlobs,
-		OnChange:       c.config.OnChange,
+		Backend:           backend,
+		Cache:             cache,
+		Aggregator:        c.config.Aggregator,
+		Logger:            c.config.Logger,
+		Directories:       c.config.Directories,
+		PollInterval:      c.config.PollInterval,
+		IgnorePatterns:    c.config.IgnoreGlobs,
+		IncludePatterns:   c.config.IncludeGlobs,
+		OnChange:          c.config.OnChange,
+		Filter:            c.config.Filter,
+		Clock:             c.config.Clock,
+		DetectBinary:      c.config.DetectBinary,
+		IgnoreFiles:       c.config.IgnoreFiles,
+		ReloadIgnore:      c.config.ReloadIgnore,
+		MaxTrackedFiles:   c.config.MaxTrackedFiles,
+		SkipHidden:        c.config.SkipHidden,
+		WarmUp:            warmUp,
+		MinPollInterval:   c.config.MinPollInterval,
+		MaxPollInterval:   c.config.MaxPollInterval,
+		IgnoreOlderThan:   c.config.IgnoreOlderThan,
+		IgnoreNewerThan:   c.config.IgnoreNewerThan,
+		BloomCache:        c.config.BloomCache,
+		ScanConcurrency:   c.config.ScanConcurrency,
+		OnScanProgress:    c.config.OnScanProgress,
+		TrackMode:         c.config.TrackMode,
+		TrackOwner:        c.config.TrackOwner,
+		DisableSafetyScan: c.config.DisableSafetyScan,
+		ScanSchedule:      c.config.ScanSchedule,
+		ChurnThreshold:    c.config.ChurnThreshold,
+		OnChurn:           c.config.OnChurn,
+		Sinks:             c.config.Sinks,
+		OnSinkError:       c.config.OnSinkError,
+		Signature:         c.config.Signature,
 	})
 	if err != nil {
 		_ = backend.Close()
 		return err
 	}
-	c.backend = backend
+	c.pauseMu.Lock()
+	monitor.SetPaused(c.paused.Load())
 	c.monitor = monitor
+	c.pauseMu.Unlock()
+	c.backend = backend
+	c.cache = cache
+	c.stopAfter = context.AfterFunc(ctx, c.cancel)
+	c.fatal.Store(nil)
+	c.running.Store(true)
 	c.wg.Add(1)
 	go func() {
 		defer c.wg.Done()
-		_ = monitor.Run(c.ctx)
+		defer c.running.Store(false)
+		err := runMonitor(c.ctx, monitor, baseline)
+		if err == nil || c.ctx.Err() != nil {
+			return
+		}
+		c.fatal.Store(&err)
+		if c.config.Logger != nil {
+			c.config.Logger.Errorf("watcher stopped unexpectedly: %v", err)
+		}
 	}()
 	if c.config.Aggregator != nil {
-		c.config.Aggregator.Record(reporting.Change{
+		c.config.Aggregator.RecordMarker(reporting.Change{
 			Path:      "(daemon startup)",
-			Type:      "BOOT",
-			Timestamp: time.Now().UTC(),
+			Type:      ChangeBoot,
+			Timestamp: clock.OrReal(c.config.Clock).Now().UTC(),
 		})
 	}
 	if c.config.Logger != nil {
@@ -102,10 +270,163 @@ func (c *Controller) Start() error {
 	return nil
 }
 
+// runMonitor baselines the given directories and runs monitor until ctx is
+// canceled, converting a panic into an error.
+func runMonitor(ctx context.Context, monitor *HybridMonitor, baseline []string) (err error) {
+	defer recoverFatal(func(fatal error) { err = fatal })
+	monitor.ctx = ctx
+	for _, dir := range baseline {
+		monitor.reseed(dir)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return monitor.Run(ctx)
+}
+
+// Running reports whether the monitor goroutine is alive. It is false before
+// Start, after Stop, and once the monitor has exited on its own, in which
+// case Err says why.
+func (c *Controller) Running() bool {
+	return c.running.Load()
+}
+
+// Err returns the error that stopped the monitor without Stop being called,
+// or nil while it is running or after a clean shutdown.
+func (c *Controller) Err() error {
+	if err := c.fatal.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Pause suspends change recording until Resume is called. It may be called
+// before Start, in which case the monitor starts paused.
+func (c *Controller) Pause() {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	c.paused.Store(true)
+	if c.monitor != nil {
+		c.monitor.SetPaused(true)
+	}
+}
+
+// Resume re-enables change recording and triggers an immediate safety scan so
+// changes made while paused are reported.
+func (c *Controller) Resume() {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	c.paused.Store(false)
+	if c.monitor != nil {
+		c.monitor.SetPaused(false)
+	}
+}
+
+// Paused reports whether change recording is suspended.
+func (c *Controller) Paused() bool {
+	return c.paused.Load()
+}
+
+// InaccessiblePaths returns the paths the running monitor skipped during its
+// latest scans because they could not be read. It returns nil before Start.
+func (c *Controller) InaccessiblePaths() []string {
+	c.pauseMu.Lock()
+	monitor := c.monitor
+	c.pauseMu.Unlock()
+	if monitor == nil {
+		return nil
+	}
+	return monitor.InaccessiblePaths()
+}
+
+// TrackingLimit reports whether the running monitor stopped tracking new files
+// because MaxTrackedFiles was reached, and the directory it was scanning.
+func (c *Controller) TrackingLimit() (root string, reached bool) {
+	c.pauseMu.Lock()
+	monitor := c.monitor
+	c.pauseMu.Unlock()
+	if monitor == nil {
+		return "", false
+	}
+	return monitor.TrackingLimit()
+}
+
+// Churning returns the directories the running monitor found changing faster
+// than the churn threshold within the last minute. It returns nil before
+// Start.
+func (c *Controller) Churning() []ChurnReport {
+	c.pauseMu.Lock()
+	monitor := c.monitor
+	c.pauseMu.Unlock()
+	if monitor == nil {
+		return nil
+	}
+	return monitor.Churning()
+}
+
+// Cache returns the signature cache backing the running monitor, or nil if the
+// controller has not been started.
+func (c *Controller) Cache() *state.Cache {
+	return c.cache
+}
+
+// DryRun performs a single scan of the configured directories using the
+// controller's ignore rules and reports how many files would be tracked. It
+// neither starts the event backend nor records changes, which makes it useful
+// for tuning ignore patterns against a real tree.
+func (c *Controller) DryRun() (DryRunReport, error) {
+	monitor := &HybridMonitor{
+		cache:           state.NewCache(),
+		directories:     c.config.Directories,
+		includePatterns: trimPatterns(c.config.IncludeGlobs),
+		skipHidden:      c.config.SkipHidden,
+		clock:           clock.OrReal(c.config.Clock),
+	}
+	monitor.ignore.Store(compileIgnorePatterns(c.config.IgnoreGlobs))
+	return monitor.DryRun()
+}
+
+// Snapshot performs a single scan of the configured directories using the
+// controller's ignore rules and returns the signature of every file that would
+// be tracked, keyed by path, along with the paths that could not be read. Like
+// DryRun, it neither starts the event backend nor records changes.
+func (c *Controller) Snapshot() (map[string]state.FileSignature, []string, error) {
+	concurrency := c.config.ScanConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	monitor := &HybridMonitor{
+		cache:           state.NewCache(),
+		directories:     c.config.Directories,
+		includePatterns: trimPatterns(c.config.IncludeGlobs),
+		skipHidden:      c.config.SkipHidden,
+		clock:           clock.OrReal(c.config.Clock),
+		concurrency:     concurrency,
+		signature:       c.config.Signature,
+	}
+	monitor.ignore.Store(compileIgnorePatterns(c.config.IgnoreGlobs))
+
+	files := make(map[string]state.FileSignature)
+	var inaccessible []string
+	for _, dir := range c.config.Directories {
+		denied, err := monitor.walkSignatures(dir, nil, func(path string, sig state.FileSignature) {
+			files[path] = sig
+		})
+		inaccessible = append(inaccessible, denied...)
+		if err != nil {
+			return files, inaccessible, err
+		}
+	}
+	return files, inaccessible, nil
+}
+
 // Stop gracefully cancels the active monitoring goroutines and waits for them
 // to shut down. This ensures a clean and orderly termination of the watcher.
 func (c *Controller) Stop() {
 	c.cancel()
+	if c.stopAfter != nil {
+		c.stopAfter()
+	}
 	if c.backend != nil {
 		_ = c.backend.Close()
 	}
@@ -9,14 +9,20 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	pathpkg "path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"lowkey/internal/clock"
 	"lowkey/internal/events"
 	"lowkey/internal/filters"
 	"lowkey/internal/logging"
@@ -28,15 +34,112 @@ import (
 // scans to provide resilient and reliable change detection. It is designed to
 // catch events that might be missed by the real-time event backend.
 type HybridMonitor struct {
-	backend        events.Backend
-	cache          *state.Cache
-	aggregator     *reporting.Aggregator
-	logger         *logging.Logger
-	directories    []string
-	pollInterval   time.Duration
-	ignorePatterns []string
-	ignoreBloom    *filters.BloomFilter
-	changeHandler  func(reporting.Change)
+	// ctx is the context the monitor runs under; walks stop once it is
+	// canceled. It is nil outside Run, for example in a dry run.
+	ctx          context.Context
+	backend      events.Backend
+	cache        *state.Cache
+	aggregator   *reporting.Aggregator
+	logger       *logging.Logger
+	directories  []string
+	pollInterval time.Duration
+	// ignore holds the compiled ignore patterns. It is replaced wholesale
+	// when the ignore files change, so each match sees one consistent set.
+	ignore atomic.Pointer[ignoreSet]
+	// includePatterns, when non-empty, restricts tracking to files matching
+	// at least one of them. It is checked before the ignore patterns.
+	includePatterns []string
+	// skipHidden excludes dot-files and dot-directories below each root.
+	skipHidden    bool
+	changeHandler func(reporting.Change)
+	filter        func(reporting.Change) (reporting.Change, bool)
+	clock         clock.Clock
+	detectBinary  bool
+	paused        atomic.Bool
+	rescan        chan struct{}
+
+	// ignoreFiles are re-read through reloadIgnore when their size or
+	// modification time changes; ignoreStamps records the last seen values.
+	ignoreFiles    []string
+	reloadIgnore   func() ([]string, error)
+	ignoreReloadMu sync.Mutex
+	ignoreStamps   map[string]fileStamp
+
+	// inaccessible holds, per watched root, the paths the latest scan could
+	// not read because of missing permissions.
+	inaccessibleMu sync.Mutex
+	inaccessible   map[string]map[string]struct{}
+
+	// maxTracked caps the number of cached files; zero or less is unlimited.
+	// limitRoot names the watched directory where the cap was first hit and
+	// limitHit records whether the current scan pass refused any file.
+	maxTracked int
+	limitMu    sync.Mutex
+	limitRoot  string
+	limitHit   bool
+
+	// lostRoots holds the watched directories found missing; see checkRoot.
+	rootsMu   sync.Mutex
+	lostRoots map[string]struct{}
+	// scannedAt holds when the latest safety scan of each watched directory
+	// started; see scanTimestamp.
+	scannedAt map[string]time.Time
+
+	// warmUp is the settling period after Run starts; warming is set while
+	// it lasts. See HybridMonitorConfig.WarmUp.
+	warmUp  time.Duration
+	warming atomic.Bool
+
+	// minPoll and maxPoll bound the adaptive safety-scan interval; adaptive
+	// scheduling is off when maxPoll is zero. realtimeSeen and scanFound
+	// count the changes reported by the backend and found by safety scans
+	// since the last adjustment; discrepancy smooths their ratio.
+	minPoll      time.Duration
+	maxPoll      time.Duration
+	realtimeSeen atomic.Int64
+	scanFound    atomic.Int64
+	scheduleMu   sync.Mutex
+	currentPoll  time.Duration
+	discrepancy  float64
+
+	// ignoreOlderThan and ignoreNewerThan drop changes to files whose
+	// modification time is outside the window; see outsideAgeWindow.
+	ignoreOlderThan time.Duration
+	ignoreNewerThan time.Duration
+
+	// bloomCache is the file the ignore Bloom filter is cached in, or empty.
+	bloomCache string
+	// concurrency bounds the signatures computed, and roots added to the
+	// backend, in parallel.
+	concurrency int
+	// trackMode and trackOwner report ChangePerm and ChangeOwner for files
+	// whose mode or owner alone changed.
+	trackMode  bool
+	trackOwner bool
+
+	// onProgress receives the progress of the first safety scan and is
+	// cleared once that scan ends; progress tracks the scan in flight. Both
+	// are only used by the goroutine running safety scans.
+	onProgress func(ScanProgress)
+	progress   *scanProgress
+
+	// scanOff disables periodic safety scans; schedule, when set, times
+	// them instead of the poll interval.
+	scanOff  bool
+	schedule *clock.Schedule
+
+	// churn counts changes per directory to warn about excessive churn; it
+	// is nil when the guard is disabled. onChurn receives its warnings.
+	churn   *churnGuard
+	onChurn func(ChurnReport)
+
+	// sinks receive every recorded change; onSinkError receives their
+	// failures.
+	sinks       []LogSink
+	onSinkError func(error)
+
+	// signature computes the signatures of changed and scanned files.
+	signature state.SignatureConfig
 }
 
 // HybridMonitorConfig encapsulates the dependencies and configuration required
@@ -49,7 +152,211 @@ type HybridMonitorConfig struct {
 	Directories    []string
 	PollInterval   time.Duration
 	IgnorePatterns []string
-	OnChange       func(reporting.Change)
+	// IncludePatterns restricts tracking to files matching at least one glob.
+	// Files that match none are ignored before IgnorePatterns are consulted.
+	// Empty tracks every file.
+	IncludePatterns []string
+	OnChange        func(reporting.Change)
+	// Filter, when set, may rewrite or drop each change after ignore matching
+	// and before it is recorded. See ControllerConfig.Filter.
+	Filter func(reporting.Change) (reporting.Change, bool)
+	Clock  clock.Clock
+	// DetectBinary flags created and modified files as binary on the changes
+	// they produce. Small files reuse the bytes read for hashing; larger files
+	// cost one extra read of their first 8KB.
+	DetectBinary bool
+	// IgnoreFiles lists the files IgnorePatterns were loaded from. When one
+	// is created, modified, or removed, ReloadIgnore is called and its result
+	// replaces the active patterns without restarting the monitor. Files are
+	// checked when an event names them and before every safety scan, so files
+	// outside the watched directories are picked up too.
+	IgnoreFiles  []string
+	ReloadIgnore func() ([]string, error)
+	// EventBuffer sizes the event channel of the backend created when Backend
+	// is nil. See events.NewPollingBackend for the memory tradeoff.
+	EventBuffer int
+	// MaxTrackedFiles caps how many files the cache may hold. Once reached,
+	// new files are not tracked and a single ChangeLimit change is reported.
+	// Zero uses DefaultMaxTrackedFiles and a negative value disables the cap.
+	MaxTrackedFiles int
+	// SkipHidden excludes files and directories whose name starts with `.`
+	// beneath each watched directory. Hidden directories are not descended
+	// into. The watched directories themselves are never skipped.
+	SkipHidden bool
+	// WarmUp is a settling period after Run starts. A scan at the start of
+	// it builds the cache baseline without reporting changes, backend events
+	// are dropped until it ends, and a scan at its end reports anything that
+	// changed since the baseline, so the backend snapshot and the first scan
+	// cannot produce spurious churn. Marker changes are reported throughout.
+	// Zero disables the warm-up.
+	WarmUp time.Duration
+	// MinPollInterval and MaxPollInterval, when both are positive, make the
+	// safety-scan interval adaptive. It starts at PollInterval, clamped to
+	// the bounds, doubles after every periodic scan that finds no change the
+	// backend missed, and halves while the discrepancy ratio (see
+	// DiscrepancyRatio) is at or above AdaptiveScanThreshold. Otherwise scans
+	// run every PollInterval.
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+	// IgnoreOlderThan and IgnoreNewerThan, when positive, drop changes to
+	// files last modified longer ago than IgnoreOlderThan or more recently
+	// than IgnoreNewerThan. Deletions are judged by the cached modification
+	// time. The files stay tracked, and the age is re-evaluated against the
+	// current time on every event and scan, so a file can age out of the
+	// window or, once modified again, back into it.
+	IgnoreOlderThan time.Duration
+	IgnoreNewerThan time.Duration
+	// BloomCache, when set, is a file caching the Bloom filter built from
+	// the ignore patterns. A filter cached for the same pattern set is loaded
+	// instead of being rebuilt; otherwise the filter is built and the file
+	// rewritten. A cache that cannot be written is logged and ignored.
+	BloomCache string
+	// ScanConcurrency is the number of file signatures computed in parallel
+	// by scans, and of directories added to the backend at once when Run
+	// starts. Zero uses runtime.GOMAXPROCS(0); 1 scans serially.
+	ScanConcurrency int
+	// OnScanProgress, when set, receives the progress of the first safety
+	// scan, at most once per ScanProgressInterval and once more when the
+	// scan finishes. Scans shorter than the interval report nothing.
+	OnScanProgress func(ScanProgress)
+	// TrackMode reports a ChangePerm change when a file's permission bits
+	// change without its content changing. Without it, such changes go
+	// unnoticed. A backend created by the monitor tracks modes too.
+	TrackMode bool
+	// TrackOwner likewise reports a ChangeOwner change when a file's owning
+	// user or group changes. Ownership is not recorded on Windows, where it
+	// has no effect.
+	TrackOwner bool
+	// DisableSafetyScan turns periodic safety scans off, leaving change
+	// detection to the backend alone: anything it misses, such as changes
+	// on network filesystems or during an event overflow, goes unnoticed
+	// until the next start. The warm-up scans and the scan queued by
+	// SetPaused(false) still run.
+	DisableSafetyScan bool
+	// ScanSchedule, when set, runs periodic safety scans at the minutes it
+	// matches instead of every PollInterval, for example at night only.
+	// Adaptive scheduling does not apply to it.
+	ScanSchedule *clock.Schedule
+	// ChurnThreshold is the number of changes within one second beneath a
+	// single directory above which an error suggesting an ignore pattern is
+	// logged, at most once a minute per directory, and the directory is
+	// listed by Churning. Zero uses DefaultChurnThreshold and a negative
+	// value disables the guard.
+	ChurnThreshold int
+	// OnChurn, when set, receives each churn warning as well.
+	OnChurn func(ChurnReport)
+	// Sinks receive every change that reaches the aggregator, markers
+	// included, in order. A sink error is logged, passed to OnSinkError when
+	// set, and does not keep the change from the remaining sinks.
+	Sinks       []LogSink
+	OnSinkError func(error)
+	// Signature selects which files have their content hashed into their
+	// signatures, and how much of each; the zero value hashes files of up
+	// to 4KB. It applies to the default backend as well.
+	Signature state.SignatureConfig
+}
+
+// ScanProgressInterval is the minimum time between two reports passed to
+// HybridMonitorConfig.OnScanProgress.
+const ScanProgressInterval = time.Second
+
+// ScanProgress describes how far the monitor's first scan has got.
+type ScanProgress struct {
+	// Files is the number of files scanned so far, across all directories.
+	Files int
+	// Directory is the watched directory being scanned.
+	Directory string
+	// Elapsed is the time since the scan started.
+	Elapsed time.Duration
+	// Done is set on the last report, once the scan has finished.
+	Done bool
+}
+
+// scanProgress throttles the reports of a scan. Its methods do nothing on a
+// nil receiver, so scans without a progress callback need no checks.
+type scanProgress struct {
+	report  func(ScanProgress)
+	clock   clock.Clock
+	started time.Time
+	last    time.Time
+	files   int
+	dir     string
+	sent    bool
+}
+
+func newScanProgress(report func(ScanProgress), clk clock.Clock) *scanProgress {
+	now := clk.Now()
+	return &scanProgress{report: report, clock: clk, started: now, last: now}
+}
+
+// add counts n more files scanned in dir and reports the total once
+// ScanProgressInterval has passed since the previous report.
+func (p *scanProgress) add(dir string, n int) {
+	if p == nil {
+		return
+	}
+	p.files += n
+	p.dir = dir
+	now := p.clock.Now()
+	if now.Sub(p.last) < ScanProgressInterval {
+		return
+	}
+	p.last = now
+	p.sent = true
+	p.report(ScanProgress{Files: p.files, Directory: dir, Elapsed: now.Sub(p.started)})
+}
+
+// finish sends the final report, provided an earlier one was sent.
+func (p *scanProgress) finish() {
+	if p == nil || !p.sent {
+		return
+	}
+	p.report(ScanProgress{Files: p.files, Directory: p.dir, Elapsed: p.clock.Now().Sub(p.started), Done: true})
+}
+
+// AdaptiveScanThreshold is the discrepancy ratio at or above which an adaptive
+// safety-scan interval is shortened.
+const AdaptiveScanThreshold = 0.1
+
+// DefaultMaxTrackedFiles is the tracked file cap applied when none is
+// configured. It is far above typical project trees but keeps a watch
+// accidentally pointed at a home directory or filesystem root from growing
+// the cache without bound.
+const DefaultMaxTrackedFiles = 500000
+
+// ChangeBoot is the type of the marker change recorded each time a controller
+// starts; its path is a placeholder or the watched directory it applies to.
+const ChangeBoot = "BOOT"
+
+// ChangeLimit is the change type reported, with the watched directory as its
+// path, when the tracked file cap is reached.
+const ChangeLimit = "LIMIT"
+
+// ChangePerm and ChangeOwner are the types of the changes reported, with
+// TrackMode and TrackOwner, when a file's permission bits or owning user or
+// group change while its content and modification time do not. A file whose
+// content changed too is reported as a modification.
+const (
+	ChangePerm  = "PERM"
+	ChangeOwner = "CHOWN"
+)
+
+// ChangeRootLost and ChangeRootRestored are reported, with the watched
+// directory as their path, when a watched directory disappears (for example
+// because it was deleted or unmounted) and when it reappears.
+const (
+	ChangeRootLost     = "ROOT_LOST"
+	ChangeRootRestored = "ROOT_RESTORED"
+)
+
+// IsMarker reports whether changeType is one of the synthetic types above,
+// which describe the watcher itself rather than a change to a file.
+func IsMarker(changeType string) bool {
+	switch changeType {
+	case ChangeBoot, ChangeLimit, ChangeRootLost, ChangeRootRestored:
+		return true
+	}
+	return false
 }
 
 // NewHybridMonitor validates the provided configuration and constructs a new
@@ -63,7 +370,7 @@ func NewHybridMonitor(cfg HybridMonitorConfig) (*HybridMonitor, error) {
 	backend := cfg.Backend
 	if backend == nil {
 		var err error
-		backend, err = events.NewBackend()
+		backend, err = events.NewBackend(events.BackendConfig{EventBuffer: cfg.EventBuffer, SkipHidden: cfg.SkipHidden, Concurrency: cfg.ScanConcurrency, TrackMode: cfg.TrackMode, TrackOwner: cfg.TrackOwner, Signature: cfg.Signature})
 		if err != nil {
 			return nil, err
 		}
@@ -79,44 +386,94 @@ func NewHybridMonitor(cfg HybridMonitorConfig) (*HybridMonitor, error) {
 		pollInterval = 30 * time.Second
 	}
 
-	patterns := make([]string, 0, len(cfg.IgnorePatterns))
-	for _, pattern := range cfg.IgnorePatterns {
-		pattern = strings.TrimSpace(pattern)
-		if pattern != "" {
-			patterns = append(patterns, pattern)
+	ignoreFiles := make([]string, 0, len(cfg.IgnoreFiles))
+	stamps := make(map[string]fileStamp, len(cfg.IgnoreFiles))
+	for _, path := range cfg.IgnoreFiles {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
 		}
+		ignoreFiles = append(ignoreFiles, path)
+		stamps[path] = statFile(path)
 	}
-	var bloom *filters.BloomFilter
-	if len(patterns) > 0 {
-		bloom = filters.NewBloomFilter(len(patterns)*8, 0.01)
-		for _, pattern := range patterns {
-			for _, token := range filters.ExtractPatternTokens(pattern) {
-				bloom.Add(token)
-			}
-		}
+
+	maxTracked := cfg.MaxTrackedFiles
+	if maxTracked == 0 {
+		maxTracked = DefaultMaxTrackedFiles
+	}
+
+	concurrency := cfg.ScanConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
 	}
 
-	return &HybridMonitor{
-		backend:        backend,
-		cache:          cache,
-		aggregator:     cfg.Aggregator,
-		logger:         cfg.Logger,
-		directories:    cfg.Directories,
-		pollInterval:   pollInterval,
-		ignorePatterns: patterns,
-		ignoreBloom:    bloom,
-		changeHandler:  cfg.OnChange,
-	}, nil
+	m := &HybridMonitor{
+		backend:         backend,
+		cache:           cache,
+		aggregator:      cfg.Aggregator,
+		logger:          cfg.Logger,
+		directories:     cfg.Directories,
+		pollInterval:    pollInterval,
+		includePatterns: trimPatterns(cfg.IncludePatterns),
+		skipHidden:      cfg.SkipHidden,
+		changeHandler:   cfg.OnChange,
+		filter:          cfg.Filter,
+		clock:           clock.OrReal(cfg.Clock),
+		detectBinary:    cfg.DetectBinary,
+		rescan:          make(chan struct{}, 1),
+		ignoreFiles:     ignoreFiles,
+		reloadIgnore:    cfg.ReloadIgnore,
+		ignoreStamps:    stamps,
+		maxTracked:      maxTracked,
+		lostRoots:       make(map[string]struct{}),
+		scannedAt:       make(map[string]time.Time),
+		warmUp:          cfg.WarmUp,
+		currentPoll:     pollInterval,
+		ignoreOlderThan: cfg.IgnoreOlderThan,
+		ignoreNewerThan: cfg.IgnoreNewerThan,
+		bloomCache:      cfg.BloomCache,
+		concurrency:     concurrency,
+		onProgress:      cfg.OnScanProgress,
+		trackMode:       cfg.TrackMode,
+		trackOwner:      cfg.TrackOwner,
+		scanOff:         cfg.DisableSafetyScan,
+		schedule:        cfg.ScanSchedule,
+		churn:           newChurnGuard(cfg.ChurnThreshold),
+		onChurn:         cfg.OnChurn,
+		sinks:           cfg.Sinks,
+		onSinkError:     cfg.OnSinkError,
+		signature:       cfg.Signature,
+	}
+	if cfg.MinPollInterval > 0 && cfg.MaxPollInterval >= cfg.MinPollInterval {
+		m.minPoll, m.maxPoll = cfg.MinPollInterval, cfg.MaxPollInterval
+		m.currentPoll = min(max(pollInterval, m.minPoll), m.maxPoll)
+	}
+	m.ignore.Store(m.compileIgnore(cfg.IgnorePatterns))
+	return m, nil
 }
 
 // Run starts the hybrid monitoring process and blocks until the provided context
 // is canceled. It launches goroutines for consuming real-time events and
-// performing periodic safety scans.
+// performing periodic safety scans. It returns nil once ctx is canceled, or
+// earlier with an error when monitoring cannot continue: the backend's event
+// channel closed, or one of the goroutines panicked.
 func (m *HybridMonitor) Run(ctx context.Context) error {
-	for _, dir := range m.directories {
-		if err := m.backend.Add(dir); err != nil {
-			return err
+	m.ctx = ctx
+	if err := m.addRoots(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
 		}
+		return err
+	}
+	m.warming.Store(m.warmUp > 0)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m.ctx = runCtx
+	var fatalOnce sync.Once
+	var fatal error
+	fail := func(err error) {
+		fatalOnce.Do(func() { fatal = err })
+		cancel()
 	}
 
 	var wg sync.WaitGroup
@@ -124,19 +481,58 @@ func (m *HybridMonitor) Run(ctx context.Context) error {
 
 	go func() {
 		defer wg.Done()
-		m.consumeEvents(ctx)
+		defer recoverFatal(fail)
+		m.consumeEvents(runCtx)
+		if runCtx.Err() == nil {
+			fail(errors.New("watcher: event backend closed its event channel"))
+		}
 	}()
 
 	go func() {
 		defer wg.Done()
-		m.safetyScanLoop(ctx)
+		defer recoverFatal(fail)
+		m.safetyScanLoop(runCtx)
 	}()
 
-	<-ctx.Done()
+	<-runCtx.Done()
 	wg.Wait()
+	return fatal
+}
+
+// addRoots adds every watched directory to the backend, up to m.concurrency
+// at a time, since each addition walks the whole tree. It returns the first
+// error, in directory order.
+func (m *HybridMonitor) addRoots(ctx context.Context) error {
+	errs := make([]error, len(m.directories))
+	slots := make(chan struct{}, max(m.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, dir := range m.directories {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, dir string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = events.AddContext(ctx, m.backend, dir)
+		}(i, dir)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// recoverFatal turns a panic in a monitor goroutine into an error passed to
+// fail, so the failure stops the monitor visibly instead of crashing the
+// process.
+func recoverFatal(fail func(error)) {
+	if r := recover(); r != nil {
+		fail(fmt.Errorf("watcher: monitor panicked: %v", r))
+	}
+}
+
 func (m *HybridMonitor) consumeEvents(ctx context.Context) {
 	for {
 		select {
@@ -151,6 +547,10 @@ func (m *HybridMonitor) consumeEvents(ctx context.Context) {
 			if !ok {
 				continue
 			}
+			if errors.Is(err, fs.ErrNotExist) && !m.checkRoots() {
+				// A missing watched directory was reported by checkRoot.
+				continue
+			}
 			if m.logger != nil {
 				m.logger.Errorf("event backend error: %v", err)
 			}
@@ -158,30 +558,204 @@ func (m *HybridMonitor) consumeEvents(ctx context.Context) {
 	}
 }
 
+// safetyScanLoop runs the warm-up scans, the periodic safety scans, and the
+// scans requested through m.rescan until ctx is canceled. Periodic scans run
+// every PollInterval, at the times of the scan schedule, or not at all.
 func (m *HybridMonitor) safetyScanLoop(ctx context.Context) {
-	ticker := time.NewTicker(m.pollInterval)
-	defer ticker.Stop()
+	// tick fires periodic scans by interval and scheduled by the scan
+	// schedule; each stays nil when that mode is not in use.
+	var tick, scheduled <-chan time.Time
+	var ticker *time.Ticker
+	switch {
+	case m.scanOff:
+		m.debugf("periodic safety scans disabled")
+	case m.schedule != nil:
+		scheduled = m.nextScheduledScan()
+	default:
+		ticker = time.NewTicker(m.PollInterval())
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	// warmed fires once the warm-up ends; it stays nil without one.
+	var warmed <-chan time.Time
+	if m.warming.Load() {
+		timer := time.NewTimer(m.warmUp)
+		defer timer.Stop()
+		warmed = timer.C
+		if !m.paused.Load() {
+			m.performSafetyScan()
+		}
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			m.performSafetyScan()
+		case <-warmed:
+			warmed = nil
+			m.warming.Store(false)
+			m.debugf("warm-up of %s complete", m.warmUp)
+			if !m.paused.Load() {
+				m.performSafetyScan()
+			}
+		case <-tick:
+			if !m.paused.Load() {
+				m.performSafetyScan()
+				if m.maxPoll > 0 {
+					ticker.Reset(m.adaptPollInterval())
+				}
+			}
+		case <-scheduled:
+			if !m.paused.Load() {
+				m.performSafetyScan()
+			}
+			scheduled = m.nextScheduledScan()
+		case <-m.rescan:
+			if !m.paused.Load() {
+				m.performSafetyScan()
+			}
+		}
+	}
+}
+
+// nextScheduledScan returns a channel that fires at the next time matched by
+// the scan schedule, or nil when it matches none.
+func (m *HybridMonitor) nextScheduledScan() <-chan time.Time {
+	now := m.clock.Now()
+	next := m.schedule.Next(now)
+	if next.IsZero() {
+		if m.logger != nil {
+			m.logger.Errorf("safety scan schedule %q matches no time; periodic scans disabled", m.schedule)
+		}
+		return nil
+	}
+	m.debugf("next scheduled safety scan at %s", next.Format(time.RFC3339))
+	return m.clock.After(next.Sub(now))
+}
+
+// adaptPollInterval updates the discrepancy ratio with the changes counted
+// since the previous periodic scan and returns the next safety-scan interval:
+// twice the current one when the scan found nothing the backend missed, half
+// of it while the ratio is at or above AdaptiveScanThreshold, within the
+// configured bounds.
+func (m *HybridMonitor) adaptPollInterval() time.Duration {
+	found := m.scanFound.Swap(0)
+	seen := m.realtimeSeen.Swap(0)
+
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+	if total := found + seen; total > 0 {
+		m.discrepancy = (m.discrepancy + float64(found)/float64(total)) / 2
+	}
+	previous := m.currentPoll
+	switch {
+	case found == 0:
+		m.currentPoll = min(m.currentPoll*2, m.maxPoll)
+	case m.discrepancy >= AdaptiveScanThreshold:
+		m.currentPoll = max(m.currentPoll/2, m.minPoll)
+	}
+	if m.currentPoll != previous {
+		m.debugf("safety scan interval %s -> %s (discrepancy %.2f)", previous, m.currentPoll, m.discrepancy)
+	}
+	return m.currentPoll
+}
+
+// PollInterval returns the current safety-scan interval. It only differs from
+// the configured PollInterval when adaptive scheduling is enabled.
+func (m *HybridMonitor) PollInterval() time.Duration {
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+	return m.currentPoll
+}
+
+// DiscrepancyRatio returns the smoothed share of changes that safety scans
+// found because the backend missed them, between 0 (the backend reports
+// everything) and 1. It is only tracked with adaptive scheduling enabled.
+func (m *HybridMonitor) DiscrepancyRatio() float64 {
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+	return m.discrepancy
+}
+
+// SetPaused suspends or resumes change recording. While paused, backend events
+// are dropped and safety scans are skipped, leaving the cache untouched so the
+// scan queued on resume reconciles anything that changed in the meantime.
+func (m *HybridMonitor) SetPaused(paused bool) {
+	if m.paused.Swap(paused) && !paused {
+		select {
+		case m.rescan <- struct{}{}:
+		default:
 		}
 	}
 }
 
+// Paused reports whether change recording is suspended.
+func (m *HybridMonitor) Paused() bool {
+	return m.paused.Load()
+}
+
 func (m *HybridMonitor) performSafetyScan() {
+	if m.onProgress != nil {
+		m.progress = newScanProgress(m.onProgress, m.clock)
+		m.onProgress = nil
+		defer func() {
+			if !m.canceled() {
+				m.progress.finish()
+			}
+			m.progress = nil
+		}()
+	}
+	m.checkIgnoreFiles()
+	m.limitMu.Lock()
+	m.limitHit = false
+	m.limitMu.Unlock()
 	for _, dir := range m.directories {
-		if err := m.scanDirectory(dir); err != nil && m.logger != nil {
+		if m.canceled() {
+			return
+		}
+		if !m.checkRoot(dir) {
+			continue
+		}
+		if err := m.scanDirectory(dir); err != nil && !m.canceled() && m.logger != nil {
 			m.logger.Errorf("safety scan error: %v", err)
 		}
 	}
+	m.clearLimitIfUnderCap()
 }
 
+// handleEvent reconciles one backend event with the cache. With debug logging
+// enabled every raw event is logged as "backend event" together with the
+// reason it was skipped; recorded changes are logged by emit.
 func (m *HybridMonitor) handleEvent(event events.Event) {
-	if m.shouldIgnore(event.Path) {
+	m.debugf("backend event %s %s", event.Type, event.Path)
+	if m.isIgnoreFile(event.Path) {
+		m.checkIgnoreFiles()
+	}
+	if m.paused.Load() {
+		m.debugf("skip %s: monitor paused", event.Path)
+		return
+	}
+	if m.warming.Load() {
+		// Left to the scan that ends the warm-up.
+		m.debugf("skip %s: warming up", event.Path)
+		return
+	}
+	root := m.rootFor(event.Path)
+	if m.rootLost(root) && !m.checkRoot(root) {
+		m.debugf("skip %s: watched directory missing", event.Path)
+		return
+	}
+	if m.skipHidden && state.HiddenBelow(event.Path, root) {
+		m.debugf("skip %s: hidden", event.Path)
+		return
+	}
+	if !m.shouldWatch(event.Path) {
+		m.debugf("skip %s: not matched by include patterns", event.Path)
+		return
+	}
+	if pattern, ok := m.matchIgnore(event.Path); ok {
+		m.debugf("skip %s: ignored by pattern %q", event.Path, pattern)
 		return
 	}
 
@@ -190,19 +764,27 @@ func (m *HybridMonitor) handleEvent(event events.Event) {
 		// For delete events, we can't get the file size anymore
 		prevSig, _ := m.cache.Get(event.Path)
 		m.cache.Delete(event.Path)
-		m.recordChangeWithSize(event.Path, events.EventDelete, event.Timestamp, 0, prevSig.Size, 0)
+		if m.outsideAgeWindow(event.Path, prevSig.ModTime) {
+			return
+		}
+		m.recordChangeWithSize(reporting.SourceRealtime, event.Path, events.EventDelete, event.Timestamp, 0, prevSig.Size, 0, false)
 	case events.EventCreate, events.EventModify:
 		info, err := os.Stat(event.Path)
 		if err != nil {
 			if os.IsNotExist(err) {
 				prevSig, _ := m.cache.Get(event.Path)
 				m.cache.Delete(event.Path)
-				m.recordChangeWithSize(event.Path, events.EventDelete, event.Timestamp, 0, prevSig.Size, 0)
+				if m.outsideAgeWindow(event.Path, prevSig.ModTime) {
+					return
+				}
+				m.recordChangeWithSize(reporting.SourceRealtime, event.Path, events.EventDelete, event.Timestamp, 0, prevSig.Size, 0, false)
+				return
 			}
+			m.debugf("skip %s: stat failed: %v", event.Path, err)
 			return
 		}
 
-		sig, err := state.ComputeSignature(event.Path, info)
+		sig, err := m.signature.Compute(event.Path, info)
 		if err != nil {
 			if m.logger != nil {
 				m.logger.Errorf("compute signature: %v", err)
@@ -211,34 +793,221 @@ func (m *HybridMonitor) handleEvent(event events.Event) {
 		}
 
 		prev, ok := m.cache.Get(event.Path)
+		if !ok && !m.admit(root) {
+			m.debugf("skip %s: tracked file limit reached", event.Path)
+			return
+		}
 		m.cache.Set(event.Path, sig)
+		if m.outsideAgeWindow(event.Path, sig.ModTime) {
+			return
+		}
 		if !ok {
 			// New file
-			m.recordChangeWithSize(event.Path, events.EventCreate, event.Timestamp, sig.Size, 0, sig.Size)
+			m.recordChangeWithSize(reporting.SourceRealtime, event.Path, events.EventCreate, event.Timestamp, sig.Size, 0, sig.Size, m.isBinary(event.Path, sig))
 			return
 		}
-		if !prev.Equal(sig) {
-			// Modified file - calculate size delta
-			sizeDelta := sig.Size - prev.Size
-			m.recordChangeWithSize(event.Path, events.EventModify, event.Timestamp, sig.Size, prev.Size, sizeDelta)
+		if prev.Equal(sig) {
+			attributes := m.attributeChanges(prev, sig)
+			for _, changeType := range attributes {
+				m.recordChangeWithSize(reporting.SourceRealtime, event.Path, changeType, event.Timestamp, sig.Size, prev.Size, 0, m.isBinary(event.Path, sig))
+			}
+			if len(attributes) == 0 {
+				m.debugf("skip %s: signature unchanged", event.Path)
+			}
+			return
 		}
+		// Modified file - calculate size delta
+		sizeDelta := sig.Size - prev.Size
+		m.recordChangeWithSize(reporting.SourceRealtime, event.Path, events.EventModify, event.Timestamp, sig.Size, prev.Size, sizeDelta, m.isBinary(event.Path, sig))
 	default:
-		m.recordChange(event.Path, event.Type, event.Timestamp)
+		m.emit(reporting.Change{Path: event.Path, Type: event.Type, Timestamp: event.Timestamp, Source: reporting.SourceRealtime})
 	}
 }
 
-func (m *HybridMonitor) scanDirectory(dir string) error {
-	reference := m.cache.FilesUnder(dir)
-	seen := make(map[string]struct{}, len(reference))
+// attributeChanges returns the change types, among the tracked ChangePerm and
+// ChangeOwner, describing how a file whose content is unchanged differs from
+// its previous signature.
+func (m *HybridMonitor) attributeChanges(previous, current state.FileSignature) []string {
+	var changes []string
+	if m.trackMode && previous.ModeChanged(current) {
+		changes = append(changes, ChangePerm)
+	}
+	if m.trackOwner && previous.OwnerChanged(current) {
+		changes = append(changes, ChangeOwner)
+	}
+	return changes
+}
+
+// ignoreSet is a compiled, immutable set of ignore patterns together with the
+// Bloom filter built from their tokens.
+type ignoreSet struct {
+	patterns []string
+	bloom    *filters.BloomFilter
+}
+
+// compileIgnorePatterns trims the supplied patterns, drops blanks, and builds
+// the Bloom filter used to short-circuit ignore checks.
+func compileIgnorePatterns(raw []string) *ignoreSet {
+	patterns := trimPatterns(raw)
+	return &ignoreSet{patterns: patterns, bloom: filters.BuildIgnoreBloom(patterns)}
+}
 
-	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+// compileIgnore is compileIgnorePatterns, loading the Bloom filter from the
+// monitor's bloom cache when it was built for the same patterns and updating
+// the cache when it was not.
+func (m *HybridMonitor) compileIgnore(raw []string) *ignoreSet {
+	patterns := trimPatterns(raw)
+	if m.bloomCache == "" || len(patterns) == 0 {
+		return compileIgnorePatterns(patterns)
+	}
+	if bloom, ok := filters.LoadIgnoreBloom(m.bloomCache, patterns); ok {
+		m.debugf("loaded ignore filter for %d patterns from %s", len(patterns), m.bloomCache)
+		return &ignoreSet{patterns: patterns, bloom: bloom}
+	}
+	set := compileIgnorePatterns(patterns)
+	if err := filters.SaveIgnoreBloom(m.bloomCache, patterns, set.bloom); err != nil && m.logger != nil {
+		m.logger.Errorf("cache ignore filter: %v", err)
+	}
+	return set
+}
+
+// SetIgnorePatterns replaces the active ignore patterns. Matches already in
+// progress finish against the previous set. Cached files that the new
+// patterns ignore are forgotten without reporting a change, and a safety scan
+// is queued so files no longer ignored are picked up.
+func (m *HybridMonitor) SetIgnorePatterns(patterns []string) {
+	m.ignore.Store(m.compileIgnore(patterns))
+	for _, dir := range m.directories {
+		for path := range m.cache.FilesUnder(dir) {
+			if m.shouldIgnore(path) {
+				m.cache.Delete(path)
+			}
+		}
+	}
+	select {
+	case m.rescan <- struct{}{}:
+	default:
+	}
+}
+
+// fileStamp captures the attributes used to notice an ignore file change.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+func (m *HybridMonitor) isIgnoreFile(path string) bool {
+	for _, file := range m.ignoreFiles {
+		if file == path {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIgnoreFiles reloads the ignore patterns when any ignore file changed
+// since the last check. A failed reload is logged and the current patterns
+// stay in effect.
+func (m *HybridMonitor) checkIgnoreFiles() {
+	if m.reloadIgnore == nil || len(m.ignoreFiles) == 0 {
+		return
+	}
+	m.ignoreReloadMu.Lock()
+	defer m.ignoreReloadMu.Unlock()
+
+	changed := false
+	for _, path := range m.ignoreFiles {
+		if stamp := statFile(path); stamp != m.ignoreStamps[path] {
+			m.ignoreStamps[path] = stamp
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	patterns, err := m.reloadIgnore()
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Errorf("reload ignore patterns: %v", err)
+		}
+		return
+	}
+	m.SetIgnorePatterns(patterns)
+	if m.logger != nil {
+		m.logger.Infof("reloaded %d ignore patterns", len(patterns))
+	}
+}
+
+// trimPatterns returns raw with surrounding whitespace removed, apart from
+// backslash-escaped trailing whitespace, and blank entries dropped.
+func trimPatterns(raw []string) []string {
+	patterns := make([]string, 0, len(raw))
+	for _, pattern := range raw {
+		pattern = filters.TrimPattern(pattern)
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// NotIncludedPattern is the DryRunReport.ByPattern key counting files skipped
+// because they match none of the include patterns.
+const NotIncludedPattern = "(not matched by include patterns)"
+
+// walkFiles visits every regular file beneath dir that is not excluded by the
+// ignore patterns. When ignored is non-nil it is called with each skipped path
+// and the pattern that excluded it.
+//
+// Entries that cannot be read because of missing permissions, including files
+// whose visit fails with a permission error, are skipped rather than aborting
+// the walk and are returned as denied; unreadable directories are not
+// descended into. With skipHidden, hidden entries below dir are skipped
+// without being reported to ignored.
+func (m *HybridMonitor) walkFiles(dir string, visit func(path string, info fs.FileInfo) error, ignored func(path, pattern string)) (denied []string, err error) {
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if m.canceled() {
+			return m.ctx.Err()
+		}
 		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				denied = append(denied, path)
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 			return err
 		}
+		if m.skipHidden && path != dir && state.IsHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if d.IsDir() {
 			return nil
 		}
-		if m.shouldIgnore(path) {
+		if !m.shouldWatch(path) {
+			if ignored != nil {
+				ignored(path, NotIncludedPattern)
+			}
+			return nil
+		}
+		if pattern, ok := m.matchIgnore(path); ok {
+			if ignored != nil {
+				ignored(path, pattern)
+			}
 			return nil
 		}
 
@@ -246,88 +1015,528 @@ func (m *HybridMonitor) scanDirectory(dir string) error {
 		if err != nil {
 			return err
 		}
+		if err := visit(path, info); err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				denied = append(denied, path)
+				return nil
+			}
+			return err
+		}
+		return nil
+	})
+	return denied, err
+}
+
+// signatureBatch is the number of files walkSignatures hashes between two
+// progress updates.
+const signatureBatch = 1024
+
+// walkSignatures walks dir like walkFiles, computes the signatures of the
+// files it tracks with up to m.concurrency workers, and passes each to visit
+// in walk order. Signatures are computed in batches, each counted towards
+// progress when it completes. Files removed since the walk are skipped, files
+// whose signature cannot be computed for lack of permission are added to
+// denied, and any other failure aborts the walk.
+func (m *HybridMonitor) walkSignatures(dir string, progress *scanProgress, visit func(path string, sig state.FileSignature)) (denied []string, err error) {
+	var files []state.FileEntry
+	denied, err = m.walkFiles(dir, func(path string, info fs.FileInfo) error {
+		files = append(files, state.FileEntry{Path: path, Info: info})
+		return nil
+	}, nil)
+	if err != nil {
+		return denied, err
+	}
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for start := 0; start < len(files); start += signatureBatch {
+		batch := files[start:min(start+signatureBatch, len(files))]
+		for i, result := range m.signature.ComputeAll(ctx, batch, m.concurrency) {
+			switch {
+			case result.Err == nil:
+				visit(batch[i].Path, result.Signature)
+			case errors.Is(result.Err, fs.ErrNotExist):
+			case errors.Is(result.Err, fs.ErrPermission):
+				denied = append(denied, batch[i].Path)
+			default:
+				return denied, result.Err
+			}
+		}
+		progress.add(dir, len(batch))
+	}
+	return denied, nil
+}
+
+// DryRunReport summarises what a full scan would track without recording any
+// changes. Ignored files are broken down by the pattern that matched them.
+type DryRunReport struct {
+	Tracked   int
+	Ignored   int
+	ByPattern map[string]int
+	// Inaccessible lists paths skipped because they could not be read.
+	Inaccessible []string
+}
 
-		sig, err := state.ComputeSignature(path, info)
+// DryRun walks every configured directory once and reports how many files
+// would be tracked and how many are ignored. The cache, aggregator, and change
+// handler are left untouched.
+func (m *HybridMonitor) DryRun() (DryRunReport, error) {
+	report := DryRunReport{ByPattern: make(map[string]int)}
+	for _, dir := range m.directories {
+		denied, err := m.walkFiles(dir, func(string, fs.FileInfo) error {
+			report.Tracked++
+			return nil
+		}, func(_, pattern string) {
+			report.Ignored++
+			report.ByPattern[pattern]++
+		})
+		report.Inaccessible = append(report.Inaccessible, denied...)
 		if err != nil {
-			return err
+			return report, err
 		}
-		seen[path] = struct{}{}
+	}
+	return report, nil
+}
 
+func (m *HybridMonitor) scanDirectory(dir string) error {
+	m.rootsMu.Lock()
+	previous := m.scannedAt[dir]
+	m.scannedAt[dir] = m.clock.Now().UTC()
+	m.rootsMu.Unlock()
+
+	reference := m.cache.FilesUnder(dir)
+	seen := make(map[string]struct{}, len(reference))
+
+	denied, err := m.walkSignatures(dir, m.progress, func(path string, sig state.FileSignature) {
 		cached, ok := reference[path]
+		if !ok && !m.admit(dir) {
+			return
+		}
+		seen[path] = struct{}{}
 		m.cache.Set(path, sig)
+		if m.outsideAgeWindow(path, sig.ModTime) {
+			return
+		}
 		if !ok {
 			// New file
-			m.recordChangeWithSize(path, events.EventCreate, time.Now().UTC(), sig.Size, 0, sig.Size)
-			return nil
+			m.recordChangeWithSize(reporting.SourceScan, path, events.EventCreate, m.scanTimestamp(sig.ModTime, previous), sig.Size, 0, sig.Size, m.isBinary(path, sig))
+			return
 		}
 		if !cached.Equal(sig) {
 			// Modified file - calculate size delta
 			sizeDelta := sig.Size - cached.Size
-			m.recordChangeWithSize(path, events.EventModify, time.Now().UTC(), sig.Size, cached.Size, sizeDelta)
+			m.recordChangeWithSize(reporting.SourceScan, path, events.EventModify, m.scanTimestamp(sig.ModTime, previous), sig.Size, cached.Size, sizeDelta, m.isBinary(path, sig))
+		} else {
+			for _, changeType := range m.attributeChanges(cached, sig) {
+				m.recordChangeWithSize(reporting.SourceScan, path, changeType, m.scanTimestamp(sig.ModTime, previous), sig.Size, cached.Size, 0, m.isBinary(path, sig))
+			}
 		}
-		return nil
 	})
 	if err != nil {
 		return err
 	}
+	m.setInaccessible(dir, denied)
 
 	for path, cachedSig := range reference {
 		if _, ok := seen[path]; ok {
 			continue
 		}
+		if withinAny(path, denied) {
+			// Unreadable, not deleted: keep the last known signature.
+			continue
+		}
 		m.cache.Delete(path)
+		if m.outsideAgeWindow(path, cachedSig.ModTime) {
+			continue
+		}
 		// For deleted files, we know the old size from cache
-		m.recordChangeWithSize(path, events.EventDelete, time.Now().UTC(), 0, cachedSig.Size, 0)
+		m.recordChangeWithSize(reporting.SourceScan, path, events.EventDelete, m.clock.Now().UTC(), 0, cachedSig.Size, 0, false)
 	}
 
 	return nil
 }
 
-func (m *HybridMonitor) recordChange(path, changeType string, timestamp time.Time) {
-	change := reporting.Change{Path: path, Type: changeType, Timestamp: timestamp}
-	if m.aggregator != nil {
-		m.aggregator.Record(change)
+// outsideAgeWindow reports whether a change to path, last modified at modTime,
+// is dropped by IgnoreOlderThan or IgnoreNewerThan. The age is measured
+// against the current time, so a file can age out of the window between two
+// scans. An unknown (zero) modification time is never dropped.
+func (m *HybridMonitor) outsideAgeWindow(path string, modTime time.Time) bool {
+	if (m.ignoreOlderThan <= 0 && m.ignoreNewerThan <= 0) || modTime.IsZero() {
+		return false
+	}
+	age := m.clock.Now().Sub(modTime)
+	switch {
+	case m.ignoreOlderThan > 0 && age > m.ignoreOlderThan:
+		m.debugf("skip %s: modified %s ago, older than %s", path, age.Round(time.Second), m.ignoreOlderThan)
+		return true
+	case m.ignoreNewerThan > 0 && age < m.ignoreNewerThan:
+		m.debugf("skip %s: modified %s ago, newer than %s", path, age.Round(time.Second), m.ignoreNewerThan)
+		return true
+	}
+	return false
+}
+
+// scanTimestamp returns the time to report for a change found by a safety
+// scan: the file's modification time when it falls between the previous scan
+// of the directory and now, which is when the missed change most likely
+// happened, and now otherwise, including on the first scan. Without the
+// fallback a file moved in with an old modification time, or one with a
+// clock-skewed future one, would be reported far from when it actually
+// appeared.
+func (m *HybridMonitor) scanTimestamp(modTime, previous time.Time) time.Time {
+	now := m.clock.Now().UTC()
+	if previous.IsZero() || modTime.After(now) || !modTime.After(previous) {
+		return now
+	}
+	return modTime.UTC()
+}
+
+// setInaccessible records the unreadable paths found by the latest scan of
+// root, logging each one the first time it is seen so a persistent permission
+// problem does not flood the log on every safety scan.
+func (m *HybridMonitor) setInaccessible(root string, denied []string) {
+	current := make(map[string]struct{}, len(denied))
+	for _, path := range denied {
+		current[path] = struct{}{}
+	}
+
+	m.inaccessibleMu.Lock()
+	if m.inaccessible == nil {
+		m.inaccessible = make(map[string]map[string]struct{})
 	}
+	previous := m.inaccessible[root]
+	m.inaccessible[root] = current
+	m.inaccessibleMu.Unlock()
+
+	if m.logger == nil {
+		return
+	}
+	for _, path := range denied {
+		if _, ok := previous[path]; !ok {
+			m.logger.Errorf("permission denied, skipping %s", path)
+		}
+	}
+}
+
+// checkRoot reports whether the watched directory root exists. The first time
+// it is found missing, its cached files are forgotten without reporting each
+// deletion, an error is logged, and a single ChangeRootLost change is
+// reported. When it reappears, the cache is re-seeded from its current
+// contents without reporting them and a ChangeRootRestored change is reported,
+// so a flapping mount shows up as a pair of changes rather than a flood.
+func (m *HybridMonitor) checkRoot(root string) bool {
+	info, err := os.Stat(root)
+	missing := errors.Is(err, fs.ErrNotExist) || (err == nil && !info.IsDir())
+
+	m.rootsMu.Lock()
+	_, wasLost := m.lostRoots[root]
+	if missing {
+		m.lostRoots[root] = struct{}{}
+	} else {
+		delete(m.lostRoots, root)
+	}
+	m.rootsMu.Unlock()
+
+	if missing == wasLost {
+		return !missing
+	}
+	if missing {
+		for path := range m.cache.FilesUnder(root) {
+			m.cache.Delete(path)
+		}
+		m.setInaccessible(root, nil)
+		if m.logger != nil {
+			m.logger.Errorf("watched directory %s no longer exists; changes beneath it are not tracked until it reappears", root)
+		}
+		m.recordChange(root, ChangeRootLost, m.clock.Now().UTC())
+		return false
+	}
+
+	m.reseed(root)
 	if m.logger != nil {
-		m.logger.Infof("%s %s", changeType, path)
+		m.logger.Infof("watched directory %s is back; tracking resumed", root)
 	}
-	if m.changeHandler != nil {
-		m.changeHandler(change)
+	m.recordChange(root, ChangeRootRestored, m.clock.Now().UTC())
+	return true
+}
+
+// checkRoots runs checkRoot for every watched directory and reports whether
+// they all exist.
+func (m *HybridMonitor) checkRoots() bool {
+	present := true
+	for _, dir := range m.directories {
+		if !m.checkRoot(dir) {
+			present = false
+		}
+	}
+	return present
+}
+
+// rootLost reports whether root was missing when last checked.
+func (m *HybridMonitor) rootLost(root string) bool {
+	m.rootsMu.Lock()
+	defer m.rootsMu.Unlock()
+	_, lost := m.lostRoots[root]
+	return lost
+}
+
+// reseed records the current signature of every file beneath root in the
+// cache without reporting changes.
+func (m *HybridMonitor) reseed(root string) {
+	denied, err := m.walkSignatures(root, nil, func(path string, sig state.FileSignature) {
+		if _, ok := m.cache.Get(path); !ok && !m.admit(root) {
+			return
+		}
+		m.cache.Set(path, sig)
+	})
+	if err != nil && !m.canceled() && m.logger != nil {
+		m.logger.Errorf("re-seed %s: %v", root, err)
+	}
+	if m.canceled() {
+		return
+	}
+	m.setInaccessible(root, denied)
+}
+
+// canceled reports whether the context the monitor runs under is done, which
+// aborts walks in progress so shutdown is not held up by a large tree.
+func (m *HybridMonitor) canceled() bool {
+	return m.ctx != nil && m.ctx.Err() != nil
+}
+
+// admit reports whether a file not yet in the cache may be tracked. Once the
+// cache holds maxTracked entries new files are refused; the first refusal
+// logs an error and reports a ChangeLimit change naming root so the user can
+// narrow the watch scope.
+func (m *HybridMonitor) admit(root string) bool {
+	if m.maxTracked <= 0 || m.cache.Len() < m.maxTracked {
+		return true
+	}
+	m.limitMu.Lock()
+	first := m.limitRoot == ""
+	if first {
+		m.limitRoot = root
+	}
+	m.limitHit = true
+	m.limitMu.Unlock()
+
+	if first {
+		if m.logger != nil {
+			m.logger.Errorf("tracking limit of %d files reached while scanning %s; new files are not tracked until the watch scope is narrowed", m.maxTracked, root)
+		}
+		m.recordChange(root, ChangeLimit, m.clock.Now().UTC())
+	}
+	return false
+}
+
+// clearLimitIfUnderCap lifts the limit condition after a full scan pass that
+// did not refuse any file, e.g. because files were deleted or newly ignored.
+// Deletions are applied after each directory walk, so the pass that frees
+// room may still refuse files and the condition clears on the next one.
+func (m *HybridMonitor) clearLimitIfUnderCap() {
+	m.limitMu.Lock()
+	cleared := m.limitRoot != "" && !m.limitHit
+	if cleared {
+		m.limitRoot = ""
+	}
+	m.limitMu.Unlock()
+	if cleared && m.logger != nil {
+		m.logger.Infof("tracked files back under the limit of %d", m.maxTracked)
+	}
+}
+
+// TrackingLimit reports the watched directory being scanned when the tracked
+// file cap was reached, and whether the cap is currently in effect.
+func (m *HybridMonitor) TrackingLimit() (root string, reached bool) {
+	m.limitMu.Lock()
+	defer m.limitMu.Unlock()
+	return m.limitRoot, m.limitRoot != ""
+}
+
+// InaccessiblePaths returns the sorted paths that the most recent scans could
+// not read because of missing permissions.
+func (m *HybridMonitor) InaccessiblePaths() []string {
+	m.inaccessibleMu.Lock()
+	defer m.inaccessibleMu.Unlock()
+	var paths []string
+	for _, set := range m.inaccessible {
+		for path := range set {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// withinAny reports whether path equals or lies beneath any of roots.
+func withinAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if state.PathWithin(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinary reports whether the file behind sig should be flagged as binary.
+// It returns false unless detection is enabled. Hashed small files use the
+// flag computed alongside their hash; larger files have their head sampled.
+func (m *HybridMonitor) isBinary(path string, sig state.FileSignature) bool {
+	if !m.detectBinary || sig.Size == 0 {
+		return false
+	}
+	if sig.Hash != "" {
+		return sig.Binary
 	}
+	binary, err := state.SniffBinary(path)
+	if err != nil {
+		return false
+	}
+	return binary
 }
 
-func (m *HybridMonitor) recordChangeWithSize(path, changeType string, timestamp time.Time, size, oldSize, sizeDelta int64) {
+func (m *HybridMonitor) recordChange(path, changeType string, timestamp time.Time) {
+	m.emit(reporting.Change{Path: path, Type: changeType, Timestamp: timestamp})
+}
+
+func (m *HybridMonitor) recordChangeWithSize(source, path, changeType string, timestamp time.Time, size, oldSize, sizeDelta int64, isBinary bool) {
 	change := reporting.Change{
+		Source:    source,
 		Path:      path,
 		Type:      changeType,
 		Timestamp: timestamp,
 		Size:      size,
 		OldSize:   oldSize,
 		SizeDelta: sizeDelta,
+		IsBinary:  isBinary,
+	}
+	m.emit(change)
+}
+
+// emit passes change through the optional filter and then records it with the
+// aggregator, logger, and change handler.
+func (m *HybridMonitor) emit(change reporting.Change) {
+	if m.warming.Load() && !IsMarker(change.Type) {
+		m.debugf("skip %s: warming up", change.Path)
+		return
+	}
+	if !IsMarker(change.Type) {
+		m.checkChurn(change.Path)
 	}
-	if m.aggregator != nil {
+	switch change.Source {
+	case reporting.SourceRealtime:
+		m.realtimeSeen.Add(1)
+	case reporting.SourceScan:
+		m.scanFound.Add(1)
+	}
+	if m.filter != nil {
+		var keep bool
+		if change, keep = m.filter(change); !keep {
+			m.debugf("skip %s: dropped by filter", change.Path)
+			return
+		}
+	}
+	switch {
+	case m.aggregator == nil:
+	case IsMarker(change.Type):
+		m.aggregator.RecordMarker(change)
+	default:
 		m.aggregator.Record(change)
 	}
+	m.recordSinks(change)
 	if m.logger != nil {
-		m.logger.Infof("%s %s", changeType, path)
+		m.logger.Infof("%s %s", change.Type, change.Path)
 	}
 	if m.changeHandler != nil {
 		m.changeHandler(change)
 	}
 }
 
+// recordSinks passes change to every sink and reports their errors.
+func (m *HybridMonitor) recordSinks(change reporting.Change) {
+	for _, sink := range m.sinks {
+		err := sink.Record(change)
+		if err == nil {
+			continue
+		}
+		if m.logger != nil {
+			m.logger.Errorf("record %s: %v", change.Path, err)
+		}
+		if m.onSinkError != nil {
+			m.onSinkError(err)
+		}
+	}
+}
+
+// checkChurn counts a change to path and warns when its directory starts
+// changing faster than the churn threshold.
+func (m *HybridMonitor) checkChurn(path string) {
+	if m.churn == nil {
+		return
+	}
+	report, warn := m.churn.observe(path, m.clock.Now())
+	if !warn {
+		return
+	}
+	report.Suggestion = suggestIgnorePattern(m.rootFor(report.Directory), report)
+	if m.logger != nil {
+		m.logger.Errorf("excessive churn: more than %d changes in one second in %s, most to %s; consider ignoring it with the pattern %q",
+			m.churn.threshold, report.Directory, report.TopPath, report.Suggestion)
+	}
+	if m.onChurn != nil {
+		m.onChurn(report)
+	}
+}
+
+// suggestIgnorePattern proposes an ignore pattern covering a churning
+// directory: the directory itself relative to root, or, for churn directly
+// in root, the extension or name of the busiest file.
+func suggestIgnorePattern(root string, report ChurnReport) string {
+	if rel, err := filepath.Rel(root, report.Directory); err == nil && rel != "." && root != "" {
+		return filepath.ToSlash(rel) + "/"
+	}
+	if ext := filepath.Ext(report.TopPath); ext != "" {
+		return "**/*" + ext
+	}
+	return filepath.Base(report.TopPath)
+}
+
+// Churning returns the directories whose change rate exceeded the churn
+// threshold within the last minute, busiest first.
+func (m *HybridMonitor) Churning() []ChurnReport {
+	if m.churn == nil {
+		return nil
+	}
+	return m.churn.churning(m.clock.Now())
+}
+
+// debugf logs a diagnostic message when the logger has debug output enabled.
+func (m *HybridMonitor) debugf(format string, args ...interface{}) {
+	if m.logger.DebugEnabled() {
+		m.logger.Debugf(format, args...)
+	}
+}
+
 func (m *HybridMonitor) shouldIgnore(path string) bool {
-	if len(m.ignorePatterns) == 0 {
-		return false
+	_, ok := m.matchIgnore(path)
+	return ok
+}
+
+// matchIgnore reports the first ignore pattern that matches path, if any.
+func (m *HybridMonitor) matchIgnore(path string) (string, bool) {
+	set := m.ignore.Load()
+	if set == nil || len(set.patterns) == 0 {
+		return "", false
 	}
 
 	tokens := filters.ExtractPathTokens(path)
 	bloomMatch := false
-	if m.ignoreBloom == nil {
+	if set.bloom == nil {
 		bloomMatch = true
 	} else {
 		for _, token := range tokens {
-			if m.ignoreBloom.Contains(token) {
+			if set.bloom.Contains(token) {
 				bloomMatch = true
 				break
 			}
@@ -335,41 +1544,147 @@ func (m *HybridMonitor) shouldIgnore(path string) bool {
 	}
 
 	if !bloomMatch {
-		return false
+		return "", false
+	}
+
+	relative, normalized := m.matchPaths(path)
+	for _, pattern := range set.patterns {
+		if matchPattern(pattern, relative, normalized) {
+			return pattern, true
+		}
 	}
 
-	normalized := filepath.ToSlash(path)
-	base := filepath.Base(normalized)
+	return "", false
+}
 
-	for _, pattern := range m.ignorePatterns {
-		if matchPattern(pattern, normalized, base) {
+// shouldWatch reports whether path passes the include gate: it is true when
+// no include patterns are configured or path matches at least one of them.
+func (m *HybridMonitor) shouldWatch(path string) bool {
+	if len(m.includePatterns) == 0 {
+		return true
+	}
+	relative, normalized := m.matchPaths(path)
+	for _, pattern := range m.includePatterns {
+		if matchPattern(pattern, relative, normalized) {
 			return true
 		}
 	}
-
 	return false
 }
 
-func matchPattern(pattern, fullPath, base string) bool {
-	pattern = strings.TrimSpace(pattern)
+// matchPaths returns the slash-separated forms of path that patterns are
+// matched against: relative to its watch root, and absolute.
+func (m *HybridMonitor) matchPaths(path string) (relative, normalized string) {
+	normalized = filepath.ToSlash(path)
+	relative = normalized
+	if root := m.rootFor(path); root != "" {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." {
+			relative = filepath.ToSlash(rel)
+		}
+	}
+	return relative, normalized
+}
+
+// rootFor returns the watched directory that contains path, preferring the
+// deepest one when roots are nested. It returns an empty string when path is
+// outside every watched directory.
+func (m *HybridMonitor) rootFor(path string) string {
+	best := ""
+	for _, dir := range m.directories {
+		if !state.PathWithin(path, dir) {
+			continue
+		}
+		if len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best
+}
+
+// matchPattern reports whether an ignore pattern matches a path. relPath is
+// the slash-separated path relative to its watch root and fullPath is the
+// absolute slash-separated path. Matching follows gitignore conventions:
+//
+//   - a pattern without a slash (e.g. `*.log`, `node_modules`) matches any
+//     single path segment, so it excludes matching files and everything
+//     beneath matching directories;
+//   - a pattern containing a slash (e.g. `src/generated/**`) is anchored to
+//     the watch root, and `**` matches zero or more directories;
+//   - a trailing slash restricts the pattern to directories;
+//   - absolute patterns are also compared against the absolute path.
+func matchPattern(pattern, relPath, fullPath string) bool {
+	pattern = filters.TrimPattern(pattern)
 	if pattern == "" {
 		return false
 	}
 
 	normPattern := filepath.ToSlash(pattern)
+	dirOnly := strings.HasSuffix(normPattern, "/") && normPattern != "/"
+	normPattern = strings.TrimSuffix(normPattern, "/")
+	if normPattern == "" {
+		return false
+	}
 
-	if strings.Contains(normPattern, "**") {
-		prefix := strings.TrimSuffix(normPattern, "**")
-		if prefix == "" || strings.HasPrefix(fullPath, prefix) {
-			return true
+	if strings.HasPrefix(normPattern, "/") && matchAnchored(normPattern, fullPath, dirOnly) {
+		return true
+	}
+
+	anchored := strings.TrimPrefix(normPattern, "/")
+	if !strings.Contains(anchored, "/") {
+		segments := strings.Split(relPath, "/")
+		limit := len(segments)
+		if dirOnly {
+			limit--
+		}
+		for _, segment := range segments[:max(limit, 0)] {
+			if ok, _ := pathpkg.Match(anchored, segment); ok {
+				return true
+			}
 		}
+		return false
 	}
 
-	if ok, _ := pathpkg.Match(normPattern, fullPath); ok {
-		return true
+	return matchAnchored(anchored, relPath, dirOnly)
+}
+
+// matchAnchored matches a slash-separated glob against target or any of its
+// parent directories, so a pattern naming a directory also covers its
+// contents. When dirOnly is set the full target (a file) is not considered.
+func matchAnchored(pattern, target string, dirOnly bool) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	targetSegments := strings.Split(strings.Trim(target, "/"), "/")
+	limit := len(targetSegments)
+	if dirOnly {
+		limit--
 	}
-	if ok, _ := filepath.Match(pattern, base); ok {
-		return true
+	for i := 1; i <= limit; i++ {
+		if matchSegments(patternSegments, targetSegments[:i]) {
+			return true
+		}
 	}
 	return false
 }
+
+// matchSegments matches path segments against glob segments, treating a `**`
+// segment as zero or more directories.
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(rest, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := pathpkg.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,1257 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lowkey/internal/clock"
+	"lowkey/internal/events"
+	"lowkey/internal/logging"
+	"lowkey/internal/reporting"
+	"lowkey/internal/state"
+	"lowkey/pkg/config"
+)
+
+func TestControllerDryRunCountsIgnoredByPattern(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "debug.log", "trace.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	controller, err := NewController(ControllerConfig{
+		Directories: []string{dir},
+		IgnoreGlobs: []string{"*.log"},
+	})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+
+	report, err := controller.DryRun()
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if report.Tracked != 1 {
+		t.Fatalf("expected 1 tracked file, got %d", report.Tracked)
+	}
+	if report.Ignored != 2 || report.ByPattern["*.log"] != 2 {
+		t.Fatalf("expected 2 files ignored by *.log, got %+v", report)
+	}
+}
+
+func TestShouldIgnoreMatchesRelativeDirectoryPatterns(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "mnt", "work", "project")
+	monitor := &HybridMonitor{directories: []string{root}}
+	monitor.ignore.Store(compileIgnorePatterns([]string{"src/generated/**", "node_modules", "build/", "docs/*.tmp", `\#draft.md`, `trailing\ `}))
+
+	cases := []struct {
+		rel    string
+		ignore bool
+	}{
+		{"src/generated/api.go", true},
+		{"src/generated/nested/deep/types.go", true},
+		{"src/main.go", false},
+		{"lib/src/generated/api.go", false},
+		{"node_modules/pkg/index.js", true},
+		{"web/node_modules/pkg/index.js", true},
+		{"build/out.bin", true},
+		{"build", false},
+		{"docs/notes.tmp", true},
+		{"docs/nested/notes.tmp", false},
+		{"#draft.md", true},
+		{"trailing ", true},
+		{"trailing", false},
+	}
+
+	for _, tc := range cases {
+		path := filepath.Join(root, filepath.FromSlash(tc.rel))
+		if got := monitor.shouldIgnore(path); got != tc.ignore {
+			t.Fatalf("shouldIgnore(%q) = %v, want %v", tc.rel, got, tc.ignore)
+		}
+	}
+}
+
+func TestPausedMonitorDropsEventsAndRescansOnResume(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+
+	var changes []reporting.Change
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:     backend,
+		Directories: []string{dir},
+		OnChange:    func(change reporting.Change) { changes = append(changes, change) },
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	monitor.SetPaused(true)
+	monitor.handleEvent(events.Event{Path: path, Type: events.EventCreate, Timestamp: time.Now()})
+	if len(changes) != 0 || monitor.cache.Len() != 0 {
+		t.Fatalf("expected paused monitor to drop events, got %d changes", len(changes))
+	}
+
+	monitor.SetPaused(false)
+	select {
+	case <-monitor.rescan:
+	default:
+		t.Fatalf("expected resume to queue a safety scan")
+	}
+	monitor.performSafetyScan()
+	if len(changes) != 1 || changes[0].Type != events.EventCreate {
+		t.Fatalf("expected rescan to report the file created while paused, got %+v", changes)
+	}
+}
+
+func TestFilterDropsAndRewritesChanges(t *testing.T) {
+	var recorded []reporting.Change
+	monitor := &HybridMonitor{
+		changeHandler: func(change reporting.Change) { recorded = append(recorded, change) },
+		filter: func(change reporting.Change) (reporting.Change, bool) {
+			if filepath.Ext(change.Path) != ".go" {
+				return change, false
+			}
+			change.Path = filepath.Base(change.Path)
+			return change, true
+		},
+	}
+
+	monitor.recordChange("/src/readme.md", events.EventModify, time.Now())
+	monitor.recordChangeWithSize(reporting.SourceRealtime, "/src/main.go", events.EventCreate, time.Now(), 10, 0, 10, false)
+
+	if len(recorded) != 1 {
+		t.Fatalf("expected only the .go change to be recorded, got %+v", recorded)
+	}
+	if recorded[0].Path != "main.go" || recorded[0].Size != 10 {
+		t.Fatalf("expected rewritten change, got %+v", recorded[0])
+	}
+}
+
+func TestScanSkipsUnreadableDirectoryWithoutReportingDeletes(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission checks do not apply to root")
+	}
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "locked")
+	if err := os.Mkdir(locked, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "a.txt"), filepath.Join(locked, "b.txt")} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var changes []reporting.Change
+	monitor := &HybridMonitor{
+		cache:         state.NewCache(),
+		directories:   []string{dir},
+		clock:         clock.OrReal(nil),
+		changeHandler: func(change reporting.Change) { changes = append(changes, change) },
+	}
+	if err := monitor.scanDirectory(dir); err != nil {
+		t.Fatalf("initial scan: %v", err)
+	}
+
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	defer os.Chmod(locked, 0o755)
+
+	changes = nil
+	if err := monitor.scanDirectory(dir); err != nil {
+		t.Fatalf("expected scan to continue past unreadable directory, got %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes for unreadable directory, got %+v", changes)
+	}
+	if got := monitor.InaccessiblePaths(); len(got) != 1 || got[0] != locked {
+		t.Fatalf("expected %s to be reported inaccessible, got %v", locked, got)
+	}
+	if monitor.cache.Len() != 2 {
+		t.Fatalf("expected cached entries under the unreadable directory to be kept, got %d", monitor.cache.Len())
+	}
+}
+
+// baselineMatchPattern is the matcher ignore patterns used before they were
+// matched against root-relative paths: the glob was compared with the
+// absolute path and the base name, and a `**` suffix matched by prefix.
+func baselineMatchPattern(pattern, fullPath string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return false
+	}
+	if strings.Contains(pattern, "**") {
+		prefix := strings.TrimSuffix(pattern, "**")
+		if prefix == "" || strings.HasPrefix(fullPath, prefix) {
+			return true
+		}
+	}
+	if ok, _ := pathpkg.Match(pattern, fullPath); ok {
+		return true
+	}
+	ok, _ := pathpkg.Match(pattern, pathpkg.Base(fullPath))
+	return ok
+}
+
+func TestIgnorePatternsStayCompatibleWithBaselineMatching(t *testing.T) {
+	root := "/mnt/work/project"
+	cases := []struct {
+		pattern string
+		rel     string
+		ignore  bool
+		// changed marks the cases the root-relative matcher decides
+		// differently from the baseline one; see the changelog.
+		changed bool
+	}{
+		// Base-name globs keep matching files at any depth.
+		{pattern: "*.log", rel: "debug.log", ignore: true},
+		{pattern: "*.log", rel: "server/logs/debug.log", ignore: true},
+		{pattern: "*.log", rel: "server/main.go", ignore: false},
+		{pattern: "node_modules", rel: "node_modules", ignore: true},
+		{pattern: ".DS_Store", rel: "assets/.DS_Store", ignore: true},
+		// Absolute patterns keep matching the absolute path.
+		{pattern: "/mnt/work/project/*.bak", rel: "notes.bak", ignore: true},
+		{pattern: "/mnt/work/project/vendor/**", rel: "vendor/lib/a.go", ignore: true},
+		{pattern: "/mnt/work/project/vendor/**", rel: "src/a.go", ignore: false},
+		// A pattern without a slash now matches any path segment, so it
+		// covers everything beneath a matching directory, where the
+		// baseline only compared the base name.
+		{pattern: "node_modules", rel: "node_modules/pkg/index.js", ignore: true, changed: true},
+		{pattern: "build*", rel: "buildtools/main.go", ignore: true, changed: true},
+		{pattern: "*.log", rel: "archive.log/readme.txt", ignore: true, changed: true},
+		// A relative pattern with a slash is now anchored to the watch
+		// root; the baseline compared it with the absolute path, where it
+		// never matched.
+		{pattern: "src/generated/**", rel: "src/generated/api.go", ignore: true, changed: true},
+		{pattern: "docs/*.tmp", rel: "docs/notes.tmp", ignore: true, changed: true},
+	}
+
+	for _, tc := range cases {
+		full := root + "/" + tc.rel
+		if got := matchPattern(tc.pattern, tc.rel, full); got != tc.ignore {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tc.pattern, tc.rel, got, tc.ignore)
+		}
+		if baseline := baselineMatchPattern(tc.pattern, full); (baseline != tc.ignore) != tc.changed {
+			t.Errorf("pattern %q on %q: baseline matched %v, new matcher %v, but changed is %v", tc.pattern, tc.rel, baseline, tc.ignore, tc.changed)
+		}
+	}
+}
+
+func TestIncludeGlobsGateBeforeIgnorePatterns(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "gen.go", "go.mod", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	controller, err := NewController(ControllerConfig{
+		Directories:  []string{dir},
+		IncludeGlobs: []string{"*.go", "go.mod"},
+		IgnoreGlobs:  []string{"gen.go"},
+	})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	report, err := controller.DryRun()
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if report.Tracked != 2 {
+		t.Fatalf("expected main.go and go.mod to be tracked, got %d", report.Tracked)
+	}
+	if report.ByPattern[NotIncludedPattern] != 1 || report.ByPattern["gen.go"] != 1 {
+		t.Fatalf("unexpected ignore breakdown: %v", report.ByPattern)
+	}
+}
+
+func TestIgnoreFileChangeReloadsPatterns(t *testing.T) {
+	dir := t.TempDir()
+	ignoreFile := filepath.Join(dir, ".lowkey")
+	tracked := filepath.Join(dir, "scratch.tmp")
+	if err := os.WriteFile(tracked, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:      backend,
+		Directories:  []string{dir},
+		IgnoreFiles:  []string{ignoreFile},
+		ReloadIgnore: func() ([]string, error) { return config.LoadIgnorePatterns(ignoreFile) },
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	monitor.performSafetyScan()
+	if _, ok := monitor.cache.Get(tracked); !ok {
+		t.Fatalf("expected %s to be tracked before the ignore file exists", tracked)
+	}
+
+	if err := os.WriteFile(ignoreFile, []byte("*.tmp\n"), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+	monitor.handleEvent(events.Event{Path: ignoreFile, Type: events.EventCreate, Timestamp: time.Now()})
+
+	if !monitor.shouldIgnore(tracked) {
+		t.Fatalf("expected reloaded patterns to ignore %s", tracked)
+	}
+	if _, ok := monitor.cache.Get(tracked); ok {
+		t.Fatalf("expected newly ignored file to be dropped from the cache")
+	}
+}
+
+func TestSetIgnorePatternsIsSafeDuringMatching(t *testing.T) {
+	monitor := &HybridMonitor{
+		cache:       state.NewCache(),
+		directories: []string{"/src"},
+		rescan:      make(chan struct{}, 1),
+	}
+	monitor.ignore.Store(compileIgnorePatterns([]string{"*.log"}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			monitor.shouldIgnore("/src/app.log")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		monitor.SetIgnorePatterns([]string{"*.log", "*.tmp"})
+	}
+	<-done
+	if !monitor.shouldIgnore("/src/a.tmp") {
+		t.Fatalf("expected the latest pattern set to be active")
+	}
+}
+
+func TestMaxTrackedFilesStopsAddingAndReportsOnce(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+	var limits []reporting.Change
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:         backend,
+		Directories:     []string{dir},
+		MaxTrackedFiles: 2,
+		OnChange: func(change reporting.Change) {
+			if change.Type == ChangeLimit {
+				limits = append(limits, change)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+
+	monitor.performSafetyScan()
+	monitor.performSafetyScan()
+	if got := monitor.cache.Len(); got != 2 {
+		t.Fatalf("expected the cache to stop at 2 files, got %d", got)
+	}
+	if len(limits) != 1 || limits[0].Path != dir {
+		t.Fatalf("expected one limit change naming %s, got %v", dir, limits)
+	}
+	if root, reached := monitor.TrackingLimit(); !reached || root != dir {
+		t.Fatalf("expected limit reached in %s, got %q %t", dir, root, reached)
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("remove %s: %v", name, err)
+		}
+	}
+	// Deletions are applied after the walk, so the freed slots are used on
+	// the following scan.
+	monitor.performSafetyScan()
+	monitor.performSafetyScan()
+	if _, reached := monitor.TrackingLimit(); reached {
+		t.Fatalf("expected the limit to clear once files fit under the cap")
+	}
+	if _, ok := monitor.cache.Get(filepath.Join(dir, "d")); !ok {
+		t.Fatalf("expected d to be tracked once there is room")
+	}
+}
+
+func TestDebugLoggingExplainsSkippedEvents(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(kept, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var buf bytes.Buffer
+	logger := logging.NewWriter(&buf)
+	logger.SetDebug(true)
+	monitor := &HybridMonitor{
+		cache:       state.NewCache(),
+		directories: []string{dir},
+		logger:      logger,
+		clock:       clock.Real{},
+	}
+	monitor.ignore.Store(compileIgnorePatterns([]string{"*.log"}))
+
+	monitor.handleEvent(events.Event{Path: filepath.Join(dir, "app.log"), Type: events.EventModify, Timestamp: time.Now()})
+	monitor.handleEvent(events.Event{Path: kept, Type: events.EventCreate, Timestamp: time.Now()})
+	monitor.handleEvent(events.Event{Path: kept, Type: events.EventModify, Timestamp: time.Now()})
+
+	out := buf.String()
+	for _, want := range []string{
+		"DEBUG backend event MODIFY " + filepath.Join(dir, "app.log"),
+		`ignored by pattern "*.log"`,
+		"INFO CREATE " + kept,
+		"skip " + kept + ": signature unchanged",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in debug output:\n%s", want, out)
+		}
+	}
+}
+
+func TestSkipHiddenIgnoresNestedDotEntries(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"a/.cache/b", "a/c"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	hiddenFile := filepath.Join(dir, "a", "c", ".swp")
+	for _, path := range []string{filepath.Join(dir, "a", ".cache", "b", "x"), hiddenFile, filepath.Join(dir, "a", "c", "kept")} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	controller, err := NewController(ControllerConfig{Directories: []string{dir}, SkipHidden: true})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	report, err := controller.DryRun()
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if report.Tracked != 1 {
+		t.Fatalf("expected only a/c/kept to be tracked, got %d", report.Tracked)
+	}
+
+	monitor := &HybridMonitor{cache: state.NewCache(), directories: []string{dir}, skipHidden: true, clock: clock.Real{}}
+	monitor.handleEvent(events.Event{Path: hiddenFile, Type: events.EventModify, Timestamp: time.Now()})
+	if monitor.cache.Len() != 0 {
+		t.Fatalf("expected events for hidden files to be dropped")
+	}
+}
+
+func TestLostRootIsReportedOnceAndReseededOnReturn(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "root")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+	var changes []reporting.Change
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:     backend,
+		Directories: []string{dir},
+		OnChange: func(change reporting.Change) {
+			changes = append(changes, change)
+		},
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	monitor.performSafetyScan()
+	changes = nil
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("remove root: %v", err)
+	}
+	monitor.performSafetyScan()
+	monitor.performSafetyScan()
+	if len(changes) != 1 || changes[0].Type != ChangeRootLost || changes[0].Path != dir {
+		t.Fatalf("expected a single ROOT_LOST change, got %v", changes)
+	}
+	if monitor.cache.Len() != 0 {
+		t.Fatalf("expected the lost root's files to be forgotten")
+	}
+
+	changes = nil
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("recreate root: %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("y"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	monitor.performSafetyScan()
+	if len(changes) != 1 || changes[0].Type != ChangeRootRestored {
+		t.Fatalf("expected only a ROOT_RESTORED change, got %v", changes)
+	}
+	if monitor.cache.Len() != 2 {
+		t.Fatalf("expected the cache to be re-seeded with 2 files, got %d", monitor.cache.Len())
+	}
+}
+
+func TestWarmUpBuildsBaselineSilentlyAndReconcilesAfterwards(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing")
+	if err := os.WriteFile(existing, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+	changes := make(chan reporting.Change, 16)
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:      backend,
+		Directories:  []string{dir},
+		PollInterval: time.Hour,
+		WarmUp:       200 * time.Millisecond,
+		OnChange: func(change reporting.Change) {
+			changes <- change
+		},
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = monitor.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for monitor.cache.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if monitor.cache.Len() != 1 {
+		t.Fatalf("expected the baseline scan to track the existing file")
+	}
+
+	// A change during the warm-up is neither reported immediately nor lost.
+	created := filepath.Join(dir, "created")
+	if err := os.WriteFile(created, []byte("y"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	monitor.handleEvent(events.Event{Path: created, Type: events.EventCreate, Timestamp: time.Now()})
+	select {
+	case change := <-changes:
+		t.Fatalf("expected no changes during warm-up, got %v", change)
+	default:
+	}
+
+	select {
+	case change := <-changes:
+		if change.Path != created || change.Type != events.EventCreate {
+			t.Fatalf("expected the file created during warm-up, got %v", change)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the change made during warm-up to be reported after it")
+	}
+	select {
+	case change := <-changes:
+		t.Fatalf("expected no further changes, got %v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestScanDiscoveredChangesUseModTimeAndSource(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	var changes []reporting.Change
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:     backend,
+		Directories: []string{dir},
+		Clock:       fake,
+		OnChange:    func(change reporting.Change) { changes = append(changes, change) },
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	monitor.performSafetyScan()
+
+	missed := filepath.Join(dir, "missed.txt")
+	movedIn := filepath.Join(dir, "moved-in.txt")
+	for path, mtime := range map[string]time.Time{missed: start.Add(10 * time.Second), movedIn: start.Add(-time.Hour)} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	fake.Advance(time.Minute)
+	monitor.performSafetyScan()
+
+	got := make(map[string]reporting.Change)
+	for _, change := range changes {
+		got[change.Path] = change
+	}
+	if c := got[missed]; c.Source != reporting.SourceScan || !c.Timestamp.Equal(start.Add(10*time.Second)) {
+		t.Fatalf("expected missed change at its mtime from the scan, got %+v", c)
+	}
+	if c := got[movedIn]; c.Source != reporting.SourceScan || !c.Timestamp.Equal(fake.Now()) {
+		t.Fatalf("expected file with an older mtime to use the scan time, got %+v", c)
+	}
+
+	changes = nil
+	monitor.handleEvent(events.Event{Path: missed, Type: events.EventDelete, Timestamp: fake.Now()})
+	if len(changes) != 1 || changes[0].Source != reporting.SourceRealtime {
+		t.Fatalf("expected a realtime delete, got %+v", changes)
+	}
+}
+
+func TestAdaptivePollIntervalFollowsDiscrepancies(t *testing.T) {
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:         backend,
+		Directories:     []string{t.TempDir()},
+		PollInterval:    30 * time.Second,
+		MinPollInterval: 10 * time.Second,
+		MaxPollInterval: 2 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+
+	report := func(source string, n int) {
+		for i := 0; i < n; i++ {
+			monitor.recordChangeWithSize(source, "/w/a.txt", events.EventModify, time.Now(), 1, 0, 1, false)
+		}
+	}
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 2 * time.Minute} {
+		if got := monitor.adaptPollInterval(); got != want {
+			t.Fatalf("expected quiet scans to lengthen the interval to %s, got %s", want, got)
+		}
+	}
+
+	report(reporting.SourceRealtime, 5)
+	report(reporting.SourceScan, 5)
+	if got := monitor.adaptPollInterval(); got != time.Minute {
+		t.Fatalf("expected missed changes to shorten the interval, got %s", got)
+	}
+	if ratio := monitor.DiscrepancyRatio(); ratio != 0.25 {
+		t.Fatalf("expected smoothed discrepancy 0.25, got %v", ratio)
+	}
+	for _, want := range []time.Duration{30 * time.Second, 15 * time.Second, 10 * time.Second} {
+		report(reporting.SourceScan, 10)
+		if got := monitor.adaptPollInterval(); got != want {
+			t.Fatalf("expected repeated misses to shorten the interval to %s, got %s", want, got)
+		}
+	}
+
+	report(reporting.SourceRealtime, 10)
+	if got := monitor.adaptPollInterval(); got != 20*time.Second || monitor.PollInterval() != got {
+		t.Fatalf("expected a clean scan to lengthen the interval again, got %s", got)
+	}
+}
+
+func TestControllerStartsFromCarriedOverCache(t *testing.T) {
+	kept, added := t.TempDir(), t.TempDir()
+	write := func(path string) {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	known := filepath.Join(kept, "known.txt")
+	write(known)
+	info, err := os.Stat(known)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	sig, err := state.ComputeSignature(known, info)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	cache := state.NewCache()
+	cache.Set(known, sig)
+	cache.Set("/removed/dir/file.txt", sig)
+
+	// Created while the controllers were swapped, so it must be reported;
+	// the newly added directory is baselined silently instead.
+	missed := filepath.Join(kept, "missed.txt")
+	write(missed)
+	write(filepath.Join(added, "existing.txt"))
+
+	changes := make(chan reporting.Change, 16)
+	controller, err := NewController(ControllerConfig{
+		Directories:     []string{kept, added},
+		PollInterval:    20 * time.Millisecond,
+		MinPollInterval: -1,
+		Cache:           cache,
+		OnChange:        func(change reporting.Change) { changes <- change },
+	})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	if err := controller.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	controller.Stop()
+	close(changes)
+
+	var got []string
+	for change := range changes {
+		got = append(got, change.Type+" "+change.Path)
+	}
+	if want := []string{events.EventCreate + " " + missed}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected only %v, got %v", want, got)
+	}
+	if _, ok := cache.Get("/removed/dir/file.txt"); ok {
+		t.Fatalf("expected entries outside the watched directories to be pruned")
+	}
+}
+
+func TestAgeWindowDropsChangesOutsideIt(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+
+	now := time.Now()
+	fake := clock.NewFake(now)
+	var changes []string
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:         backend,
+		Directories:     []string{dir},
+		Clock:           fake,
+		IgnoreOlderThan: time.Hour,
+		IgnoreNewerThan: time.Minute,
+		OnChange:        func(change reporting.Change) { changes = append(changes, change.Type+" "+filepath.Base(change.Path)) },
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+
+	for name, age := range map[string]time.Duration{"backlog.txt": 2 * time.Hour, "recent.txt": 10 * time.Minute, "settling.txt": 10 * time.Second} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	monitor.performSafetyScan()
+	if strings.Join(changes, ",") != "CREATE recent.txt" {
+		t.Fatalf("expected only the file inside the window to be reported, got %v", changes)
+	}
+	if monitor.cache.Len() != 3 {
+		t.Fatalf("expected files outside the window to stay tracked, got %d", monitor.cache.Len())
+	}
+
+	// The old file's deletion is judged by its cached modification time.
+	changes = nil
+	backlog := filepath.Join(dir, "backlog.txt")
+	if err := os.Remove(backlog); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	monitor.handleEvent(events.Event{Path: backlog, Type: events.EventDelete, Timestamp: now})
+	if len(changes) != 0 {
+		t.Fatalf("expected deletion of an old file to be dropped, got %v", changes)
+	}
+
+	// Once the clock moves on, the recent file ages out of the window.
+	fake.Advance(2 * time.Hour)
+	recent := filepath.Join(dir, "recent.txt")
+	if err := os.WriteFile(recent, []byte("xy"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(recent, now, now); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	monitor.performSafetyScan()
+	if len(changes) != 0 {
+		t.Fatalf("expected changes to files that aged out to be dropped, got %v", changes)
+	}
+}
+
+func TestControllerReportsMonitorThatStoppedOnItsOwn(t *testing.T) {
+	dir := t.TempDir()
+	controller, err := NewController(ControllerConfig{
+		Directories:     []string{dir},
+		PollInterval:    20 * time.Millisecond,
+		MinPollInterval: -1,
+		WarmUp:          -1,
+		Filter: func(change reporting.Change) (reporting.Change, bool) {
+			panic("filter exploded")
+		},
+	})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	if controller.Running() {
+		t.Fatalf("expected the controller not to be running before Start")
+	}
+	if err := controller.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer controller.Stop()
+	if !controller.Running() {
+		t.Fatalf("expected the controller to be running after Start")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "boom.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for controller.Running() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if controller.Running() {
+		t.Fatalf("expected the monitor to stop after the filter panicked")
+	}
+	if err := controller.Err(); err == nil || !strings.Contains(err.Error(), "filter exploded") {
+		t.Fatalf("expected the panic to be surfaced by Err, got %v", err)
+	}
+}
+
+func TestControllerStopsWhenStartContextIsCanceled(t *testing.T) {
+	controller, err := NewController(ControllerConfig{Directories: []string{t.TempDir()}, WarmUp: -1})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := controller.StartContext(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer controller.Stop()
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for controller.Running() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if controller.Running() {
+		t.Fatalf("expected the watcher to stop once its context was canceled")
+	}
+	if err := controller.Err(); err != nil {
+		t.Fatalf("expected cancellation not to be reported as a failure, got %v", err)
+	}
+}
+
+// tickingClock is a fake clock that advances by step every time it is read.
+type tickingClock struct {
+	*clock.Fake
+	step time.Duration
+}
+
+func (c tickingClock) Now() time.Time {
+	c.Fake.Advance(c.step)
+	return c.Fake.Now()
+}
+
+func TestFirstScanReportsProgress(t *testing.T) {
+	dir := t.TempDir()
+	total := 2*signatureBatch + 1
+	for i := 0; i < total; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file-%04d.txt", i)), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	defer backend.Close()
+
+	var reports []ScanProgress
+	monitor, err := NewHybridMonitor(HybridMonitorConfig{
+		Backend:        backend,
+		Directories:    []string{dir},
+		Clock:          tickingClock{Fake: clock.NewFake(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)), step: ScanProgressInterval},
+		OnScanProgress: func(p ScanProgress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatalf("new monitor: %v", err)
+	}
+	monitor.performSafetyScan()
+
+	want := []int{signatureBatch, 2 * signatureBatch, total, total}
+	if len(reports) != len(want) {
+		t.Fatalf("expected %d reports, got %+v", len(want), reports)
+	}
+	for i, report := range reports {
+		if report.Files != want[i] || report.Directory != dir || report.Done != (i == len(want)-1) {
+			t.Fatalf("report %d: got %+v, want %d files", i, report, want[i])
+		}
+	}
+
+	reports = nil
+	monitor.performSafetyScan()
+	if len(reports) != 0 {
+		t.Fatalf("expected only the first scan to report progress, got %+v", reports)
+	}
+}
+
+func TestTrackModeReportsPermissionChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deploy.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	for _, trackMode := range []bool{true, false} {
+		if err := os.Chmod(path, 0o644); err != nil {
+			t.Fatalf("chmod: %v", err)
+		}
+		backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+		if err != nil {
+			t.Fatalf("new backend: %v", err)
+		}
+		defer backend.Close()
+		var changes []reporting.Change
+		monitor, err := NewHybridMonitor(HybridMonitorConfig{
+			Backend:     backend,
+			Directories: []string{dir},
+			TrackMode:   trackMode,
+			OnChange:    func(change reporting.Change) { changes = append(changes, change) },
+		})
+		if err != nil {
+			t.Fatalf("new monitor: %v", err)
+		}
+		if err := monitor.scanDirectory(dir); err != nil {
+			t.Fatalf("initial scan: %v", err)
+		}
+		if err := os.Chmod(path, 0o755); err != nil {
+			t.Fatalf("chmod: %v", err)
+		}
+
+		changes = nil
+		if err := monitor.scanDirectory(dir); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if !trackMode {
+			if len(changes) != 0 {
+				t.Fatalf("expected a mode change to go unnoticed without TrackMode, got %+v", changes)
+			}
+			continue
+		}
+		if len(changes) != 1 || changes[0].Type != ChangePerm || changes[0].Source != reporting.SourceScan {
+			t.Fatalf("expected one PERM change from the scan, got %+v", changes)
+		}
+
+		changes = nil
+		if err := os.Chmod(path, 0o700); err != nil {
+			t.Fatalf("chmod: %v", err)
+		}
+		monitor.handleEvent(events.Event{Path: path, Type: events.EventModify, Timestamp: time.Now()})
+		if len(changes) != 1 || changes[0].Type != ChangePerm || changes[0].Source != reporting.SourceRealtime {
+			t.Fatalf("expected one realtime PERM change, got %+v", changes)
+		}
+
+		// Signatures loaded from a cache without modes match any mode.
+		sig, _ := monitor.cache.Get(path)
+		sig.Mode, sig.HasMode = 0, false
+		monitor.cache.Set(path, sig)
+		changes = nil
+		if err := os.Chmod(path, 0o755); err != nil {
+			t.Fatalf("chmod: %v", err)
+		}
+		if err := monitor.scanDirectory(dir); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if len(changes) != 0 {
+			t.Fatalf("expected no PERM change against a signature without a mode, got %+v", changes)
+		}
+	}
+}
+
+func TestAttributeChangesFollowTrackedAttributes(t *testing.T) {
+	previous := state.FileSignature{Size: 1, Mode: 0o644, HasMode: true, UID: 1000, GID: 1000, HasOwner: true}
+	current := previous
+	current.Mode, current.UID = 0o600, 0
+
+	for _, tc := range []struct {
+		trackMode, trackOwner bool
+		want                  []string
+	}{
+		{false, false, nil},
+		{true, false, []string{ChangePerm}},
+		{false, true, []string{ChangeOwner}},
+		{true, true, []string{ChangePerm, ChangeOwner}},
+	} {
+		monitor := &HybridMonitor{trackMode: tc.trackMode, trackOwner: tc.trackOwner}
+		got := monitor.attributeChanges(previous, current)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Fatalf("mode=%v owner=%v: expected %v, got %v", tc.trackMode, tc.trackOwner, tc.want, got)
+		}
+	}
+}
+
+func TestSafetyScansFollowScheduleOrStayOff(t *testing.T) {
+	schedule, err := clock.ParseSchedule("0 10 * * *")
+	if err != nil {
+		t.Fatalf("parse schedule: %v", err)
+	}
+	fake := clock.NewFake(time.Date(2025, 3, 1, 9, 59, 30, 0, time.UTC))
+
+	run := func(cfg HybridMonitorConfig) (*HybridMonitor, chan reporting.Change, func()) {
+		t.Helper()
+		backend, err := events.NewPollingBackend(time.Hour, events.BackendConfig{})
+		if err != nil {
+			t.Fatalf("new backend: %v", err)
+		}
+		changes := make(chan reporting.Change, 16)
+		cfg.Backend = backend
+		cfg.OnChange = func(change reporting.Change) { changes <- change }
+		monitor, err := NewHybridMonitor(cfg)
+		if err != nil {
+			t.Fatalf("new monitor: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = monitor.Run(ctx)
+		}()
+		return monitor, changes, func() {
+			cancel()
+			<-done
+			backend.Close()
+		}
+	}
+
+	dir := t.TempDir()
+	_, changes, stop := run(HybridMonitorConfig{Directories: []string{dir}, Clock: fake, ScanSchedule: schedule})
+	defer stop()
+	deadline := time.Now().Add(2 * time.Second)
+	for fake.Waiters() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	fake.Advance(29 * time.Second)
+	select {
+	case change := <-changes:
+		t.Fatalf("expected no scan before 10:00, got %v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+	fake.Advance(time.Second)
+	select {
+	case change := <-changes:
+		if change.Type != events.EventCreate {
+			t.Fatalf("expected the scheduled scan to find the new file, got %v", change)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a safety scan at 10:00")
+	}
+
+	offDir := t.TempDir()
+	_, offChanges, stopOff := run(HybridMonitorConfig{Directories: []string{offDir}, PollInterval: 10 * time.Millisecond, DisableSafetyScan: true})
+	defer stopOff()
+	if err := os.WriteFile(filepath.Join(offDir, "b.txt"), []byte("y"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	select {
+	case change := <-offChanges:
+		t.Fatalf("expected no safety scans when disabled, got %v", change)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestControllerRestartsDoNotInflateChangeCounts(t *testing.T) {
+	aggregator := reporting.NewAggregator()
+	dir := t.TempDir()
+	// Each restart by the supervisor starts a fresh controller.
+	for i := 0; i < 3; i++ {
+		controller, err := NewController(ControllerConfig{Directories: []string{dir}, Aggregator: aggregator, WarmUp: -1})
+		if err != nil {
+			t.Fatalf("new controller: %v", err)
+		}
+		if err := controller.Start(); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		controller.Stop()
+	}
+
+	snapshot := aggregator.Snapshot()
+	if snapshot.Count != 0 || len(snapshot.PerDirectory) != 0 || snapshot.LastChange != nil {
+		t.Fatalf("expected BOOT markers to stay out of the change counts, got %+v", snapshot)
+	}
+	if snapshot.Markers != 3 || snapshot.LastMarker == nil || snapshot.LastMarker.Type != ChangeBoot {
+		t.Fatalf("expected three BOOT markers, got %d (last %+v)", snapshot.Markers, snapshot.LastMarker)
+	}
+}
+
+type recordingSink struct {
+	changes []reporting.Change
+	err     error
+}
+
+func (s *recordingSink) Record(change reporting.Change) error {
+	s.changes = append(s.changes, change)
+	return s.err
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestEmitFansOutToEverySink(t *testing.T) {
+	failing := &recordingSink{err: fmt.Errorf("disk full")}
+	healthy := &recordingSink{}
+	var sinkErrs []error
+	var handled int
+	monitor := &HybridMonitor{
+		sinks:         []LogSink{failing, healthy},
+		onSinkError:   func(err error) { sinkErrs = append(sinkErrs, err) },
+		changeHandler: func(reporting.Change) { handled++ },
+	}
+
+	monitor.emit(reporting.Change{Type: "CREATE", Path: "/w/a.go"})
+	monitor.emit(reporting.Change{Type: ChangeBoot, Path: "/w"})
+
+	for _, sink := range []*recordingSink{failing, healthy} {
+		if len(sink.changes) != 2 || sink.changes[1].Type != ChangeBoot {
+			t.Fatalf("expected both changes, marker included, in every sink, got %+v", sink.changes)
+		}
+	}
+	if len(sinkErrs) != 2 || handled != 2 {
+		t.Fatalf("expected 2 sink errors and 2 handled changes, got %v and %d", sinkErrs, handled)
+	}
+}
+
+func TestControllerKeepsPollIntervalFixedWithoutBounds(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		min, max   time.Duration
+		wantAdapts bool
+	}{
+		{name: "no bounds"},
+		{name: "min only", min: 5 * time.Second},
+		{name: "both bounds", min: 5 * time.Second, max: time.Minute, wantAdapts: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controller, err := NewController(ControllerConfig{
+				Directories:     []string{t.TempDir()},
+				PollInterval:    20 * time.Second,
+				MinPollInterval: tc.min,
+				MaxPollInterval: tc.max,
+				WarmUp:          -1,
+			})
+			if err != nil {
+				t.Fatalf("new controller: %v", err)
+			}
+			if err := controller.Start(); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			defer controller.Stop()
+
+			monitor := controller.monitor
+			if adapts := monitor.maxPoll > 0; adapts != tc.wantAdapts {
+				t.Fatalf("adaptive = %v, want %v", adapts, tc.wantAdapts)
+			}
+			if got := monitor.PollInterval(); got != 20*time.Second {
+				t.Fatalf("expected the interval to start at 20s, got %s", got)
+			}
+		})
+	}
+}
+
+func TestControllerReportsChangesImmediatelyWithoutWarmUp(t *testing.T) {
+	dir := t.TempDir()
+	changes := make(chan reporting.Change, 16)
+	controller, err := NewController(ControllerConfig{
+		Directories:  []string{dir},
+		PollInterval: time.Hour,
+		NewBackend: func(cfg events.BackendConfig) (events.Backend, error) {
+			return events.NewPollingBackend(20*time.Millisecond, cfg)
+		},
+		OnChange: func(change reporting.Change) {
+			if !IsMarker(change.Type) {
+				changes <- change
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("new controller: %v", err)
+	}
+	if err := controller.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer controller.Stop()
+	if controller.monitor.warmUp != 0 {
+		t.Fatalf("expected no warm-up by default, got %s", controller.monitor.warmUp)
+	}
+
+	path := filepath.Join(dir, "new.txt")
+	deadline := time.After(time.Second)
+	for {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		select {
+		case change := <-changes:
+			if change.Path != path {
+				t.Fatalf("unexpected change %+v", change)
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("expected the new file to be reported without a warm-up")
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package watcher
+
+import "lowkey/internal/reporting"
+
+// LogSink receives every change the monitor records, including markers, so
+// a change log can be written to any store. The monitor calls Record from
+// its own goroutine, after the aggregator and before OnChange, so a sink
+// must not block for long. WatchLoggerPool, which writes the text logs under
+// .lowlog, is the default implementation.
+type LogSink interface {
+	// Record stores one change. An error is reported and does not stop the
+	// change from reaching the other sinks or OnChange.
+	Record(change reporting.Change) error
+	// Close flushes and releases the sink. The monitor never calls it: sinks
+	// outlive controller restarts and are closed by their owner.
+	Close() error
+}
+
+// Record implements LogSink by logging the change to its directory's log.
+func (p *WatchLoggerPool) Record(change reporting.Change) error {
+	return p.LogChange(change)
+}
+
+var _ LogSink = (*WatchLoggerPool)(nil)
@@ -3,16 +3,22 @@
 package watcher
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"lowkey/internal/clock"
 	"lowkey/internal/reporting"
 )
 
-// WatchLogger handles logging of file system changes to .lowkey directories
+// WatchLogger handles logging of file system changes to .lowlog directories
 // within each watched directory. It creates date-based log files and ensures
 // thread-safe writes.
 type WatchLogger struct {
@@ -21,16 +27,89 @@ type WatchLogger struct {
 	currentFile *os.File
 	currentDate string
 	lastLogTime *time.Time
+	clock       clock.Clock
+	recent      recentChanges
 	mu          sync.Mutex
+
+	// collapse enables repeat collapsing; see SetCollapseRepeats. last is
+	// the identity of the latest entry written, and pending the newest
+	// repeat of it not yet written, standing for pendingCount repeats.
+	collapse     bool
+	last         changeIdentity
+	pending      reporting.Change
+	pendingCount int
+}
+
+// DedupWindow is how close in time two changes with the same path and type
+// must be for WatchLogger to treat the second as a duplicate delivery, such as
+// a change reported by both the event stream and a safety scan.
+const DedupWindow = 2 * time.Second
+
+// maxRecentChanges bounds how many change identities a WatchLogger remembers
+// for deduplication.
+const maxRecentChanges = 4096
+
+// changeIdentity identifies a change for deduplication.
+type changeIdentity struct {
+	path string
+	typ  string
+}
+
+// recentChanges remembers when each change identity was last logged, for at
+// most DedupWindow and maxRecentChanges entries.
+type recentChanges struct {
+	seen  map[changeIdentity]time.Time
+	order []changeIdentity
+}
+
+// duplicate reports whether change repeats one logged less than DedupWindow
+// earlier, and records it otherwise.
+func (r *recentChanges) duplicate(change reporting.Change) bool {
+	if r.seen == nil {
+		r.seen = make(map[changeIdentity]time.Time)
+	}
+	// Forget identities that fell out of the window, oldest first; order
+	// holds each identity once, in the order it was last recorded.
+	for len(r.order) > 0 {
+		oldest := r.order[0]
+		if len(r.order) < maxRecentChanges && change.Timestamp.Sub(r.seen[oldest]) < DedupWindow {
+			break
+		}
+		delete(r.seen, oldest)
+		r.order = r.order[1:]
+	}
+
+	id := changeIdentity{path: change.Path, typ: change.Type}
+	if last, ok := r.seen[id]; ok {
+		if delta := change.Timestamp.Sub(last); delta > -DedupWindow && delta < DedupWindow {
+			return true
+		}
+		for i, other := range r.order {
+			if other == id {
+				r.order = append(r.order[:i], r.order[i+1:]...)
+				break
+			}
+		}
+	}
+	r.seen[id] = change.Timestamp
+	r.order = append(r.order, id)
+	return false
 }
 
 // NewWatchLogger creates a new logger for the specified directory.
-// It initializes the .lowkey directory structure if it doesn't exist.
+// It initializes the .lowlog directory structure if it doesn't exist.
 func NewWatchLogger(dir string) (*WatchLogger, error) {
-	logDir := filepath.Join(dir, ".lowkey")
+	return NewWatchLoggerAt(dir, filepath.Join(dir, ".lowlog"))
+}
+
+// NewWatchLoggerAt creates a logger for changes beneath dir that writes its
+// dated log files to logDir, creating it if needed. Paths in the log remain
+// relative to dir.
+func NewWatchLoggerAt(dir, logDir string) (*WatchLogger, error) {
 	logger := &WatchLogger{
 		baseDir: dir,
 		logDir:  logDir,
+		clock:   clock.Real{},
 	}
 
 	if err := logger.ensureLogDir(); err != nil {
@@ -45,11 +124,72 @@ func NewWatchLogger(dir string) (*WatchLogger, error) {
 }
 
 // LogChange writes a formatted change event to the current log file.
-// It handles date-based rotation automatically.
+// It handles date-based rotation automatically. A change with the same path
+// and type as one logged within DedupWindow of it is dropped, so a change
+// delivered twice is logged once. With repeat collapsing enabled, a change
+// repeating the previous entry is held back until Flush; see
+// SetCollapseRepeats.
 func (wl *WatchLogger) LogChange(change reporting.Change) error {
 	wl.mu.Lock()
 	defer wl.mu.Unlock()
 
+	marker := IsMarker(change.Type)
+	if !marker && wl.recent.duplicate(change) {
+		return nil
+	}
+
+	id := changeIdentity{path: change.Path, typ: change.Type}
+	if wl.collapse && !marker && id == wl.last {
+		wl.pending = change
+		wl.pendingCount++
+		return nil
+	}
+	if err := wl.flushLocked(); err != nil {
+		return err
+	}
+	if err := wl.writeLocked(change, ""); err != nil {
+		return err
+	}
+	wl.last = id
+	return nil
+}
+
+// SetCollapseRepeats enables or disables repeat collapsing. When enabled, a
+// run of changes with the same path and type as the previous entry is logged
+// as one line suffixed with "(repeated N times)", written with the time of
+// the last repeat once a different change arrives or on Flush or Close.
+func (wl *WatchLogger) SetCollapseRepeats(enabled bool) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	wl.collapse = enabled
+}
+
+// Flush writes the pending collapsed repeats, if any, so they are not lost
+// when logging stops.
+func (wl *WatchLogger) Flush() error {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	return wl.flushLocked()
+}
+
+// flushLocked writes the pending collapsed repeats. The caller must hold
+// wl.mu.
+func (wl *WatchLogger) flushLocked() error {
+	if wl.pendingCount == 0 {
+		return nil
+	}
+	suffix := " (repeated 1 time)"
+	if wl.pendingCount > 1 {
+		suffix = fmt.Sprintf(" (repeated %d times)", wl.pendingCount)
+	}
+	err := wl.writeLocked(wl.pending, suffix)
+	wl.pending, wl.pendingCount = reporting.Change{}, 0
+	return err
+}
+
+// writeLocked appends the entry for change, followed by suffix, to the
+// current log file. The caller must hold wl.mu.
+func (wl *WatchLogger) writeLocked(change reporting.Change, suffix string) error {
 	// Ensure we have the right log file for today
 	if err := wl.ensureCurrentLogFile(); err != nil {
 		return fmt.Errorf("watch logger: ensure log file: %w", err)
@@ -71,6 +211,9 @@ func (wl *WatchLogger) LogChange(change reporting.Change) error {
 
 	// Format the log entry
 	entry := wl.formatLogEntry(change)
+	if suffix != "" {
+		entry = strings.TrimSuffix(entry, "\n") + suffix + "\n"
+	}
 
 	// Write to file
 	if _, err := wl.currentFile.WriteString(entry); err != nil {
@@ -88,20 +231,32 @@ func (wl *WatchLogger) LogChange(change reporting.Change) error {
 	return nil
 }
 
-// Close closes the current log file if open.
+// SetClock replaces the clock used to pick the dated log file. It is intended
+// for tests that need to exercise date rollover deterministically.
+func (wl *WatchLogger) SetClock(c clock.Clock) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	wl.clock = clock.OrReal(c)
+}
+
+// Close writes any pending collapsed repeats and closes the current log file
+// if open.
 func (wl *WatchLogger) Close() error {
 	wl.mu.Lock()
 	defer wl.mu.Unlock()
 
+	flushErr := wl.flushLocked()
 	if wl.currentFile != nil {
 		err := wl.currentFile.Close()
 		wl.currentFile = nil
-		return err
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return flushErr
 }
 
-// ensureLogDir creates the .lowkey directory if it doesn't exist.
+// ensureLogDir creates the .lowlog directory if it doesn't exist.
 func (wl *WatchLogger) ensureLogDir() error {
 	return os.MkdirAll(wl.logDir, 0o755)
 }
@@ -109,7 +264,7 @@ func (wl *WatchLogger) ensureLogDir() error {
 // ensureCurrentLogFile ensures the correct date-based log file is open.
 // It handles rotation when the date changes.
 func (wl *WatchLogger) ensureCurrentLogFile() error {
-	today := time.Now().Format("2006-01-02")
+	today := wl.clock.Now().Format("2006-01-02")
 
 	// If date hasn't changed and file is open, nothing to do
 	if wl.currentDate == today && wl.currentFile != nil {
@@ -181,15 +336,31 @@ func (wl *WatchLogger) formatLogEntry(change reporting.Change) string {
 		changeType = "DELETED"
 	}
 
+	if change.IsBinary {
+		sizeInfo += " [binary]"
+	}
+
 	return fmt.Sprintf("[%s] [%s] %s%s\n", timestamp, changeType, relPath, sizeInfo)
 }
 
+// LogBoot writes a BOOT marker for the watched directory, recording that
+// watching (re)started at ts. Log readers use it to scope output to the
+// activity since the last start.
+func (wl *WatchLogger) LogBoot(ts time.Time) error {
+	return wl.LogChange(reporting.Change{Path: wl.baseDir, Type: ChangeBoot, Timestamp: ts})
+}
+
 // WatchLoggerPool manages multiple WatchLogger instances for different directories.
 // This is useful when watching multiple directories simultaneously.
 type WatchLoggerPool struct {
 	loggers map[string]*WatchLogger
 	mu      sync.RWMutex
 	enabled bool
+	// logRoot, when set, holds the log directories of every watched
+	// directory instead of their own .lowlog; see SetLogRoot.
+	logRoot string
+	// collapse is passed to every logger; see SetCollapseRepeats.
+	collapse bool
 }
 
 // NewWatchLoggerPool creates a new pool for managing multiple watch loggers.
@@ -269,15 +440,99 @@ func (p *WatchLoggerPool) getOrCreateLogger(dir string) (*WatchLogger, error) {
 		return logger, nil
 	}
 
-	logger, err := NewWatchLogger(dir)
+	var logger *WatchLogger
+	var err error
+	if p.logRoot == "" {
+		logger, err = NewWatchLogger(dir)
+	} else {
+		var logDir string
+		if logDir, err = claimLogDir(p.logRoot, dir); err == nil {
+			logger, err = NewWatchLoggerAt(dir, logDir)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	logger.SetCollapseRepeats(p.collapse)
 
 	p.loggers[dir] = logger
 	return logger, nil
 }
 
+// SetCollapseRepeats enables repeat collapsing in every logger of the pool;
+// see WatchLogger.SetCollapseRepeats. It must be called before directories
+// are added.
+func (p *WatchLoggerPool) SetCollapseRepeats(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.collapse = enabled
+}
+
+// SetLogRoot makes the pool write the logs of each watched directory to a
+// subdirectory of root named after the directory's path (for example
+// `/home/me/src` logs to `<root>/home_me_src`) instead of to its .lowlog. It
+// must be called before directories are added.
+//
+// Each subdirectory records the directory it belongs to, so when two
+// directories map to the same name the later one is given a name suffixed
+// with a hash of its path, and every directory keeps its log location across
+// runs.
+func (p *WatchLoggerPool) SetLogRoot(root string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logRoot = root
+}
+
+// logRootMarker is the file in each log subdirectory under a pool's log root
+// naming the watched directory it belongs to.
+const logRootMarker = ".root"
+
+// claimLogDir returns the subdirectory of root that holds the logs of dir,
+// claiming a free one on first use.
+func claimLogDir(root, dir string) (string, error) {
+	name := logDirName(dir)
+	sum := sha256.Sum256([]byte(dir))
+	for _, candidate := range []string{name, name + "-" + hex.EncodeToString(sum[:4])} {
+		logDir := filepath.Join(root, candidate)
+		marker := filepath.Join(logDir, logRootMarker)
+		owner, err := os.ReadFile(marker)
+		switch {
+		case err == nil:
+			if strings.TrimSpace(string(owner)) == dir {
+				return logDir, nil
+			}
+		case errors.Is(err, fs.ErrNotExist):
+			if err := os.MkdirAll(logDir, 0o755); err != nil {
+				return "", fmt.Errorf("watch logger: create log dir: %w", err)
+			}
+			if err := os.WriteFile(marker, []byte(dir+"\n"), 0o644); err != nil {
+				return "", fmt.Errorf("watch logger: claim log dir: %w", err)
+			}
+			return logDir, nil
+		default:
+			return "", fmt.Errorf("watch logger: claim log dir: %w", err)
+		}
+	}
+	return "", fmt.Errorf("watch logger: no free log directory for %s under %s", dir, root)
+}
+
+// logDirName turns the path of a watched directory into a single file name by
+// replacing separators and other unusual characters with underscores.
+func logDirName(dir string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, filepath.Clean(dir))
+	name = strings.Trim(name, "_.")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
 // AddDirectory adds a directory to be logged.
 func (p *WatchLoggerPool) AddDirectory(dir string) error {
 	if !p.enabled {
@@ -288,7 +543,39 @@ func (p *WatchLoggerPool) AddDirectory(dir string) error {
 	return err
 }
 
-// Close closes all loggers in the pool.
+// LogBoot writes a BOOT marker to the log of every directory in the pool.
+func (p *WatchLoggerPool) LogBoot(ts time.Time) error {
+	if !p.enabled {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var lastErr error
+	for _, logger := range p.loggers {
+		if err := logger.LogBoot(ts); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Flush writes the pending collapsed repeats of every logger in the pool.
+func (p *WatchLoggerPool) Flush() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var lastErr error
+	for _, logger := range p.loggers {
+		if err := logger.Flush(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Close closes all loggers in the pool, which first write their pending
+// collapsed repeats.
 func (p *WatchLoggerPool) Close() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
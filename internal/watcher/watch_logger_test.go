@@ -3,8 +3,12 @@ package watcher
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"lowkey/internal/clock"
+	"lowkey/internal/reporting"
 )
 
 func TestNewWatchLoggerCreatesDailyLogFile(t *testing.T) {
@@ -20,7 +24,7 @@ func TestNewWatchLoggerCreatesDailyLogFile(t *testing.T) {
 		}
 	})
 
-	todayLog := filepath.Join(baseDir, ".lowkey", time.Now().Format("2006-01-02")+".log")
+	todayLog := filepath.Join(baseDir, ".lowlog", time.Now().Format("2006-01-02")+".log")
 
 	info, err := os.Stat(todayLog)
 	if err != nil {
@@ -35,3 +39,216 @@ func TestNewWatchLoggerCreatesDailyLogFile(t *testing.T) {
 		t.Fatalf("expected log file to be empty, got size %d", size)
 	}
 }
+
+func TestWatchLoggerInsertsGapAfterAnHour(t *testing.T) {
+	baseDir := t.TempDir()
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.Local)
+	fake := clock.NewFake(start)
+
+	logger, err := NewWatchLogger(baseDir)
+	if err != nil {
+		t.Fatalf("NewWatchLogger returned error: %v", err)
+	}
+	defer logger.Close()
+	logger.SetClock(fake)
+
+	path := filepath.Join(baseDir, "a.txt")
+	if err := logger.LogChange(reporting.Change{Path: path, Type: "CREATE", Timestamp: start}); err != nil {
+		t.Fatalf("log first change: %v", err)
+	}
+	fake.Advance(time.Hour)
+	if err := logger.LogChange(reporting.Change{Path: path, Type: "DELETE", Timestamp: fake.Now()}); err != nil {
+		t.Fatalf("log second change: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, ".lowlog", "2025-03-01.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	blank := 0
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if line == "" {
+			blank++
+		}
+	}
+	if blank != 9 {
+		t.Fatalf("expected a 9-line gap between entries, got %d blank lines in %q", blank, data)
+	}
+}
+
+func TestWatchLoggerRollsOverAtMidnight(t *testing.T) {
+	baseDir := t.TempDir()
+	start := time.Date(2025, 3, 1, 23, 59, 0, 0, time.Local)
+	fake := clock.NewFake(start)
+
+	logger, err := NewWatchLogger(baseDir)
+	if err != nil {
+		t.Fatalf("NewWatchLogger returned error: %v", err)
+	}
+	defer logger.Close()
+	logger.SetClock(fake)
+
+	path := filepath.Join(baseDir, "a.txt")
+	if err := logger.LogChange(reporting.Change{Path: path, Type: "CREATE", Timestamp: start}); err != nil {
+		t.Fatalf("log before midnight: %v", err)
+	}
+	fake.Advance(2 * time.Minute)
+	if err := logger.LogChange(reporting.Change{Path: path, Type: "MODIFY", Timestamp: fake.Now()}); err != nil {
+		t.Fatalf("log after midnight: %v", err)
+	}
+
+	for _, name := range []string{"2025-03-01.log", "2025-03-02.log"} {
+		data, err := os.ReadFile(filepath.Join(baseDir, ".lowlog", name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 1 {
+			t.Fatalf("expected exactly one entry in %s, got %q", name, data)
+		}
+	}
+}
+
+func TestWatchLoggerLogBootWritesMarker(t *testing.T) {
+	baseDir := t.TempDir()
+	logger, err := NewWatchLogger(baseDir)
+	if err != nil {
+		t.Fatalf("NewWatchLogger returned error: %v", err)
+	}
+	defer logger.Close()
+
+	ts := time.Now()
+	if err := logger.LogBoot(ts); err != nil {
+		t.Fatalf("LogBoot returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, ".lowlog", ts.Format("2006-01-02")+".log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := "[" + ts.Format("2006-01-02 15:04:05") + "] [BOOT] .\n"
+	if string(data) != want {
+		t.Fatalf("expected %q, got %q", want, string(data))
+	}
+}
+
+func TestWatchLoggerDropsDoubleDeliveredChanges(t *testing.T) {
+	baseDir := t.TempDir()
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.Local)
+	logger, err := NewWatchLogger(baseDir)
+	if err != nil {
+		t.Fatalf("NewWatchLogger returned error: %v", err)
+	}
+	defer logger.Close()
+	logger.SetClock(clock.NewFake(start))
+
+	path := filepath.Join(baseDir, "a.txt")
+	changes := []reporting.Change{
+		// The event stream and a safety scan both report the same creation.
+		{Path: path, Type: "CREATE", Timestamp: start, Size: 3},
+		{Path: path, Type: "CREATE", Timestamp: start.Add(time.Second), Size: 3},
+		{Path: path, Type: "MODIFY", Timestamp: start.Add(time.Second), SizeDelta: 1},
+		// Outside the window, the same change is logged again.
+		{Path: path, Type: "CREATE", Timestamp: start.Add(DedupWindow + time.Second), Size: 3},
+	}
+	for _, change := range changes {
+		if err := logger.LogChange(change); err != nil {
+			t.Fatalf("LogChange: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, ".lowlog", "2025-03-01.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := "[2025-03-01 09:00:00] [NEW] a.txt (3 bytes)\n" +
+		"[2025-03-01 09:00:01] [MODIFIED] a.txt (+1 bytes)\n" +
+		"[2025-03-01 09:00:03] [NEW] a.txt (3 bytes)\n"
+	if string(data) != want {
+		t.Fatalf("expected %q, got %q", want, string(data))
+	}
+}
+
+func TestWatchLoggerPoolLogRootNamespacesAndSeparatesCollisions(t *testing.T) {
+	base := t.TempDir()
+	logRoot := filepath.Join(t.TempDir(), "logs")
+	first := filepath.Join(base, "a_b")
+	second := filepath.Join(base, "a", "b")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+
+	logDirs := func() map[string]string {
+		pool := NewWatchLoggerPool(true)
+		pool.SetLogRoot(logRoot)
+		defer pool.Close()
+		for _, dir := range []string{first, second} {
+			if err := pool.AddDirectory(dir); err != nil {
+				t.Fatalf("AddDirectory(%s): %v", dir, err)
+			}
+		}
+		dirs := make(map[string]string)
+		for dir, logger := range pool.loggers {
+			dirs[dir] = logger.logDir
+		}
+		return dirs
+	}
+
+	dirs := logDirs()
+	if got, want := dirs[first], filepath.Join(logRoot, logDirName(first)); got != want {
+		t.Fatalf("expected %s to log to %s, got %s", first, want, got)
+	}
+	if dirs[second] == dirs[first] || !strings.HasPrefix(filepath.Base(dirs[second]), logDirName(second)+"-") {
+		t.Fatalf("expected colliding directory to get a suffixed name, got %s", dirs[second])
+	}
+	if _, err := os.Stat(filepath.Join(first, ".lowlog")); !os.IsNotExist(err) {
+		t.Fatalf("expected no in-tree .lowlog when a log root is set")
+	}
+
+	// Locations are stable across runs, whichever directory is added first.
+	if again := logDirs(); again[first] != dirs[first] || again[second] != dirs[second] {
+		t.Fatalf("expected stable log directories, got %v then %v", dirs, again)
+	}
+}
+
+func TestWatchLoggerPoolCloseFlushesCollapsedRepeats(t *testing.T) {
+	baseDir := t.TempDir()
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.Local)
+	pool := NewWatchLoggerPool(true)
+	pool.SetCollapseRepeats(true)
+	if err := pool.AddDirectory(baseDir); err != nil {
+		t.Fatalf("AddDirectory: %v", err)
+	}
+	pool.loggers[baseDir].SetClock(clock.NewFake(start))
+
+	busy := filepath.Join(baseDir, "busy.log")
+	for i := 0; i < 4; i++ {
+		// Spaced beyond DedupWindow, so each is a real repeat.
+		change := reporting.Change{Path: busy, Type: "MODIFY", Timestamp: start.Add(time.Duration(i) * 5 * time.Second), SizeDelta: 1}
+		if err := pool.LogChange(change); err != nil {
+			t.Fatalf("LogChange: %v", err)
+		}
+	}
+	logPath := filepath.Join(baseDir, ".lowlog", "2025-03-01.log")
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if want := "[2025-03-01 09:00:00] [MODIFIED] busy.log (+1 bytes)\n"; string(data) != want {
+		t.Fatalf("expected the repeats to be pending, got %q", string(data))
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data, err = os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := "[2025-03-01 09:00:00] [MODIFIED] busy.log (+1 bytes)\n" +
+		"[2025-03-01 09:00:15] [MODIFIED] busy.log (+1 bytes) (repeated 3 times)\n"
+	if string(data) != want {
+		t.Fatalf("expected the pending repeats to be written on close, got %q", string(data))
+	}
+}
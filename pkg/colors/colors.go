@@ -5,6 +5,7 @@ package colors
 
 import (
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -15,6 +16,7 @@ const (
 	Yellow  = "\033[0;33m"
 	Blue    = "\033[0;34m"
 	Magenta = "\033[0;35m"
+	Dim     = "\033[2m"
 	Reset   = "\033[0m"
 )
 
@@ -52,12 +54,22 @@ func Colorize(text, color string) string {
 
 // Printf prints formatted text with color support
 func Printf(color, format string, args ...interface{}) {
-	fmt.Print(Colorize(fmt.Sprintf(format, args...), color))
+	Fprintf(os.Stdout, color, format, args...)
 }
 
 // Println prints text with color support and a newline
 func Println(color, text string) {
-	fmt.Println(Colorize(text, color))
+	Fprintln(os.Stdout, color, text)
+}
+
+// Fprintf writes formatted text to w with color support
+func Fprintf(w io.Writer, color, format string, args ...interface{}) {
+	fmt.Fprint(w, Colorize(fmt.Sprintf(format, args...), color))
+}
+
+// Fprintln writes text to w with color support and a newline
+func Fprintln(w io.Writer, color, text string) {
+	fmt.Fprintln(w, Colorize(text, color))
 }
 
 // EventColor returns the appropriate color for a given event type
@@ -69,6 +81,8 @@ func EventColor(eventType string) string {
 		return Yellow
 	case "DELETED", "DELETE":
 		return Red
+	case "PERM", "CHOWN":
+		return Magenta
 	default:
 		return Reset
 	}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// IgnoreFileName is the name of the per-directory ignore file.
+const IgnoreFileName = ".lowkey"
+
+// UserIgnoreFile returns the location of the user-level ignore file,
+// `lowkey/ignore` under the user configuration directory (usually
+// `~/.config/lowkey/ignore`, or `$XDG_CONFIG_HOME/lowkey/ignore`).
+func UserIgnoreFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("config: locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "lowkey", "ignore"), nil
+}
+
+// IgnoreFiles lists every ignore file consulted for manifest and dirs, from
+// highest to lowest precedence:
+//
+//  1. the `.lowkey` file in each watched directory, in directory order;
+//  2. the manifest's ignore_file, when set;
+//  3. the user-level file returned by UserIgnoreFile.
+//
+// The files need not exist, so callers watching them for changes notice when
+// one is created. A nil manifest contributes no ignore_file.
+func IgnoreFiles(manifest *Manifest, dirs []string) []string {
+	paths := make([]string, 0, len(dirs)+2)
+	for _, dir := range dirs {
+		paths = append(paths, filepath.Join(dir, IgnoreFileName))
+	}
+	if manifest != nil && manifest.IgnoreFile != "" {
+		paths = append(paths, manifest.IgnoreFile)
+	}
+	if user, err := UserIgnoreFile(); err == nil {
+		paths = append(paths, user)
+	}
+	return paths
+}
+
+// ResolveIgnorePatterns merges the patterns of every file returned by
+// IgnoreFiles, mirroring git's layering of repository and global ignore
+// files. Patterns are deduplicated, keeping the first occurrence, so they are
+// ordered by precedence and a path matched by several sources is attributed
+// to the most specific one.
+//
+// Missing `.lowkey` and user-level files are skipped, but a missing
+// ignore_file is an error because the manifest names it explicitly. Files
+// that fail to load are skipped and their errors joined into the returned
+// error alongside the patterns that did load.
+func ResolveIgnorePatterns(manifest *Manifest, dirs []string) ([]string, error) {
+	var patterns []string
+	var problems []error
+	seen := make(map[string]struct{})
+	for _, path := range IgnoreFiles(manifest, dirs) {
+		loaded, err := LoadIgnorePatterns(path)
+		if err != nil {
+			explicit := manifest != nil && path == manifest.IgnoreFile
+			if explicit || !errors.Is(err, fs.ErrNotExist) {
+				problems = append(problems, err)
+			}
+			continue
+		}
+		for _, pattern := range loaded {
+			if _, ok := seen[pattern]; ok {
+				continue
+			}
+			seen[pattern] = struct{}{}
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, errors.Join(problems...)
+}
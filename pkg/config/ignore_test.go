@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveIgnorePatternsMergesLayersByPrecedence(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(base, "config"))
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	project := filepath.Join(base, "project")
+	docs := filepath.Join(base, "docs")
+	if err := os.MkdirAll(docs, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	write(filepath.Join(project, IgnoreFileName), "build/\n*.log\n")
+	global := filepath.Join(base, "global-ignore")
+	write(global, "*.log\n*.tmp\n")
+	user, err := UserIgnoreFile()
+	if err != nil {
+		t.Fatalf("user ignore file: %v", err)
+	}
+	write(user, "*.tmp\n.DS_Store\n")
+
+	manifest := &Manifest{Directories: []string{project, docs}, IgnoreFile: global}
+	patterns, err := ResolveIgnorePatterns(manifest, manifest.Directories)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := []string{"build/", "*.log", "*.tmp", ".DS_Store"}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Fatalf("patterns = %q, want %q", patterns, want)
+	}
+
+	manifest.IgnoreFile = filepath.Join(base, "missing")
+	patterns, err = ResolveIgnorePatterns(manifest, manifest.Directories)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a missing ignore_file to be reported, got %v", err)
+	}
+	if want := []string{"build/", "*.log", "*.tmp", ".DS_Store"}; !reflect.DeepEqual(patterns, want) {
+		t.Fatalf("expected the remaining layers to load, got %q", patterns)
+	}
+}
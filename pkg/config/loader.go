@@ -9,11 +9,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
+
+	"lowkey/internal/filters"
 )
 
 // Manifest represents the persisted daemon configuration. It specifies which
@@ -24,8 +30,111 @@ type Manifest struct {
 	Directories []string `json:"directories"`
 	LogPath     string   `json:"log_path,omitempty"`
 	IgnoreFile  string   `json:"ignore_file,omitempty"`
+	// Disabled lists directories that stay in Directories but are not
+	// watched or scanned until they are enabled again.
+	Disabled []string `json:"disabled,omitempty"`
+	// Include, when non-empty, limits watching to files matching at least one
+	// of these globs. It is applied before the ignore file's patterns.
+	Include []string `json:"include,omitempty"`
+	// EventBuffer overrides the watcher's event channel capacity. Zero keeps
+	// the default of 256.
+	EventBuffer int `json:"event_buffer,omitempty"`
+	// MaxTrackedFiles caps how many files the watcher tracks. Zero keeps the
+	// default of 500,000 and a negative value disables the cap.
+	MaxTrackedFiles int `json:"max_tracked_files,omitempty"`
+	// ChurnThreshold is the number of changes within one second in a single
+	// directory above which the daemon warns about churn. Zero keeps the
+	// default of 1,000 and a negative value disables the warning.
+	ChurnThreshold int `json:"churn_threshold,omitempty"`
+	// ScanConcurrency is how many files the watcher hashes in parallel while
+	// scanning. Zero uses one worker per CPU and 1 scans serially.
+	ScanConcurrency int `json:"scan_concurrency,omitempty"`
+	// HashThreshold is the largest file, in bytes, whose content is hashed
+	// to detect edits that keep its size and modification time; zero keeps
+	// the default of 4096. HashReadLimit caps how many bytes of such a file
+	// are hashed, zero hashing all of it. Edits past the read limit go
+	// unnoticed when the size and modification time are unchanged.
+	HashThreshold int64 `json:"hash_threshold,omitempty"`
+	HashReadLimit int64 `json:"hash_read_limit,omitempty"`
+	// TrackMode reports permission changes to files whose content did not
+	// change as PERM changes.
+	TrackMode bool `json:"track_mode,omitempty"`
+	// TrackOwner reports changes to the owning user or group of files whose
+	// content did not change as CHOWN changes. It has no effect on Windows.
+	TrackOwner bool `json:"track_owner,omitempty"`
+	// SkipHidden stops watching files and directories whose name starts with
+	// `.` beneath the watched directories. Hidden files are watched by default.
+	SkipHidden bool `json:"skip_hidden,omitempty"`
+	// LogLevel selects the daemon log verbosity: "info" (the default) or
+	// "debug", which also logs every raw backend event and why it was or was
+	// not recorded.
+	LogLevel string `json:"log_level,omitempty"`
+	// ExecOnChange is a shell command the daemon runs for each changed file,
+	// with {path}, {type}, and {dir} replaced by shell-quoted values. Rapid
+	// changes are debounced and runs never overlap.
+	ExecOnChange string `json:"exec_on_change,omitempty"`
+	// ExecBatch is a shell command the daemon runs once per debounced burst
+	// of changes, with the changed paths on stdin (one per line) and in place
+	// of {paths}.
+	ExecBatch string `json:"exec_batch,omitempty"`
+	// ExecRestart interrupts a still-running ExecOnChange or ExecBatch
+	// command when the next burst of changes is ready, and runs it again.
+	ExecRestart bool `json:"exec_restart,omitempty"`
+	// WebhookURL is an http(s) endpoint the daemon POSTs a JSON document to
+	// for each change. Deliveries are queued and retried with backoff.
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// WebhookToken, when set, is sent to WebhookURL as a bearer credential.
+	WebhookToken string `json:"webhook_token,omitempty"`
+	// WebhookSecret, when set, signs each webhook request body with
+	// HMAC-SHA256, sent in the X-Lowkey-Signature header.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+	// WebhookHeaders are extra HTTP headers sent with each webhook request.
+	WebhookHeaders map[string]string `json:"webhook_headers,omitempty"`
+	// WebhookBatchSize, when positive, POSTs changes as JSON arrays of up to
+	// this many documents, each sent once full or WebhookBatchInterval (a Go
+	// duration, "1s" when empty) after its first change.
+	WebhookBatchSize     int    `json:"webhook_batch_size,omitempty"`
+	WebhookBatchInterval string `json:"webhook_batch_interval,omitempty"`
+	// Mirror is an absolute directory the daemon copies changed files to,
+	// removing the files deleted from the watched directories. It must not
+	// overlap a watched directory.
+	Mirror string `json:"mirror,omitempty"`
+	// ScanIntervalMin and ScanIntervalMax, as Go durations such as "10s" or
+	// "5m", make the safety-scan interval adaptive between them. The interval
+	// lengthens while scans find nothing the event backend missed and
+	// shortens when they keep finding missed changes. Unless both are set,
+	// the interval stays fixed.
+	ScanIntervalMin string `json:"scan_interval_min,omitempty"`
+	ScanIntervalMax string `json:"scan_interval_max,omitempty"`
+	// SafetyScan selects when periodic safety scans run: empty or
+	// "interval" for the (adaptive) interval, "off" to rely on the event
+	// backend alone, or a cron expression such as "0 3 * * *".
+	SafetyScan string `json:"safety_scan,omitempty"`
+	// IgnoreOlderThan and IgnoreNewerThan, as Go durations such as "1h",
+	// drop changes to files last modified longer ago, or more recently, than
+	// the duration. The age is re-evaluated on every event and scan.
+	IgnoreOlderThan string `json:"ignore_older_than,omitempty"`
+	IgnoreNewerThan string `json:"ignore_newer_than,omitempty"`
+	// WarmUp, as a Go duration such as "2s", is a settling period after the
+	// watcher starts during which the baseline is built without reporting
+	// changes. Empty or "0" disables it.
+	WarmUp string `json:"warm_up,omitempty"`
+	// Observability optionally configures metrics and tracing for the daemon.
+	Observability *ObservabilityConfig `json:"observability,omitempty"`
 }
 
+// Log levels accepted in Manifest.LogLevel.
+const (
+	LogLevelInfo  = "info"
+	LogLevelDebug = "debug"
+)
+
+// Keywords accepted in Manifest.SafetyScan besides a cron expression.
+const (
+	SafetyScanInterval = "interval"
+	SafetyScanOff      = "off"
+)
+
 // LoadManifest parses a manifest file from disk. It performs validation and
 // normalization, ensuring that all paths are absolute and ready for use.
 // This function is the primary entry point for loading a daemon's
@@ -35,17 +144,41 @@ func LoadManifest(path string) (*Manifest, error) {
 	if err != nil {
 		return nil, fmt.Errorf("config: read manifest %q: %w", path, err)
 	}
+	return decodeManifest(data, fmt.Sprintf("%q", path), filepath.Dir(path))
+}
+
+// ReadManifest parses a manifest from r, such as standard input, resolving
+// relative paths against base since there is no file directory to anchor
+// them to. Empty input is an error.
+func ReadManifest(r io.Reader, base string) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("config: read manifest from stdin: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("config: manifest from stdin is empty")
+	}
+	return decodeManifest(data, "from stdin", base)
+}
 
+// decodeManifest parses data and normalizes its paths against dir. source
+// names where data came from in error messages.
+func decodeManifest(data []byte, source, dir string) (*Manifest, error) {
 	var manifest Manifest
 	if err := json.Unmarshal(data, &manifest); err != nil {
-		return nil, fmt.Errorf("config: decode manifest %q: %w", path, err)
+		return nil, fmt.Errorf("config: decode manifest %s: %w", source, err)
 	}
 
-	dir := filepath.Dir(path)
+	var err error
 	manifest.Directories, err = normalizeDirectories(dir, manifest.Directories)
 	if err != nil {
 		return nil, err
 	}
+	if len(manifest.Disabled) > 0 {
+		if manifest.Disabled, err = normalizeDirectories(dir, manifest.Disabled); err != nil {
+			return nil, err
+		}
+	}
 	manifest.LogPath, err = normalizeLogPath(dir, manifest.LogPath)
 	if err != nil {
 		return nil, err
@@ -59,9 +192,11 @@ func LoadManifest(path string) (*Manifest, error) {
 }
 
 // LoadIgnorePatterns reads a `.lowkey` ignore file. Lines beginning with `#`
-// or blank lines are ignored. Paths are returned as provided to match glob
-// semantics understood by the watcher layer. This allows for flexible and
-// powerful ignore patterns.
+// or blank lines are ignored, and a `#` preceded by whitespace starts a
+// trailing comment. Surrounding whitespace is dropped unless escaped: `\#`
+// is a literal hash and `foo\ ` keeps its trailing space. Escapes are left in
+// the returned patterns, which follow the glob semantics understood by the
+// watcher layer.
 func LoadIgnorePatterns(path string) ([]string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -71,20 +206,52 @@ func LoadIgnorePatterns(path string) ([]string, error) {
 	lines := strings.Split(string(data), "\n")
 	patterns := make([]string, 0, len(lines))
 	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
+		if pattern := parseIgnoreLine(line); pattern != "" {
+			patterns = append(patterns, pattern)
 		}
-		patterns = append(patterns, trimmed)
 	}
 	return patterns, nil
 }
 
+// parseIgnoreLine returns the pattern on one ignore file line, or "" for a
+// blank or comment line. A `#` only starts a trailing comment when it follows
+// unescaped whitespace, so `a#b` stays a single pattern.
+func parseIgnoreLine(line string) string {
+	line = strings.TrimLeftFunc(line, unicode.IsSpace)
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	afterSpace := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\':
+			// Skip the escaped byte; it is never a comment or separator.
+			i++
+			afterSpace = false
+		case c == '#' && afterSpace:
+			line = line[:i]
+			i = len(line)
+		default:
+			afterSpace = unicode.IsSpace(rune(c))
+		}
+	}
+	return filters.TrimPattern(line)
+}
+
 // BuildManifestFromArgs creates a manifest from CLI-supplied directories. The
 // basePath parameter is typically the current working directory, used to resolve
 // relative directory paths into absolute ones.
-func BuildManifestFromArgs(basePath string, dirs []string) (*Manifest, error) {
-	normalized, err := normalizeDirectories(basePath, dirs)
+//
+// Arguments containing glob metacharacters (`*`, `?`, `[`) are expanded with
+// filepath.Glob, so `services/*/logs` watches every matching directory.
+// Matches that are not directories are skipped and reported through warn when
+// it is non-nil; a pattern matching no directory is an error.
+func BuildManifestFromArgs(basePath string, dirs []string, warn func(string)) (*Manifest, error) {
+	expanded, err := expandDirectoryGlobs(basePath, dirs, warn)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := normalizeDirectories(basePath, expanded)
 	if err != nil {
 		return nil, err
 	}
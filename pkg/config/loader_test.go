@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadIgnorePatternsHandlesCommentsAndEscapes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".lowkey")
+	content := "# full-line comment\n" +
+		"   \n" +
+		"*.log   # trailing comment\n" +
+		"\\#notes.md\n" +
+		"issue#42.txt\n" +
+		"keep\\ \n" +
+		"keep-two\\   \n" +
+		"dist\\  # comment after escaped space\n" +
+		"  build/  \r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+
+	patterns, err := LoadIgnorePatterns(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := []string{"*.log", `\#notes.md`, "issue#42.txt", `keep\ `, `keep-two\ `, `dist\ `, "build/"}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Fatalf("patterns = %q, want %q", patterns, want)
+	}
+}
+
+func TestBuildManifestFromArgsExpandsGlobs(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"services/api/logs", "services/web/logs", "services/db"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	// A file matching the pattern is skipped with a warning.
+	if err := os.MkdirAll(filepath.Join(base, "services", "cron"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "services", "cron", "logs"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var warnings []string
+	manifest, err := BuildManifestFromArgs(base, []string{"services/*/logs", "plain"}, func(msg string) {
+		warnings = append(warnings, msg)
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	want := []string{
+		filepath.Join(base, "plain"),
+		filepath.Join(base, "services", "api", "logs"),
+		filepath.Join(base, "services", "web", "logs"),
+	}
+	if !reflect.DeepEqual(manifest.Directories, want) {
+		t.Fatalf("directories = %q, want %q", manifest.Directories, want)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning for the file match, got %q", warnings)
+	}
+
+	if _, err := BuildManifestFromArgs(base, []string{"nothing/*"}, nil); err == nil {
+		t.Fatalf("expected a pattern without matches to fail")
+	}
+}
+
+func TestReadManifestResolvesAgainstBase(t *testing.T) {
+	base := t.TempDir()
+	if err := os.Mkdir(filepath.Join(base, "src"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	manifest, err := ReadManifest(strings.NewReader(`{"directories":["src"],"log_path":"logs/lowkey.log"}`), base)
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	if want := []string{filepath.Join(base, "src")}; !reflect.DeepEqual(manifest.Directories, want) {
+		t.Fatalf("expected directories %v, got %v", want, manifest.Directories)
+	}
+	if want := filepath.Join(base, "logs", "lowkey.log"); manifest.LogPath != want {
+		t.Fatalf("expected log path %s, got %s", want, manifest.LogPath)
+	}
+
+	for _, input := range []string{"", " \n", `{"directories":`} {
+		if _, err := ReadManifest(strings.NewReader(input), base); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
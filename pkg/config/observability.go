@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Environment variables recognised by ResolveObservability. EnvDaemonMetrics is
+// an older alias for EnvMetricsAddr and is consulted only when the latter is
+// unset.
+const (
+	EnvMetricsAddr   = "LOWKEY_METRICS_ADDR"
+	EnvDaemonMetrics = "LOWKEY_DAEMON_METRICS"
+	EnvMetricsToken  = "LOWKEY_METRICS_TOKEN"
+	EnvTraceEnabled  = "LOWKEY_TRACE_ENABLED"
+	EnvTraceEndpoint = "LOWKEY_TRACE_ENDPOINT"
+)
+
+// ObservabilityConfig gathers the metrics and tracing settings for the daemon.
+// It can be embedded in a manifest as the `observability` block and is
+// resolved once at daemon startup.
+type ObservabilityConfig struct {
+	MetricsAddr   string `json:"metrics_addr,omitempty"`
+	MetricsToken  string `json:"metrics_token,omitempty"`
+	TraceEnabled  bool   `json:"trace_enabled,omitempty"`
+	TraceEndpoint string `json:"trace_endpoint,omitempty"`
+}
+
+// ObservabilityFlags carries observability values supplied on the command
+// line. Empty strings and a nil TraceEnabled mean the flag was not given.
+type ObservabilityFlags struct {
+	MetricsAddr   string
+	MetricsToken  string
+	TraceEnabled  *bool
+	TraceEndpoint string
+}
+
+// ResolveObservability merges the observability settings with the precedence
+// flag > environment > manifest > default. getenv is typically os.Getenv; a
+// nil manifest or one without an observability block contributes nothing.
+func ResolveObservability(flags ObservabilityFlags, getenv func(string) string, manifest *Manifest) (ObservabilityConfig, error) {
+	var cfg ObservabilityConfig
+	if manifest != nil && manifest.Observability != nil {
+		cfg = *manifest.Observability
+	}
+
+	if getenv != nil {
+		if addr := getenv(EnvMetricsAddr); addr != "" {
+			cfg.MetricsAddr = addr
+		} else if addr := getenv(EnvDaemonMetrics); addr != "" {
+			cfg.MetricsAddr = addr
+		}
+		if token := getenv(EnvMetricsToken); token != "" {
+			cfg.MetricsToken = token
+		}
+		if raw := getenv(EnvTraceEnabled); raw != "" {
+			enabled, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				return ObservabilityConfig{}, fmt.Errorf("config: invalid %s %q", EnvTraceEnabled, raw)
+			}
+			cfg.TraceEnabled = enabled
+		}
+		if endpoint := getenv(EnvTraceEndpoint); endpoint != "" {
+			cfg.TraceEndpoint = endpoint
+		}
+	}
+
+	if flags.MetricsAddr != "" {
+		cfg.MetricsAddr = flags.MetricsAddr
+	}
+	if flags.MetricsToken != "" {
+		cfg.MetricsToken = flags.MetricsToken
+	}
+	if flags.TraceEnabled != nil {
+		cfg.TraceEnabled = *flags.TraceEnabled
+	}
+	if flags.TraceEndpoint != "" {
+		cfg.TraceEndpoint = flags.TraceEndpoint
+	}
+	return cfg, nil
+}
+
+// Env renders the configuration as environment assignments understood by
+// ResolveObservability, used to hand resolved settings to a child process.
+// Unset values are omitted.
+func (c ObservabilityConfig) Env() []string {
+	var env []string
+	if c.MetricsAddr != "" {
+		env = append(env, EnvMetricsAddr+"="+c.MetricsAddr)
+	}
+	if c.MetricsToken != "" {
+		env = append(env, EnvMetricsToken+"="+c.MetricsToken)
+	}
+	env = append(env, EnvTraceEnabled+"="+strconv.FormatBool(c.TraceEnabled))
+	if c.TraceEndpoint != "" {
+		env = append(env, EnvTraceEndpoint+"="+c.TraceEndpoint)
+	}
+	return env
+}
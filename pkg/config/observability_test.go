@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveObservabilityPrecedence(t *testing.T) {
+	manifest := &Manifest{Observability: &ObservabilityConfig{
+		MetricsAddr:   "127.0.0.1:9000",
+		MetricsToken:  "manifest-token",
+		TraceEndpoint: "http://manifest/traces",
+	}}
+	env := map[string]string{
+		EnvMetricsAddr:   "127.0.0.1:9100",
+		EnvTraceEnabled:  "1",
+		EnvTraceEndpoint: "http://env/traces",
+	}
+	enabled := false
+	flags := ObservabilityFlags{TraceEndpoint: "http://flag/traces", TraceEnabled: &enabled}
+
+	cfg, err := ResolveObservability(flags, func(key string) string { return env[key] }, manifest)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := ObservabilityConfig{
+		MetricsAddr:   "127.0.0.1:9100",     // env beats manifest
+		MetricsToken:  "manifest-token",     // only the manifest sets it
+		TraceEnabled:  false,                // flag beats env
+		TraceEndpoint: "http://flag/traces", // flag beats env and manifest
+	}
+	if cfg != want {
+		t.Fatalf("expected %+v, got %+v", want, cfg)
+	}
+}
+
+func TestResolveObservabilityLegacyMetricsEnv(t *testing.T) {
+	env := map[string]string{EnvDaemonMetrics: "127.0.0.1:9200"}
+	cfg, err := ResolveObservability(ObservabilityFlags{}, func(key string) string { return env[key] }, nil)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if cfg.MetricsAddr != "127.0.0.1:9200" || cfg.TraceEnabled {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	env[EnvTraceEnabled] = "maybe"
+	if _, err := ResolveObservability(ObservabilityFlags{}, func(key string) string { return env[key] }, nil); err == nil {
+		t.Fatalf("expected invalid trace flag to be rejected")
+	}
+}
+
+func TestObservabilityEnvRoundTrips(t *testing.T) {
+	cfg := ObservabilityConfig{MetricsAddr: "127.0.0.1:9300", MetricsToken: "secret", TraceEnabled: true}
+	env := map[string]string{}
+	for _, entry := range cfg.Env() {
+		key, value, _ := strings.Cut(entry, "=")
+		env[key] = value
+	}
+	resolved, err := ResolveObservability(ObservabilityFlags{}, func(key string) string { return env[key] }, nil)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if resolved != cfg {
+		t.Fatalf("expected %+v, got %+v", cfg, resolved)
+	}
+}
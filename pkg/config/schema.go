@@ -11,8 +11,17 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"time"
+
+	"lowkey/internal/clock"
 )
 
 // ErrNoDirectories is returned when a manifest or configuration is invalid
@@ -57,6 +66,42 @@ func normalizeDirectories(base string, dirs []string) ([]string, error) {
 	return result, nil
 }
 
+// expandDirectoryGlobs replaces each argument containing glob metacharacters
+// with the directories it matches, resolving relative patterns against base.
+// Other arguments are passed through untouched.
+func expandDirectoryGlobs(base string, args []string, warn func(string)) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			expanded = append(expanded, arg)
+			continue
+		}
+		pattern := arg
+		if !filepath.IsAbs(pattern) && base != "" {
+			pattern = filepath.Join(base, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid directory pattern %q: %w", arg, err)
+		}
+		found := false
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				if warn != nil {
+					warn(fmt.Sprintf("skipping %s: not a directory", match))
+				}
+				continue
+			}
+			expanded = append(expanded, match)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("config: pattern %q matched no directories", arg)
+		}
+	}
+	return expanded, nil
+}
+
 // normalizeLogPath cleans and absolutizes the log path when supplied. If the
 // path is relative, it is resolved against the provided base directory.
 func normalizeLogPath(base, logPath string) (string, error) {
@@ -71,3 +116,313 @@ func normalizeLogPath(base, logPath string) (string, error) {
 	}
 	return filepath.Clean(logPath), nil
 }
+
+// Validate checks that the manifest can be used to start the daemon: every
+// directory exists and is a directory, the ignore file is readable and its
+// patterns compile, include globs compile, the log path is writable, and
+// numeric, webhook, and observability settings are well formed. Unlike
+// LoadManifest, it reports every problem found, joined with errors.Join,
+// rather than stopping at the first. It returns nil for a valid manifest.
+func (m *Manifest) Validate() error {
+	if m == nil {
+		return errors.New("config: manifest is nil")
+	}
+	var problems []error
+	if len(m.Directories) == 0 {
+		problems = append(problems, ErrNoDirectories)
+	}
+	if len(m.Directories) > 0 && len(m.EnabledDirectories()) == 0 {
+		problems = append(problems, errors.New("config: every directory is disabled"))
+	}
+	for _, dir := range m.EnabledDirectories() {
+		info, err := os.Stat(dir)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Errorf("config: directory %q: %w", dir, err))
+		case !info.IsDir():
+			problems = append(problems, fmt.Errorf("config: directory %q is not a directory", dir))
+		}
+	}
+
+	if m.IgnoreFile != "" {
+		patterns, err := LoadIgnorePatterns(m.IgnoreFile)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		for _, pattern := range patterns {
+			if err := checkPattern(pattern); err != nil {
+				problems = append(problems, fmt.Errorf("config: ignore file %q: %w", m.IgnoreFile, err))
+			}
+		}
+	}
+	for _, pattern := range m.Include {
+		if err := checkPattern(pattern); err != nil {
+			problems = append(problems, fmt.Errorf("config: include: %w", err))
+		}
+	}
+
+	if m.LogPath != "" {
+		if err := checkWritable(m.LogPath); err != nil {
+			problems = append(problems, fmt.Errorf("config: log path %q: %w", m.LogPath, err))
+		}
+	}
+	if m.EventBuffer < 0 {
+		problems = append(problems, fmt.Errorf("config: event_buffer must not be negative, got %d", m.EventBuffer))
+	}
+	if m.ScanConcurrency < 0 {
+		problems = append(problems, fmt.Errorf("config: scan_concurrency must not be negative, got %d", m.ScanConcurrency))
+	}
+	if m.HashThreshold < 0 {
+		problems = append(problems, fmt.Errorf("config: hash_threshold must not be negative, got %d", m.HashThreshold))
+	}
+	if m.HashReadLimit < 0 {
+		problems = append(problems, fmt.Errorf("config: hash_read_limit must not be negative, got %d", m.HashReadLimit))
+	}
+	if err := CheckWebhookURL(m.WebhookURL); err != nil {
+		problems = append(problems, err)
+	}
+	if err := CheckMirror(m.Mirror, m.EnabledDirectories()); err != nil {
+		problems = append(problems, err)
+	}
+	if m.WebhookBatchSize < 0 {
+		problems = append(problems, fmt.Errorf("config: webhook_batch_size must not be negative, got %d", m.WebhookBatchSize))
+	}
+	if _, err := m.WebhookBatchWait(); err != nil {
+		problems = append(problems, err)
+	}
+	if _, _, err := m.ScanIntervalBounds(); err != nil {
+		problems = append(problems, err)
+	}
+	if _, _, err := m.SafetyScanSchedule(); err != nil {
+		problems = append(problems, err)
+	}
+	if _, _, err := m.AgeWindow(); err != nil {
+		problems = append(problems, err)
+	}
+	if _, err := m.WarmUpPeriod(); err != nil {
+		problems = append(problems, err)
+	}
+	switch m.LogLevel {
+	case "", LogLevelInfo, LogLevelDebug:
+	default:
+		problems = append(problems, fmt.Errorf("config: log_level must be %q or %q, got %q", LogLevelInfo, LogLevelDebug, m.LogLevel))
+	}
+
+	if obs := m.Observability; obs != nil {
+		if obs.MetricsAddr != "" {
+			if _, _, err := net.SplitHostPort(obs.MetricsAddr); err != nil {
+				problems = append(problems, fmt.Errorf("config: observability.metrics_addr %q: %w", obs.MetricsAddr, err))
+			}
+		}
+		if obs.TraceEndpoint != "" {
+			if u, err := url.Parse(obs.TraceEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems = append(problems, fmt.Errorf("config: observability.trace_endpoint %q must be an http(s) URL", obs.TraceEndpoint))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// EnabledDirectories returns the directories to watch: Directories without
+// the ones listed in Disabled.
+func (m *Manifest) EnabledDirectories() []string {
+	dirs := make([]string, 0, len(m.Directories))
+	for _, dir := range m.Directories {
+		if !m.IsDisabled(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// IsDisabled reports whether dir is listed in Disabled.
+func (m *Manifest) IsDisabled(dir string) bool {
+	for _, disabled := range m.Disabled {
+		if disabled == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// SetEnabled enables or disables dir, which must be one of Directories.
+func (m *Manifest) SetEnabled(dir string, enabled bool) error {
+	if !slices.Contains(m.Directories, dir) {
+		return fmt.Errorf("config: %s is not a watched directory", dir)
+	}
+	m.Disabled = slices.DeleteFunc(m.Disabled, func(d string) bool { return d == dir })
+	if !enabled {
+		m.Disabled = append(m.Disabled, dir)
+		sort.Strings(m.Disabled)
+	}
+	if len(m.Disabled) == 0 {
+		m.Disabled = nil
+	}
+	return nil
+}
+
+// ScanIntervalBounds parses ScanIntervalMin and ScanIntervalMax. An empty or
+// "0" value is returned as zero, which keeps the safety-scan interval fixed.
+func (m *Manifest) ScanIntervalBounds() (minInterval, maxInterval time.Duration, err error) {
+	if minInterval, err = parseDurationSetting("scan_interval_min", m.ScanIntervalMin); err != nil {
+		return 0, 0, err
+	}
+	if maxInterval, err = parseDurationSetting("scan_interval_max", m.ScanIntervalMax); err != nil {
+		return 0, 0, err
+	}
+	if minInterval > 0 && maxInterval > 0 && minInterval > maxInterval {
+		return 0, 0, fmt.Errorf("config: scan_interval_min %s exceeds scan_interval_max %s", minInterval, maxInterval)
+	}
+	return minInterval, maxInterval, nil
+}
+
+// SafetyScanSchedule parses SafetyScan. It reports off for "off" and returns
+// the schedule for a cron expression; both are zero for the default interval.
+func (m *Manifest) SafetyScanSchedule() (off bool, schedule *clock.Schedule, err error) {
+	return ParseSafetyScan(m.SafetyScan)
+}
+
+// ParseSafetyScan parses a safety-scan setting as accepted by
+// Manifest.SafetyScan.
+func ParseSafetyScan(raw string) (off bool, schedule *clock.Schedule, err error) {
+	switch strings.TrimSpace(raw) {
+	case "", SafetyScanInterval:
+		return false, nil, nil
+	case SafetyScanOff:
+		return true, nil, nil
+	}
+	schedule, err = clock.ParseSchedule(raw)
+	if err != nil {
+		return false, nil, fmt.Errorf("config: safety_scan must be %q, %q, or a cron expression: %w", SafetyScanInterval, SafetyScanOff, err)
+	}
+	return false, schedule, nil
+}
+
+// AgeWindow parses IgnoreOlderThan and IgnoreNewerThan. Empty values are
+// returned as zero, which leaves that side of the window open.
+func (m *Manifest) AgeWindow() (olderThan, newerThan time.Duration, err error) {
+	if olderThan, err = parseDurationSetting("ignore_older_than", m.IgnoreOlderThan); err != nil {
+		return 0, 0, err
+	}
+	if newerThan, err = parseDurationSetting("ignore_newer_than", m.IgnoreNewerThan); err != nil {
+		return 0, 0, err
+	}
+	return olderThan, newerThan, nil
+}
+
+// WarmUpPeriod parses WarmUp; an empty value is zero, which disables the
+// warm-up.
+func (m *Manifest) WarmUpPeriod() (time.Duration, error) {
+	return parseDurationSetting("warm_up", m.WarmUp)
+}
+
+// WebhookBatchWait parses WebhookBatchInterval; an empty value is zero, which
+// leaves the webhook's default in place.
+func (m *Manifest) WebhookBatchWait() (time.Duration, error) {
+	return parseDurationSetting("webhook_batch_interval", m.WebhookBatchInterval)
+}
+
+// ParseAge parses a non-negative Go duration for the setting named field; an
+// empty value is zero.
+func parseDurationSetting(field, raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s %q: %w", field, raw, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("config: %s must not be negative, got %q", field, raw)
+	}
+	return d, nil
+}
+
+// CheckWebhookURL reports whether raw is usable as Manifest.WebhookURL: empty,
+// or an absolute http(s) URL.
+func CheckWebhookURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("config: webhook_url %q must be an http(s) URL", raw)
+	}
+	return nil
+}
+
+// CheckMirror reports whether dest is usable as Manifest.Mirror for dirs:
+// empty, or an absolute path that neither is inside a watched directory,
+// where copies would be reported as changes, nor contains one.
+func CheckMirror(dest string, dirs []string) error {
+	if dest == "" {
+		return nil
+	}
+	if !filepath.IsAbs(dest) {
+		return fmt.Errorf("config: mirror %q must be an absolute path", dest)
+	}
+	dest = filepath.Clean(dest)
+	for _, dir := range dirs {
+		if pathWithin(dest, dir) || pathWithin(dir, dest) {
+			return fmt.Errorf("config: mirror %q overlaps watched directory %q", dest, dir)
+		}
+	}
+	return nil
+}
+
+// pathWithin reports whether path is dir or lies beneath it.
+func pathWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkPattern reports whether a glob would be rejected by the watcher's
+// matcher, which matches each slash-separated segment with path.Match.
+func checkPattern(pattern string) error {
+	for _, segment := range strings.Split(filepath.ToSlash(strings.TrimSpace(pattern)), "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// checkWritable reports whether a file could be created at logPath: an
+// existing file must be writable, otherwise its nearest existing parent must
+// be a writable directory (missing directories are created on startup).
+func checkWritable(logPath string) error {
+	if info, err := os.Stat(logPath); err == nil {
+		if info.IsDir() {
+			return errors.New("is a directory")
+		}
+		file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+	dir := filepath.Dir(logPath)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+	probe, err := os.CreateTemp(dir, ".lowkey-validate-*")
+	if err != nil {
+		return err
+	}
+	name := probe.Name()
+	probe.Close()
+	return os.Remove(name)
+}
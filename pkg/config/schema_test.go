@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestValidateReportsEveryProblem(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ignore := filepath.Join(dir, "ignore")
+	if err := os.WriteFile(ignore, []byte("*.log\n[a-\n"), 0o644); err != nil {
+		t.Fatalf("write ignore: %v", err)
+	}
+
+	manifest := &Manifest{
+		Directories:          []string{dir, file, filepath.Join(dir, "missing")},
+		IgnoreFile:           ignore,
+		Include:              []string{"src/**/*.go", "[x"},
+		LogPath:              filepath.Join(file, "lowkey.log"),
+		EventBuffer:          -1,
+		ScanConcurrency:      -2,
+		LogLevel:             "trace",
+		WebhookURL:           "hooks.example.com/lowkey",
+		ScanIntervalMin:      "fast",
+		WebhookBatchInterval: "soon",
+		SafetyScan:           "nightly",
+		WarmUp:               "briefly",
+	}
+	err := manifest.Validate()
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T", err)
+	}
+	if got := len(joined.Unwrap()); got != 13 {
+		t.Fatalf("expected 13 problems, got %d: %v", got, err)
+	}
+
+	valid := &Manifest{Directories: []string{dir}, LogPath: filepath.Join(dir, "logs", "lowkey.log"), LogLevel: LogLevelDebug}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid manifest, got %v", err)
+	}
+	if err := (&Manifest{}).Validate(); !errors.Is(err, ErrNoDirectories) {
+		t.Fatalf("expected ErrNoDirectories, got %v", err)
+	}
+}
+
+func TestSetEnabledTogglesWatchedDirectories(t *testing.T) {
+	manifest := &Manifest{Directories: []string{"/a", "/b", "/c"}}
+	if err := manifest.SetEnabled("/c", false); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if err := manifest.SetEnabled("/a", false); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if got := manifest.EnabledDirectories(); !reflect.DeepEqual(got, []string{"/b"}) {
+		t.Fatalf("expected only /b enabled, got %v", got)
+	}
+	if !reflect.DeepEqual(manifest.Disabled, []string{"/a", "/c"}) {
+		t.Fatalf("expected /a and /c disabled, got %v", manifest.Disabled)
+	}
+	if err := manifest.SetEnabled("/a", true); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if err := manifest.SetEnabled("/c", true); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if manifest.Disabled != nil || len(manifest.EnabledDirectories()) != 3 {
+		t.Fatalf("expected every directory enabled, got disabled %v", manifest.Disabled)
+	}
+	if err := manifest.SetEnabled("/d", false); err == nil {
+		t.Fatalf("expected an error for a directory outside the manifest")
+	}
+	if err := (&Manifest{Directories: []string{t.TempDir()}, Disabled: []string{"/x"}}).Validate(); err != nil {
+		t.Fatalf("expected a disabled entry outside Directories to be harmless, got %v", err)
+	}
+	all := &Manifest{Directories: []string{"/missing"}, Disabled: []string{"/missing"}}
+	if err := all.Validate(); err == nil || strings.Contains(err.Error(), "/missing\":") {
+		t.Fatalf("expected only the all-disabled problem, got %v", err)
+	}
+}
+
+func TestCheckMirrorRejectsOverlappingDirectories(t *testing.T) {
+	dirs := []string{"/src/app"}
+	for dest, ok := range map[string]bool{
+		"":                 true,
+		"/backup/app":      true,
+		"/src/application": true,
+		"backup":           false,
+		"/src/app":         false,
+		"/src/app/out":     false,
+		"/src":             false,
+	} {
+		if err := CheckMirror(dest, dirs); (err == nil) != ok {
+			t.Fatalf("CheckMirror(%q): unexpected error %v", dest, err)
+		}
+	}
+}
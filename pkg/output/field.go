@@ -0,0 +1,65 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"lowkey/internal/daemon"
+)
+
+// statusFields are the fields WriteStatusField can print, in the order they
+// are listed in its error.
+var statusFields = []struct {
+	name  string
+	value func(daemon.ManagerStatus) string
+}{
+	{"state", statusState},
+	{"running", func(s daemon.ManagerStatus) string { return strconv.FormatBool(s.Running) }},
+	{"paused", func(s daemon.ManagerStatus) string { return strconv.FormatBool(s.Running && s.Paused) }},
+	{"pid", func(s daemon.ManagerStatus) string {
+		if !s.Running || s.PID <= 0 {
+			return ""
+		}
+		return strconv.Itoa(s.PID)
+	}},
+	{"manifest", func(s daemon.ManagerStatus) string { return s.ManifestPath }},
+	{"directories", func(s daemon.ManagerStatus) string { return strings.Join(s.Directories, "\n") }},
+	{"disabled", func(s daemon.ManagerStatus) string { return strings.Join(s.Disabled, "\n") }},
+	{"changes", func(s daemon.ManagerStatus) string { return strconv.Itoa(s.Summary.TotalChanges) }},
+	{"last_change", func(s daemon.ManagerStatus) string {
+		if s.Summary.LastEvent == nil {
+			return ""
+		}
+		return s.Summary.LastEvent.Timestamp.Format(time.RFC3339)
+	}},
+	{"restarts", func(s daemon.ManagerStatus) string { return strconv.Itoa(s.Heartbeat.Restarts) }},
+	{"version", func(s daemon.ManagerStatus) string { return s.Version }},
+	{"commit", func(s daemon.ManagerStatus) string { return s.Commit }},
+}
+
+// StatusFieldNames returns the field names accepted by WriteStatusField.
+func StatusFieldNames() []string {
+	names := make([]string, len(statusFields))
+	for i, field := range statusFields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// WriteStatusField writes the bare value of one status field followed by a
+// newline, for shell scripts: booleans as true or false, times in RFC3339,
+// and lists one entry per line. A value that is unavailable, such as the pid
+// of a stopped daemon, is an empty line. An unknown name is an error that
+// lists the valid ones.
+func WriteStatusField(w io.Writer, status daemon.ManagerStatus, name string) error {
+	for _, field := range statusFields {
+		if field.name == name {
+			_, err := fmt.Fprintln(w, field.value(status))
+			return err
+		}
+	}
+	return fmt.Errorf("output: unknown status field %q (valid fields: %s)", name, strings.Join(StatusFieldNames(), ", "))
+}
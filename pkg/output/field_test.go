@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"lowkey/internal/daemon"
+)
+
+func TestWriteStatusFieldPrintsBareValues(t *testing.T) {
+	running := daemon.ManagerStatus{Running: true, Paused: true, PID: 42, Directories: []string{"/a", "/b"}}
+	stopped := daemon.ManagerStatus{PID: 42}
+	cases := []struct {
+		status daemon.ManagerStatus
+		field  string
+		want   string
+	}{
+		{running, "state", "paused\n"},
+		{running, "pid", "42\n"},
+		{running, "directories", "/a\n/b\n"},
+		{stopped, "running", "false\n"},
+		{stopped, "pid", "\n"},
+		{stopped, "last_change", "\n"},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		if err := WriteStatusField(&buf, tc.status, tc.field); err != nil {
+			t.Fatalf("%s: %v", tc.field, err)
+		}
+		if buf.String() != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.field, tc.want, buf.String())
+		}
+	}
+
+	err := WriteStatusField(&bytes.Buffer{}, running, "uptime")
+	if err == nil || !strings.Contains(err.Error(), "state, running, paused, pid") {
+		t.Fatalf("expected an error listing the valid fields, got %v", err)
+	}
+}
@@ -15,8 +15,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strconv"
+	"time"
 
 	"lowkey/internal/daemon"
+	"lowkey/internal/logs"
 )
 
 // Renderer defines the interface for emitting formatted output for CLI commands.
@@ -24,17 +28,25 @@ import (
 // provides methods for rendering specific data structures, such as daemon status.
 type Renderer interface {
 	Status(status daemon.ManagerStatus) error
+	// Logs renders parsed change log entries, oldest first.
+	Logs(entries []logs.LogEntry) error
 }
 
 // NewRenderer returns a Renderer implementation based on the specified format
-// keyword. It supports "plain" (or "text") for human-readable output and "json"
-// for machine-readable output. An error is returned if the format is unsupported.
+// keyword. It supports "plain" (or "text") for human-readable output, "json"
+// for machine-readable output, "yaml" for the same data as YAML, and
+// "oneline" for a single line suited to prompts and scripts. An error is
+// returned if the format is unsupported.
 func NewRenderer(format string) (Renderer, error) {
 	switch format {
 	case "", "plain", "text":
 		return &tableRenderer{writer: os.Stdout}, nil
 	case "json":
 		return &jsonRenderer{encoder: json.NewEncoder(os.Stdout)}, nil
+	case "oneline":
+		return &onelineRenderer{writer: os.Stdout}, nil
+	case "yaml":
+		return &yamlRenderer{writer: os.Stdout}, nil
 	default:
 		return nil, fmt.Errorf("output: unsupported format %q", format)
 	}
@@ -46,11 +58,15 @@ func NewRenderer(format string) (Renderer, error) {
 func WithWriter(r Renderer, w io.Writer) Renderer {
 	switch r.(type) {
 	case *tableRenderer:
-		return &tableRenderer{writer: w}
+		return &tableRenderer{writer: w, tree: r.(*tableRenderer).tree}
 	case *jsonRenderer:
 		enc := json.NewEncoder(w)
 		enc.SetIndent("", "  ")
 		return &jsonRenderer{encoder: enc}
+	case *onelineRenderer:
+		return &onelineRenderer{writer: w}
+	case *yamlRenderer:
+		return &yamlRenderer{writer: w}
 	default:
 		panic("output: unknown renderer implementation")
 	}
@@ -60,6 +76,9 @@ func WithWriter(r Renderer, w io.Writer) Renderer {
 // text. It writes to the configured io.Writer, which is typically os.Stdout.
 type tableRenderer struct {
 	writer io.Writer
+	// tree prints the watched directories as a tree with their change
+	// counts; see WithDirectoryTree.
+	tree bool
 }
 
 // Status formats and prints the daemon's status to the configured writer in a
@@ -70,10 +89,39 @@ func (t *tableRenderer) Status(status daemon.ManagerStatus) error {
 	}
 
 	fmt.Fprintf(t.writer, "daemon: running=%t\n", status.Running)
+	if status.Version != "" {
+		fmt.Fprintf(t.writer, "version: %s (commit %s)\n", status.Version, status.Commit)
+	}
+	if status.Paused {
+		fmt.Fprintln(t.writer, "paused: true (changes are not being recorded)")
+	}
 	fmt.Fprintf(t.writer, "manifest: %s\n", status.ManifestPath)
 	fmt.Fprintf(t.writer, "directories (%d):\n", len(status.Directories))
-	for _, dir := range status.Directories {
-		fmt.Fprintf(t.writer, "  - %s\n", dir)
+	if t.tree {
+		writeDirTree(t.writer, buildDirTree(status.Directories, status.Disabled, status.PerDirectory))
+	} else {
+		for _, dir := range status.Directories {
+			if slices.Contains(status.Disabled, dir) {
+				fmt.Fprintf(t.writer, "  - %s (disabled)\n", dir)
+				continue
+			}
+			fmt.Fprintf(t.writer, "  - %s\n", dir)
+		}
+	}
+	if len(status.Inaccessible) > 0 {
+		fmt.Fprintf(t.writer, "inaccessible (%d, permission denied):\n", len(status.Inaccessible))
+		for _, path := range status.Inaccessible {
+			fmt.Fprintf(t.writer, "  - %s\n", path)
+		}
+	}
+	if status.TrackedFiles > 0 {
+		fmt.Fprintf(t.writer, "tracked: files=%d size=%s\n", status.TrackedFiles, FormatBytes(status.TrackedBytes))
+	}
+	if status.TrackingLimitDirectory != "" {
+		fmt.Fprintf(t.writer, "error: tracked file limit reached while scanning %s; new files are not tracked (narrow the watch or raise max_tracked_files)\n", status.TrackingLimitDirectory)
+	}
+	for _, churn := range status.Churning {
+		fmt.Fprintf(t.writer, "warning: excessive churn in %s (%d changes/s, most to %s); consider an ignore pattern\n", churn.Directory, churn.Rate, churn.TopPath)
 	}
 	fmt.Fprintf(t.writer, "changes: total=%d window=%s\n", status.Summary.TotalChanges, status.Summary.Window)
 	if status.Summary.LastEvent != nil {
@@ -92,10 +140,102 @@ func (t *tableRenderer) Status(status daemon.ManagerStatus) error {
 		if !status.Heartbeat.BackoffUntil.IsZero() {
 			fmt.Fprintf(t.writer, "heartbeat backoff until: %s\n", status.Heartbeat.BackoffUntil.Format("2006-01-02 15:04:05"))
 		}
+		if len(status.Heartbeat.RecentErrors) > 0 {
+			fmt.Fprintf(t.writer, "recent errors (%d):\n", len(status.Heartbeat.RecentErrors))
+			for _, recent := range status.Heartbeat.RecentErrors {
+				fmt.Fprintf(t.writer, "  - %s %s\n", recent.At.Format("2006-01-02 15:04:05"), recent.Error)
+			}
+		}
 	}
 	return nil
 }
 
+// Logs prints each entry's original log line.
+func (t *tableRenderer) Logs(entries []logs.LogEntry) error {
+	if t.writer == nil {
+		return errors.New("output: table renderer missing writer")
+	}
+	return writeRawLines(t.writer, entries)
+}
+
+// writeRawLines writes the original line of each entry.
+func writeRawLines(w io.Writer, entries []logs.LogEntry) error {
+	for _, entry := range entries {
+		if _, err := fmt.Fprintln(w, entry.RawLine); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatBytes renders a byte count using binary units (KiB, MiB, ...) so sizes
+// stay readable in plain-text output.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for value := n / unit; value >= unit; value /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// onelineRenderer renders daemon status as a single line of space-separated
+// fields for shell prompts and scripts. The format is kept stable so it can be
+// grepped and split reliably.
+type onelineRenderer struct {
+	writer io.Writer
+}
+
+// Status prints the daemon's status as one line of the form
+//
+//	<state> pid=<pid> dirs=<n> changes=<n> last=<RFC3339 time>
+//
+// where state is running, paused, or stopped. The keys always appear in this
+// order, and a value that is unavailable (the pid of a stopped daemon, the
+// time when nothing has changed) is printed as "-".
+func (o *onelineRenderer) Status(status daemon.ManagerStatus) error {
+	if o.writer == nil {
+		return errors.New("output: oneline renderer missing writer")
+	}
+
+	state := statusState(status)
+	pid := "-"
+	if status.Running && status.PID > 0 {
+		pid = strconv.Itoa(status.PID)
+	}
+	last := "-"
+	if status.Summary.LastEvent != nil {
+		last = status.Summary.LastEvent.Timestamp.Format(time.RFC3339)
+	}
+	_, err := fmt.Fprintf(o.writer, "%s pid=%s dirs=%d changes=%d last=%s\n",
+		state, pid, len(status.Directories), status.Summary.TotalChanges, last)
+	return err
+}
+
+// statusState names the daemon state: running, paused, or stopped.
+func statusState(status daemon.ManagerStatus) string {
+	switch {
+	case status.Running && status.Paused:
+		return "paused"
+	case status.Running:
+		return "running"
+	default:
+		return "stopped"
+	}
+}
+
+// Logs prints each entry's original log line, which is already one line.
+func (o *onelineRenderer) Logs(entries []logs.LogEntry) error {
+	if o.writer == nil {
+		return errors.New("output: oneline renderer missing writer")
+	}
+	return writeRawLines(o.writer, entries)
+}
+
 // jsonRenderer emits command outputs as JSON payloads. This is suitable for
 // scripting or integration with other tools that can parse JSON.
 type jsonRenderer struct {
@@ -111,3 +251,16 @@ func (j *jsonRenderer) Status(status daemon.ManagerStatus) error {
 	j.encoder.SetIndent("", "  ")
 	return j.encoder.Encode(status)
 }
+
+// Logs encodes the entries as one JSON array, empty rather than null when
+// there are none.
+func (j *jsonRenderer) Logs(entries []logs.LogEntry) error {
+	if j.encoder == nil {
+		return errors.New("output: json encoder missing")
+	}
+	if entries == nil {
+		entries = []logs.LogEntry{}
+	}
+	j.encoder.SetIndent("", "  ")
+	return j.encoder.Encode(entries)
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"lowkey/internal/daemon"
+	"lowkey/internal/reporting"
+)
+
+func TestOnelineStatus(t *testing.T) {
+	renderer, err := NewRenderer("oneline")
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	last := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		status daemon.ManagerStatus
+		want   string
+	}{
+		{
+			name: "running",
+			status: daemon.ManagerStatus{
+				Running:     true,
+				PID:         1234,
+				Directories: []string{"/a", "/b", "/c"},
+				Summary:     reporting.Summary{TotalChanges: 57, LastEvent: &reporting.Change{Timestamp: last}},
+			},
+			want: "running pid=1234 dirs=3 changes=57 last=2024-01-02T03:04:05Z\n",
+		},
+		{
+			name:   "paused",
+			status: daemon.ManagerStatus{Running: true, Paused: true, PID: 7, Directories: []string{"/a"}},
+			want:   "paused pid=7 dirs=1 changes=0 last=-\n",
+		},
+		{
+			name:   "stopped",
+			status: daemon.ManagerStatus{PID: 99, Directories: []string{"/a"}, Summary: reporting.Summary{TotalChanges: 2}},
+			want:   "stopped pid=- dirs=1 changes=2 last=-\n",
+		},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		if err := WithWriter(renderer, &buf).Status(tc.status); err != nil {
+			t.Fatalf("%s: Status: %v", tc.name, err)
+		}
+		if buf.String() != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, buf.String())
+		}
+	}
+}
+
+func TestTableStatusDirectoryTree(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := WithWriter(WithDirectoryTree(&tableRenderer{}), &buf)
+	status := daemon.ManagerStatus{
+		Directories: []string{"/home/me/src/api", "/home/me/src/web", "/home/me/src/web/assets", "/tmp/scratch"},
+		Disabled:    []string{"/tmp/scratch"},
+		PerDirectory: map[string]int{
+			"/home/me/src/api":            4,
+			"/home/me/src/api/handlers":   6,
+			"/home/me/src/web/assets/img": 2,
+			"/home/me/src/webhooks":       9,
+			"/elsewhere":                  1,
+		},
+	}
+	if err := renderer.Status(status); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	want := "directories (4):\n" +
+		"  /home/me/src             12\n" +
+		"    api                    10\n" +
+		"    web                     2\n" +
+		"      assets                2\n" +
+		"  /tmp/scratch (disabled)   0\n"
+	got := buf.String()
+	start := strings.Index(got, "directories")
+	end := strings.Index(got, "changes:")
+	if start < 0 || end < 0 || got[start:end] != want {
+		t.Fatalf("unexpected tree:\n%s", got)
+	}
+}
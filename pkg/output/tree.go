@@ -0,0 +1,151 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WithDirectoryTree returns r set to print the watched directories of a
+// status as a tree grouped by common path prefixes, each with the number of
+// changes at or below it. Renderers other than the plain text one are
+// returned unchanged, so JSON output keeps its flat PerDirectory map.
+func WithDirectoryTree(r Renderer) Renderer {
+	if t, ok := r.(*tableRenderer); ok {
+		return &tableRenderer{writer: t.writer, tree: true}
+	}
+	return r
+}
+
+// dirTreeNode is a directory in the tree. Chains of unwatched directories
+// with a single child are collapsed into one node whose name spans them.
+type dirTreeNode struct {
+	name     string
+	path     string
+	watched  bool
+	disabled bool
+	count    int
+	children []*dirTreeNode
+}
+
+// buildDirTree arranges dirs under their common prefixes and counts, for
+// each node, the changes in perDirectory at or below it.
+func buildDirTree(dirs, disabled []string, perDirectory map[string]int) *dirTreeNode {
+	root := &dirTreeNode{}
+	for _, dir := range dirs {
+		node := root
+		for _, name := range splitPath(dir) {
+			node = node.child(name)
+		}
+		node.watched = true
+		node.disabled = slices.Contains(disabled, dir)
+	}
+	// Every absolute path shares the filesystem root, so it would group
+	// nothing; its children are shown as top-level absolute paths instead.
+	if len(root.children) == 1 && !root.children[0].watched && root.children[0].name == string(filepath.Separator) {
+		top := root.children[0]
+		for _, c := range top.children {
+			c.name = filepath.Join(top.name, c.name)
+		}
+		root.children = top.children
+	}
+	root.compact()
+	root.countChanges(perDirectory)
+	return root
+}
+
+// splitPath splits a cleaned path into its components; an absolute path
+// starts with the separator as its own component.
+func splitPath(path string) []string {
+	path = filepath.Clean(path)
+	sep := string(filepath.Separator)
+	var parts []string
+	if strings.HasPrefix(path, sep) {
+		parts = append(parts, sep)
+		path = strings.TrimPrefix(path, sep)
+	}
+	if path != "" {
+		parts = append(parts, strings.Split(path, sep)...)
+	}
+	return parts
+}
+
+func (n *dirTreeNode) child(name string) *dirTreeNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &dirTreeNode{name: name, path: filepath.Join(n.path, name)}
+	n.children = append(n.children, c)
+	return c
+}
+
+// compact collapses unwatched single-child chains below n and sorts the
+// children by name.
+func (n *dirTreeNode) compact() {
+	for i, c := range n.children {
+		for !c.watched && len(c.children) == 1 {
+			only := c.children[0]
+			only.name = filepath.Join(c.name, only.name)
+			c = only
+		}
+		n.children[i] = c
+		c.compact()
+	}
+	slices.SortFunc(n.children, func(a, b *dirTreeNode) int { return strings.Compare(a.name, b.name) })
+}
+
+// countChanges sets the count of each node below n: the changes at or below
+// a watched directory, or the sum of its children for a grouping prefix.
+func (n *dirTreeNode) countChanges(perDirectory map[string]int) {
+	for _, c := range n.children {
+		c.countChanges(perDirectory)
+		if !c.watched {
+			for _, grandchild := range c.children {
+				c.count += grandchild.count
+			}
+			continue
+		}
+		prefix := strings.TrimSuffix(c.path, string(filepath.Separator)) + string(filepath.Separator)
+		for dir, count := range perDirectory {
+			if dir == c.path || strings.HasPrefix(dir, prefix) {
+				c.count += count
+			}
+		}
+	}
+}
+
+// writeDirTree writes the nodes below root indented by depth, with the
+// change counts right-aligned in one column.
+func writeDirTree(w io.Writer, root *dirTreeNode) {
+	type row struct {
+		label string
+		count int
+	}
+	var rows []row
+	var walk func(n *dirTreeNode, depth int)
+	walk = func(n *dirTreeNode, depth int) {
+		for _, c := range n.children {
+			label := strings.Repeat("  ", depth+1) + c.name
+			if c.disabled {
+				label += " (disabled)"
+			}
+			rows = append(rows, row{label: label, count: c.count})
+			walk(c, depth+1)
+		}
+	}
+	walk(root, 0)
+
+	labelWidth, countWidth := 0, 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, len(r.label))
+		countWidth = max(countWidth, len(strconv.Itoa(r.count)))
+	}
+	for _, r := range rows {
+		fmt.Fprintf(w, "%-*s  %*d\n", labelWidth, r.label, countWidth, r.count)
+	}
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lowkey/internal/daemon"
+	"lowkey/internal/logs"
+)
+
+// yamlRenderer emits command outputs as YAML documents, for readers who
+// prefer it to JSON. Fields are named and ordered as in the JSON output.
+type yamlRenderer struct {
+	writer io.Writer
+}
+
+// Status writes the daemon's status as a YAML document.
+func (y *yamlRenderer) Status(status daemon.ManagerStatus) error {
+	return WriteYAML(y.writer, status)
+}
+
+// Logs writes the entries as a YAML sequence.
+func (y *yamlRenderer) Logs(entries []logs.LogEntry) error {
+	if entries == nil {
+		entries = []logs.LogEntry{}
+	}
+	return WriteYAML(y.writer, entries)
+}
+
+// WriteYAML writes v to w as a block-style YAML document. v is first
+// encoded as JSON, so its json tags decide field names, order, and
+// omission, and the document holds exactly the data of the JSON output.
+func WriteYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := readYAMLNode(decoder)
+	if err != nil {
+		return fmt.Errorf("output: encode yaml: %w", err)
+	}
+	var buf bytes.Buffer
+	switch {
+	case node.scalar != "":
+		buf.WriteString(node.scalar + "\n")
+	case node.isEmpty():
+		buf.WriteString(node.flow() + "\n")
+	default:
+		node.write(&buf, 0)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// yamlNode is a decoded JSON value: a scalar already rendered as YAML, or a
+// mapping or sequence. Mapping keys keep their JSON order.
+type yamlNode struct {
+	scalar  string
+	mapping bool
+	keys    []string
+	values  []yamlNode
+}
+
+func (n yamlNode) isEmpty() bool { return n.scalar == "" && len(n.values) == 0 }
+
+// flow renders an empty mapping or sequence.
+func (n yamlNode) flow() string {
+	if n.mapping {
+		return "{}"
+	}
+	return "[]"
+}
+
+func readYAMLNode(decoder *json.Decoder) (yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return yamlNode{}, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		node := yamlNode{mapping: token == '{'}
+		for decoder.More() {
+			if node.mapping {
+				key, err := decoder.Token()
+				if err != nil {
+					return yamlNode{}, err
+				}
+				node.keys = append(node.keys, yamlString(key.(string)))
+			}
+			value, err := readYAMLNode(decoder)
+			if err != nil {
+				return yamlNode{}, err
+			}
+			node.values = append(node.values, value)
+		}
+		_, err := decoder.Token() // closing delimiter
+		return node, err
+	case string:
+		return yamlNode{scalar: yamlString(token)}, nil
+	case json.Number:
+		return yamlNode{scalar: token.String()}, nil
+	case bool:
+		return yamlNode{scalar: strconv.FormatBool(token)}, nil
+	default:
+		return yamlNode{scalar: "null"}, nil
+	}
+}
+
+// write writes a non-empty mapping or sequence indented by indent spaces.
+func (n yamlNode) write(buf *bytes.Buffer, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, value := range n.values {
+		// A sequence entry starts with "- "; a mapping inside it continues on
+		// the same line and its remaining keys align with the first.
+		prefix := pad + "- "
+		if n.mapping {
+			prefix = pad + n.keys[i] + ":"
+		}
+		switch {
+		case value.scalar != "" && n.mapping:
+			buf.WriteString(prefix + " " + value.scalar + "\n")
+		case value.scalar != "":
+			buf.WriteString(prefix + value.scalar + "\n")
+		case value.isEmpty() && n.mapping:
+			buf.WriteString(prefix + " " + value.flow() + "\n")
+		case value.isEmpty():
+			buf.WriteString(prefix + value.flow() + "\n")
+		case n.mapping:
+			buf.WriteString(prefix + "\n")
+			value.write(buf, indent+2)
+		default:
+			var nested bytes.Buffer
+			value.write(&nested, indent+2)
+			buf.WriteString(prefix + strings.TrimPrefix(nested.String(), pad+"  "))
+		}
+	}
+}
+
+// plainYAML matches strings that YAML reads back as the same string without
+// quotes.
+var plainYAML = regexp.MustCompile(`^[A-Za-z0-9_./~+(][A-Za-z0-9_./~+()@=, -]*$`)
+
+// yamlString renders s as a YAML scalar, double-quoted unless it is safe
+// plain: not empty, free of indicator characters and surrounding spaces,
+// and not readable as a number, boolean, or null.
+func yamlString(s string) string {
+	if plainYAML.MatchString(s) && !strings.HasSuffix(s, " ") && !yamlReserved(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func yamlReserved(s string) bool {
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n", ".inf", "-.inf", ".nan":
+		return true
+	}
+	_, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+	return err == nil || strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o")
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteYAMLFollowsJSONFieldsAndQuotesAmbiguousStrings(t *testing.T) {
+	value := struct {
+		Name    string              `json:"name"`
+		Count   int                 `json:"count"`
+		Empty   []string            `json:"empty"`
+		Skipped string              `json:"skipped,omitempty"`
+		Words   []string            `json:"words"`
+		Items   []map[string]string `json:"items"`
+		Nested  map[string]any      `json:"nested"`
+	}{
+		Name:   "/srv/app",
+		Count:  3,
+		Empty:  []string{},
+		Words:  []string{"yes", "1.5", "", "*.go", "a: b", "plain text"},
+		Items:  []map[string]string{{"a": "1x", "b": "two"}},
+		Nested: map[string]any{"none": nil, "flag": true, "list": [][]int{{1, 2}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, value); err != nil {
+		t.Fatalf("WriteYAML: %v", err)
+	}
+	want := `name: /srv/app
+count: 3
+empty: []
+words:
+  - "yes"
+  - "1.5"
+  - ""
+  - "*.go"
+  - "a: b"
+  - plain text
+items:
+  - a: 1x
+    b: two
+nested:
+  flag: true
+  list:
+    - - 1
+      - 2
+  none: null
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected yaml:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,9 +30,20 @@ type Collector struct {
 	latencySum   time.Duration
 	latencyCount uint64
 
+	gaugesMu sync.Mutex
+	gauges   []gauge
+
 	server   *http.Server
 	listener net.Listener
 	startMu  sync.Mutex
+	token    string
+}
+
+// gauge is a metric whose value is read from its source on every scrape.
+type gauge struct {
+	name   string
+	help   string
+	source func() float64
 }
 
 // NewCollector constructs an idle metrics collector. The collector does not
@@ -72,6 +84,14 @@ func (c *Collector) Start(addr string) error {
 	return nil
 }
 
+// SetToken requires scrapers to present the token as a bearer credential.
+// An empty token leaves the endpoint open. It must be called before Start.
+func (c *Collector) SetToken(token string) {
+	c.startMu.Lock()
+	defer c.startMu.Unlock()
+	c.token = token
+}
+
 // Stop gracefully shuts down the HTTP server that serves the Prometheus
 // metrics. It waits for active connections to finish before returning.
 func (c *Collector) Stop(ctx context.Context) error {
@@ -107,7 +127,21 @@ func (c *Collector) ObserveLatency(d time.Duration) {
 	c.latencyCount++
 }
 
+// RegisterGauge adds a gauge named name, described by help, whose value is
+// read from source each time the metrics are scraped. Gauges are written after
+// the built-in metrics, in registration order. source must be safe for
+// concurrent use.
+func (c *Collector) RegisterGauge(name, help string, source func() float64) {
+	c.gaugesMu.Lock()
+	defer c.gaugesMu.Unlock()
+	c.gauges = append(c.gauges, gauge{name: name, help: help, source: source})
+}
+
 func (c *Collector) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if c.token != "" && r.Header.Get("Authorization") != "Bearer "+c.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
 
 	events := atomic.LoadUint64(&c.events)
@@ -136,4 +170,13 @@ func (c *Collector) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "# HELP lowkey_event_latency_samples Number of samples contributing to latency metric.\n")
 	fmt.Fprintf(w, "# TYPE lowkey_event_latency_samples counter\n")
 	fmt.Fprintf(w, "lowkey_event_latency_samples %d\n", count)
+
+	c.gaugesMu.Lock()
+	gauges := append([]gauge(nil), c.gauges...)
+	c.gaugesMu.Unlock()
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n", g.name, g.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", g.name)
+		fmt.Fprintf(w, "%s %s\n", g.name, strconv.FormatFloat(g.source(), 'f', -1, 64))
+	}
 }
@@ -8,8 +8,11 @@
 package telemetry
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"log"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -34,9 +37,12 @@ type SpanExporter interface {
 
 // TracerOptions configures a Tracer instance. It allows enabling or disabling
 // tracing and specifying a custom SpanExporter for processing completed spans.
+// When no Exporter is given but Endpoint is set, spans are POSTed to Endpoint
+// as JSON.
 type TracerOptions struct {
 	Enabled  bool
 	Exporter SpanExporter
+	Endpoint string
 }
 
 // Tracer provides lightweight, OpenTelemetry-inspired tracing capabilities.
@@ -50,7 +56,8 @@ type Tracer struct {
 
 // NewTracer constructs a new tracer based on the provided options. If tracing
 // is disabled in the options, a no-op tracer is returned. If no exporter is
-// specified, a default logging exporter is used.
+// specified, spans are sent to the configured endpoint, or logged when there is
+// none.
 func NewTracer(opts TracerOptions) *Tracer {
 	tracer := &Tracer{enabled: opts.Enabled}
 	if !opts.Enabled {
@@ -58,6 +65,8 @@ func NewTracer(opts TracerOptions) *Tracer {
 	}
 	if opts.Exporter != nil {
 		tracer.exporter = opts.Exporter
+	} else if opts.Endpoint != "" {
+		tracer.exporter = &httpExporter{endpoint: opts.Endpoint, client: &http.Client{Timeout: 5 * time.Second}}
 	} else {
 		tracer.exporter = &loggingExporter{}
 	}
@@ -147,6 +156,29 @@ func SpanFromContext(ctx context.Context) (*Span, bool) {
 
 type spanKey struct{}
 
+// httpExporter posts each span as a JSON document to an HTTP endpoint. Exports
+// run in the background so tracing never blocks the watcher; failures are
+// logged and dropped.
+type httpExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (e *httpExporter) ExportSpan(snapshot SpanSnapshot) {
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return
+	}
+	go func() {
+		resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("trace export failed: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+}
+
 type loggingExporter struct{}
 
 func (loggingExporter) ExportSpan(snapshot SpanSnapshot) {
@@ -29,6 +29,14 @@ func (c *Command) AddCommand(cmds ...*Command) {
 	}
 }
 
+func (c *Command) Commands() []*Command {
+	return append([]*Command(nil), c.subCommands...)
+}
+
+func (c *Command) Name() string {
+	return strings.Split(c.Use, " ")[0]
+}
+
 func (c *Command) SetArgs(args []string) {
 	c.args = append([]string(nil), args...)
 }
@@ -54,10 +62,12 @@ func (c *Command) execute(args []string) error {
 		}
 	}
 
-	if err := c.invoke(args); err != nil {
-		return err
+	// A command that runs itself takes the remaining words as arguments;
+	// only a pure group of sub-commands rejects an unknown one.
+	if len(c.subCommands) > 0 && c.RunE == nil && c.Run == nil {
+		return fmt.Errorf("unknown command: %s", next)
 	}
-	return fmt.Errorf("unknown command: %s", next)
+	return c.invoke(args)
 }
 
 func (c *Command) invoke(args []string) error {
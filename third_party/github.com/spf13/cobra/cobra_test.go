@@ -0,0 +1,32 @@
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExecutePassesArgumentsToLeafCommands(t *testing.T) {
+	var got []string
+	root := &Command{Use: "root"}
+	root.AddCommand(&Command{
+		Use: "leaf [dir ...]",
+		RunE: func(cmd *Command, args []string) error {
+			got = args
+			return nil
+		},
+	})
+
+	root.SetArgs([]string{"leaf", "--dry-run", "a", "b"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if want := []string{"--dry-run", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+
+	root.SetArgs([]string{"missing"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "unknown command: missing") {
+		t.Fatalf("expected an unknown command error, got %v", err)
+	}
+}